@@ -16,6 +16,7 @@ package pem
 
 import (
 	"crypto"
+	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -24,6 +25,8 @@ import (
 
 	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keyspb"
+
+	terrors "github.com/google/trillian/errors"
 )
 
 // FromProto takes a PEMKeyFile protobuf message and loads the private key it specifies.
@@ -96,3 +99,44 @@ func UnmarshalPublicKey(keyPEM string) (crypto.PublicKey, error) {
 
 	return der.UnmarshalPublicKey(block.Bytes)
 }
+
+// Rekey decrypts a PEM-encoded private key using oldPassword and re-encrypts
+// it using newPassword. The key material itself is preserved.
+// The re-encrypted key is always protected using AES-256.
+// Returns an InvalidArgument error if oldPassword fails to decrypt the key.
+func Rekey(keyPEM, oldPassword, newPassword string) (string, error) {
+	if newPassword == "" {
+		return "", terrors.New(terrors.InvalidArgument, "pemfile: empty new password")
+	}
+
+	block, rest := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return "", terrors.New(terrors.InvalidArgument, "pemfile: invalid private key PEM")
+	}
+	if len(rest) > 0 {
+		return "", terrors.New(terrors.InvalidArgument, "pemfile: extra data found after first PEM block")
+	}
+
+	keyDER := block.Bytes
+	if x509.IsEncryptedPEMBlock(block) {
+		pwdDer, err := x509.DecryptPEMBlock(block, []byte(oldPassword))
+		if err != nil {
+			return "", terrors.Errorf(terrors.InvalidArgument, "pemfile: failed to decrypt: %v", err)
+		}
+		keyDER = pwdDer
+	} else if oldPassword != "" {
+		return "", terrors.New(terrors.InvalidArgument, "pemfile: password given but private key PEM is not encrypted")
+	}
+
+	// Decryption with the wrong password may occasionally succeed (padding
+	// happens to be valid), so make sure the result is a usable key.
+	if _, err := der.UnmarshalPrivateKey(keyDER); err != nil {
+		return "", terrors.Errorf(terrors.InvalidArgument, "pemfile: failed to decrypt: %v", err)
+	}
+
+	newBlock, err := x509.EncryptPEMBlock(rand.Reader, block.Type, keyDER, []byte(newPassword), x509.PEMCipherAES256)
+	if err != nil {
+		return "", fmt.Errorf("pemfile: failed to encrypt: %v", err)
+	}
+	return string(pem.EncodeToMemory(newBlock)), nil
+}
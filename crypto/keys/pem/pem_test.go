@@ -16,11 +16,13 @@ package pem_test
 
 import (
 	"crypto"
+	"reflect"
 	"testing"
 
 	. "github.com/google/trillian/crypto/keys/pem"
 	ktestonly "github.com/google/trillian/crypto/keys/testonly"
 	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/errors"
 	"github.com/google/trillian/testonly"
 )
 
@@ -193,3 +195,72 @@ func TestLoadPrivateKeyAndSign(t *testing.T) {
 		}
 	}
 }
+
+func TestRekey(t *testing.T) {
+	const newPass = "new-towel"
+
+	wantKey, err := UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey() = (_, %v), want = (_, nil)", err)
+	}
+
+	rekeyed, err := Rekey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass, newPass)
+	if err != nil {
+		t.Fatalf("Rekey() = (_, %v), want = (_, nil)", err)
+	}
+
+	k, err := UnmarshalPrivateKey(rekeyed, newPass)
+	if err != nil {
+		t.Fatalf("UnmarshalPrivateKey(rekeyed, newPass) = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := k.Public(), wantKey.Public(); !reflect.DeepEqual(got, want) {
+		t.Errorf("rekeyed Public() = %v, want = %v", got, want)
+	}
+	if err := ktestonly.SignAndVerify(k, wantKey.Public()); err != nil {
+		t.Errorf("SignAndVerify() = %q, want nil", err)
+	}
+
+	if _, err := UnmarshalPrivateKey(rekeyed, testonly.DemoPrivateKeyPass); err == nil {
+		t.Errorf("UnmarshalPrivateKey(rekeyed, oldPass) = (_, nil), want = (_, err)")
+	}
+}
+
+func TestRekeyErrors(t *testing.T) {
+	tests := []struct {
+		desc             string
+		keyPEM           string
+		oldPass, newPass string
+		wantCode         errors.Code
+	}{
+		{
+			desc:     "wrongOldPassword",
+			keyPEM:   testonly.DemoPrivateKey,
+			oldPass:  testonly.DemoPrivateKeyPass + "foo",
+			newPass:  "new-towel",
+			wantCode: errors.InvalidArgument,
+		},
+		{
+			desc:     "emptyOldPassword",
+			keyPEM:   testonly.DemoPrivateKey,
+			newPass:  "new-towel",
+			wantCode: errors.InvalidArgument,
+		},
+		{
+			desc:     "emptyNewPassword",
+			keyPEM:   testonly.DemoPrivateKey,
+			oldPass:  testonly.DemoPrivateKeyPass,
+			wantCode: errors.InvalidArgument,
+		},
+		{
+			desc:     "corruptKey",
+			keyPEM:   corruptEcdsaPrivateKey,
+			newPass:  "new-towel",
+			wantCode: errors.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		if _, err := Rekey(test.keyPEM, test.oldPass, test.newPass); errors.ErrorCode(err) != test.wantCode {
+			t.Errorf("%v: Rekey() = (_, %v), wantCode = %s", test.desc, err, test.wantCode)
+		}
+	}
+}
@@ -18,10 +18,12 @@ package crypto
 import (
 	"crypto"
 	"crypto/rand"
+	"crypto/rsa"
 	"encoding/json"
 	"fmt"
 
 	"github.com/benlaurie/objecthash/go/objecthash"
+	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/sigpb"
 )
 
@@ -34,6 +36,10 @@ var sigpbHashLookup = map[crypto.Hash]sigpb.DigitallySigned_HashAlgorithm{
 type Signer struct {
 	Hash   crypto.Hash
 	Signer crypto.Signer
+
+	// Opts are passed to Signer, e.g. *rsa.PSSOptions to produce RSA-PSS
+	// signatures. If nil, Hash is used.
+	Opts crypto.SignerOpts
 }
 
 // NewSHA256Signer creates a new SHA256 based Signer.
@@ -44,6 +50,51 @@ func NewSHA256Signer(signer crypto.Signer) *Signer {
 	}
 }
 
+// NewSignerForTree creates a Signer that signs with the hash algorithm and
+// signature params configured by tree.
+func NewSignerForTree(tree *trillian.Tree, signer crypto.Signer) (*Signer, error) {
+	hash, err := TreeHash(tree)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := SignerOpts(tree)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{Hash: hash, Signer: signer, Opts: opts}, nil
+}
+
+// TreeHash returns the crypto.Hash configured by the tree's HashAlgorithm.
+func TreeHash(tree *trillian.Tree) (crypto.Hash, error) {
+	switch tree.HashAlgorithm {
+	case sigpb.DigitallySigned_SHA256:
+		return crypto.SHA256, nil
+	}
+	// There's no nil-like value for crypto.Hash, something has to be returned.
+	return crypto.SHA256, fmt.Errorf("unexpected hash algorithm: %s", tree.HashAlgorithm)
+}
+
+// SignerOpts returns the crypto.SignerOpts configured by the tree's
+// SignatureParams. Signatures of the tree must be verified with the same opts,
+// see VerifyWithOpts.
+// Returns nil opts if the tree uses default params.
+func SignerOpts(tree *trillian.Tree) (crypto.SignerOpts, error) {
+	hash, err := TreeHash(tree)
+	if err != nil {
+		return nil, err
+	}
+	switch padding := tree.GetSignatureParams().GetRsaPadding(); {
+	case padding == trillian.SignatureParams_PKCS1V15:
+		return nil, nil
+	case tree.SignatureAlgorithm != sigpb.DigitallySigned_RSA:
+		return nil, fmt.Errorf("rsa_padding %s not supported by signature algorithm %s", padding, tree.SignatureAlgorithm)
+	case padding == trillian.SignatureParams_PSS:
+		return &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}, nil
+	default:
+		return nil, fmt.Errorf("unexpected rsa_padding: %s", padding)
+	}
+}
+
 // Public returns the public key that can verify signatures produced by s.
 func (s *Signer) Public() crypto.PublicKey {
 	return s.Signer.Public()
@@ -55,7 +106,11 @@ func (s *Signer) Sign(data []byte) (*sigpb.DigitallySigned, error) {
 	h.Write(data)
 	digest := h.Sum(nil)
 
-	sig, err := s.Signer.Sign(rand.Reader, digest, s.Hash)
+	var opts crypto.SignerOpts = s.Hash
+	if s.Opts != nil {
+		opts = s.Opts
+	}
+	sig, err := s.Signer.Sign(rand.Reader, digest, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +51,12 @@ func VerifyObject(pub crypto.PublicKey, obj interface{}, sig *sigpb.DigitallySig
 
 // Verify cryptographically verifies the output of Signer.
 func Verify(pub crypto.PublicKey, data []byte, sig *sigpb.DigitallySigned) error {
+	return VerifyWithOpts(pub, data, sig, nil)
+}
+
+// VerifyWithOpts is like Verify, but for signatures produced by a Signer with
+// the specified Opts, e.g. *rsa.PSSOptions for RSA-PSS signatures.
+func VerifyWithOpts(pub crypto.PublicKey, data []byte, sig *sigpb.DigitallySigned, opts crypto.SignerOpts) error {
 	if sig == nil {
 		return errors.New("signature is nil")
 	}
@@ -72,7 +78,10 @@ func Verify(pub crypto.PublicKey, data []byte, sig *sigpb.DigitallySigned) error
 	case *ecdsa.PublicKey:
 		return verifyECDSA(pub, digest, sig.Signature)
 	case *rsa.PublicKey:
-		return verifyRSA(pub, digest, sig.Signature, hasher, hasher)
+		if opts == nil {
+			opts = hasher
+		}
+		return verifyRSA(pub, digest, sig.Signature, hasher, opts)
 	default:
 		return fmt.Errorf("unknown private key type: %T", pub)
 	}
@@ -15,6 +15,9 @@
 package crypto
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
 	"testing"
 
 	"github.com/google/trillian"
@@ -84,6 +87,27 @@ func TestSignVerify(t *testing.T) {
 	}
 }
 
+func TestSignVerifyWithOpts(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey()=(_,%v), want (_,nil)", err)
+	}
+	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+	signer := &Signer{Hash: crypto.SHA256, Signer: key, Opts: pssOpts}
+
+	msg := []byte("foo")
+	signature, err := signer.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign()=(_,%v), want (_,nil)", err)
+	}
+	if err := VerifyWithOpts(key.Public(), msg, signature, pssOpts); err != nil {
+		t.Errorf("VerifyWithOpts(PSS)=%v, want nil", err)
+	}
+	if err := Verify(key.Public(), msg, signature); err == nil {
+		t.Error("Verify()=nil for PSS signature, want err")
+	}
+}
+
 func TestSignVerifyObject(t *testing.T) {
 	key, err := pem.UnmarshalPrivateKey(testonly.DemoPrivateKey, testonly.DemoPrivateKeyPass)
 	if err != nil {
@@ -14,7 +14,10 @@
 
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Code mirrors gRPC's codes.Code.
 type Code uint32
@@ -201,3 +204,40 @@ func Errorf(code Code, format string, a ...interface{}) error {
 func New(code Code, msg string) error {
 	return &trillianError{code, msg}
 }
+
+// MultiError aggregates several errors into a single TrillianError.
+//
+// Its message is the concatenation of all messages and its code is the code
+// shared by all errors, or Unknown if codes differ. An empty MultiError
+// should not be returned as an error; see MultiError.ErrorOrNil.
+type MultiError []error
+
+func (m MultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Code returns the code shared by all errors in m, or Unknown if codes differ.
+func (m MultiError) Code() Code {
+	if len(m) == 0 {
+		return OK
+	}
+	code := ErrorCode(m[0])
+	for _, err := range m[1:] {
+		if ErrorCode(err) != code {
+			return Unknown
+		}
+	}
+	return code
+}
+
+// ErrorOrNil returns m as an error if it contains any errors, nil otherwise.
+func (m MultiError) ErrorOrNil() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
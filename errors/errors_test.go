@@ -98,6 +98,46 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestMultiError(t *testing.T) {
+	tests := []struct {
+		desc     string
+		errs     MultiError
+		wantMsg  string
+		wantCode Code
+	}{
+		{
+			desc:     "sameCode",
+			errs:     MultiError{New(InvalidArgument, "foo"), New(InvalidArgument, "bar")},
+			wantMsg:  "foo; bar",
+			wantCode: InvalidArgument,
+		},
+		{
+			desc:     "differentCodes",
+			errs:     MultiError{New(InvalidArgument, "foo"), New(NotFound, "bar")},
+			wantMsg:  "foo; bar",
+			wantCode: Unknown,
+		},
+		{
+			desc:     "genericError",
+			errs:     MultiError{errors.New("foo")},
+			wantMsg:  "foo",
+			wantCode: Unknown,
+		},
+	}
+	for _, test := range tests {
+		err := test.errs.ErrorOrNil()
+		if err == nil {
+			t.Errorf("%v: ErrorOrNil() = nil, want non-nil", test.desc)
+			continue
+		}
+		assertError(t, err, test.wantCode, test.wantMsg)
+	}
+
+	if err := (MultiError{}).ErrorOrNil(); err != nil {
+		t.Errorf("ErrorOrNil() = %v, want = nil", err)
+	}
+}
+
 func assertError(t *testing.T, err error, wantCode Code, wantMsg string) {
 	if got := err.Error(); got != wantMsg {
 		t.Errorf("Error() = %v, want = %v", got, wantMsg)
@@ -21,7 +21,10 @@ import (
 	"fmt"
 
 	log "github.com/golang/glog"
+	"github.com/google/trillian"
 	"github.com/google/trillian/merkle/hashers"
+
+	terr "github.com/google/trillian/errors"
 )
 
 // RootHashMismatchError indicates a unexpected root hash value.
@@ -119,6 +122,18 @@ func NewCompactMerkleTree(hasher hashers.LogHasher) *CompactMerkleTree {
 	return &r
 }
 
+// CompactTreeHasherForStrategy returns the node hash function of the
+// LogHasher registered for strategy, i.e., the same function used by proof
+// and storage code to hash interior nodes.
+// Returns an InvalidArgument error if no LogHasher is registered for strategy.
+func CompactTreeHasherForStrategy(strategy trillian.HashStrategy) (func(l, r []byte) []byte, error) {
+	h, err := hashers.NewLogHasher(strategy)
+	if err != nil {
+		return nil, terr.Errorf(terr.InvalidArgument, "no LogHasher registered for %v: %v", strategy, err)
+	}
+	return h.HashChildren, nil
+}
+
 // CurrentRoot returns the current root hash.
 func (c CompactMerkleTree) CurrentRoot() []byte {
 	return c.root
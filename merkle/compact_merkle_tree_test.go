@@ -22,10 +22,14 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/merkle/rfc6962"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/testonly"
 	"github.com/kylelemons/godebug/pretty"
+
+	terr "github.com/google/trillian/errors"
 )
 
 func checkUnusedNodesInvariant(c *CompactMerkleTree) error {
@@ -293,3 +297,31 @@ func TestRootHashForVariousTreeSizes(t *testing.T) {
 		}
 	}
 }
+
+func TestCompactTreeHasherForStrategy(t *testing.T) {
+	strategy := trillian.HashStrategy_RFC6962_SHA256
+	hashChildren, err := CompactTreeHasherForStrategy(strategy)
+	if err != nil {
+		t.Fatalf("CompactTreeHasherForStrategy(%v) = (_, %v), want = (_, nil)", strategy, err)
+	}
+	h, err := hashers.NewLogHasher(strategy)
+	if err != nil {
+		t.Fatalf("NewLogHasher(%v) = (_, %v), want = (_, nil)", strategy, err)
+	}
+
+	inputs := []struct{ l, r []byte }{
+		{l: []byte{}, r: []byte{}},
+		{l: []byte("left"), r: []byte("right")},
+		{l: []byte("right"), r: []byte("left")},
+		{l: rfc6962.DefaultHasher.EmptyRoot(), r: testonly.MustDecodeBase64("6uU/phfHg1n/GksYT6TO9aN8EauMCCJRl3dIK0HDs2M=")},
+	}
+	for _, input := range inputs {
+		if got, want := hashChildren(input.l, input.r), h.HashChildren(input.l, input.r); !bytes.Equal(got, want) {
+			t.Errorf("hashChildren(%x, %x) = %x, want = %x", input.l, input.r, got, want)
+		}
+	}
+
+	if _, err := CompactTreeHasherForStrategy(trillian.HashStrategy_UNKNOWN_HASH_STRATEGY); terr.ErrorCode(err) != terr.InvalidArgument {
+		t.Errorf("CompactTreeHasherForStrategy(UNKNOWN_HASH_STRATEGY) returned err = %v, wantCode = %s", err, terr.InvalidArgument)
+	}
+}
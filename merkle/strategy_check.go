@@ -0,0 +1,95 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+
+	terr "github.com/google/trillian/errors"
+)
+
+// selfTestTreeID is the tree ID used to hash map leaves during self tests.
+const selfTestTreeID = 1
+
+// SelfTestStrategy checks that strategy is usable end-to-end in this binary,
+// i.e., that its hasher is registered and produces verifiable inclusion
+// proofs over a few sample leaves.
+// Returns an InvalidArgument error if no LogHasher or MapHasher is registered
+// for strategy, and a generic error if proofs fail to verify.
+func SelfTestStrategy(strategy trillian.HashStrategy) error {
+	tested := false
+	if h, err := hashers.NewLogHasher(strategy); err == nil {
+		if err := selfTestLogHasher(h); err != nil {
+			return fmt.Errorf("%v: LogHasher self test failed: %v", strategy, err)
+		}
+		tested = true
+	}
+	if h, err := hashers.NewMapHasher(strategy); err == nil {
+		if err := selfTestMapHasher(h); err != nil {
+			return fmt.Errorf("%v: MapHasher self test failed: %v", strategy, err)
+		}
+		tested = true
+	}
+	if !tested {
+		return terr.Errorf(terr.InvalidArgument, "no hasher registered for %v", strategy)
+	}
+	return nil
+}
+
+// selfTestLogHasher builds a 4-leaf tree and verifies the inclusion proof of
+// one of its leaves.
+func selfTestLogHasher(h hashers.LogHasher) error {
+	var leafHashes [][]byte
+	for i := 0; i < 4; i++ {
+		// Leaves are valid JSON, as required by object hashers.
+		leafHash, err := h.HashLeaf([]byte(fmt.Sprintf(`{"leaf":%v}`, i)))
+		if err != nil {
+			return fmt.Errorf("HashLeaf(): %v", err)
+		}
+		leafHashes = append(leafHashes, leafHash)
+	}
+	left := h.HashChildren(leafHashes[0], leafHashes[1])
+	right := h.HashChildren(leafHashes[2], leafHashes[3])
+	root := h.HashChildren(left, right)
+
+	const leafIndex = 2
+	proof := [][]byte{leafHashes[3], left}
+	return NewLogVerifier(h).VerifyInclusionProof(leafIndex, int64(len(leafHashes)), proof, root, leafHashes[leafIndex])
+}
+
+// selfTestMapHasher builds a single-leaf sparse tree and verifies the
+// inclusion proof of its leaf, which only has empty siblings.
+func selfTestMapHasher(h hashers.MapHasher) error {
+	index := bytes.Repeat([]byte{0x5a}, h.Size())
+	leaf := []byte(`{"leaf":0}`)
+	leafHash, err := h.HashLeaf(selfTestTreeID, index, leaf)
+	if err != nil {
+		return fmt.Errorf("HashLeaf(): %v", err)
+	}
+	hs2 := NewHStar2(selfTestTreeID, h)
+	root, err := hs2.HStar2Root(h.BitLen(), []HStar2LeafHash{
+		{Index: new(big.Int).SetBytes(index), LeafHash: leafHash},
+	})
+	if err != nil {
+		return fmt.Errorf("HStar2Root(): %v", err)
+	}
+	proof := make([][]byte, h.BitLen())
+	return VerifyMapInclusionProof(selfTestTreeID, index, leaf, root, proof, h)
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkle
+
+import (
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+
+	_ "github.com/google/trillian/merkle/coniks"    // CONIKS_SHA512_256
+	_ "github.com/google/trillian/merkle/maphasher" // TEST_MAP_HASHER
+	_ "github.com/google/trillian/merkle/objhasher" // OBJECT_RFC6962_SHA256
+	_ "github.com/google/trillian/merkle/rfc6962"   // RFC6962_SHA256
+)
+
+func TestSelfTestStrategy(t *testing.T) {
+	for s := range trillian.HashStrategy_name {
+		strategy := trillian.HashStrategy(s)
+		if strategy == trillian.HashStrategy_UNKNOWN_HASH_STRATEGY {
+			continue
+		}
+		if err := SelfTestStrategy(strategy); err != nil {
+			t.Errorf("SelfTestStrategy(%v) = %v, want = nil", strategy, err)
+		}
+	}
+
+	for _, strategy := range []trillian.HashStrategy{trillian.HashStrategy_UNKNOWN_HASH_STRATEGY, trillian.HashStrategy(1000)} {
+		if err := SelfTestStrategy(strategy); errors.ErrorCode(err) != errors.InvalidArgument {
+			t.Errorf("SelfTestStrategy(%v) returned err = %v, wantCode = %s", strategy, err, errors.InvalidArgument)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testonly contains code and data that should only be used by tests
+// of Merkle tree implementations and their clients.
+package testonly
+
+import (
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/hashers"
+)
+
+// ProofVectors are known-answer test vectors for a log tree built from
+// ProofVectorLeaf(0) to ProofVectorLeaf(TreeSize-1), as generated by
+// GenerateProofVectors.
+type ProofVectors struct {
+	HashStrategy trillian.HashStrategy
+	TreeSize     int64
+
+	// Leaves contains the leaf data of the tree, in order.
+	Leaves [][]byte
+
+	// Roots contains the root hash of every tree size from 0 to TreeSize,
+	// indexed by size.
+	Roots [][]byte
+
+	// Inclusion contains an inclusion proof of each leaf in the tree of size
+	// TreeSize, indexed by leaf.
+	Inclusion []InclusionVector
+
+	// Consistency contains a consistency proof between each pair of
+	// non-empty sizes 0 < Size1 < Size2 <= TreeSize, ordered by Size1, then
+	// Size2.
+	Consistency []ConsistencyVector
+}
+
+// InclusionVector is an inclusion proof of LeafIndex in a tree of TreeSize.
+type InclusionVector struct {
+	LeafIndex int64
+	TreeSize  int64
+	Proof     [][]byte
+}
+
+// ConsistencyVector is a consistency proof between trees of Size1 and Size2.
+type ConsistencyVector struct {
+	Size1 int64
+	Size2 int64
+	Proof [][]byte
+}
+
+// ProofVectorLeaf returns the leaf data at index of the trees used by
+// GenerateProofVectors. Leaves are small JSON objects, so they're valid for
+// both raw and object hashers.
+func ProofVectorLeaf(index int64) []byte {
+	return []byte(fmt.Sprintf(`{"leaf":%d}`, index))
+}
+
+// GenerateProofVectors returns the ProofVectors of a tree of treeSize leaves
+// hashed as per strategy, which must have a registered LogHasher.
+// Vectors are deterministic: the same parameters always yield the same
+// vectors, so they're suitable as golden data.
+func GenerateProofVectors(strategy trillian.HashStrategy, treeSize int64) (*ProofVectors, error) {
+	if treeSize < 0 {
+		return nil, errors.Errorf(errors.InvalidArgument, "invalid tree size: %v", treeSize)
+	}
+	hasher, err := hashers.NewLogHasher(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := &ProofVectors{
+		HashStrategy: strategy,
+		TreeSize:     treeSize,
+		Roots:        [][]byte{hasher.EmptyRoot()},
+	}
+	mt := merkle.NewInMemoryMerkleTree(hasher)
+	for i := int64(0); i < treeSize; i++ {
+		leaf := ProofVectorLeaf(i)
+		if _, _, err := mt.AddLeaf(leaf); err != nil {
+			return nil, err
+		}
+		vectors.Leaves = append(vectors.Leaves, leaf)
+		vectors.Roots = append(vectors.Roots, mt.CurrentRoot().Hash())
+	}
+
+	// The in-memory tree uses 1-based leaf indexing.
+	for i := int64(0); i < treeSize; i++ {
+		vectors.Inclusion = append(vectors.Inclusion, InclusionVector{
+			LeafIndex: i,
+			TreeSize:  treeSize,
+			Proof:     proofHashes(mt.PathToRootAtSnapshot(i+1, treeSize)),
+		})
+	}
+	for size1 := int64(1); size1 < treeSize; size1++ {
+		for size2 := size1 + 1; size2 <= treeSize; size2++ {
+			vectors.Consistency = append(vectors.Consistency, ConsistencyVector{
+				Size1: size1,
+				Size2: size2,
+				Proof: proofHashes(mt.SnapshotConsistency(size1, size2)),
+			})
+		}
+	}
+	return vectors, nil
+}
+
+func proofHashes(path []merkle.TreeEntryDescriptor) [][]byte {
+	hashes := make([][]byte, 0, len(path))
+	for _, node := range path {
+		hashes = append(hashes, node.Value.Hash())
+	}
+	return hashes
+}
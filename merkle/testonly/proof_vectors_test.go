@@ -0,0 +1,82 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testonly
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/hashers"
+
+	_ "github.com/google/trillian/merkle/rfc6962"
+)
+
+func TestGenerateProofVectors(t *testing.T) {
+	strategy := trillian.HashStrategy_RFC6962_SHA256
+	hasher, err := hashers.NewLogHasher(strategy)
+	if err != nil {
+		t.Fatalf("NewLogHasher() returned err = %v", err)
+	}
+	verifier := merkle.NewLogVerifier(hasher)
+
+	for _, treeSize := range []int64{0, 1, 2, 7, 8, 13} {
+		vectors, err := GenerateProofVectors(strategy, treeSize)
+		if err != nil {
+			t.Fatalf("GenerateProofVectors(%v) = (_, %v), want = (_, nil)", treeSize, err)
+		}
+		if got, want := len(vectors.Roots), int(treeSize+1); got != want {
+			t.Fatalf("GenerateProofVectors(%v): len(Roots) = %v, want = %v", treeSize, got, want)
+		}
+		if got, want := len(vectors.Inclusion), int(treeSize); got != want {
+			t.Errorf("GenerateProofVectors(%v): len(Inclusion) = %v, want = %v", treeSize, got, want)
+		}
+
+		root := vectors.Roots[treeSize]
+		for _, v := range vectors.Inclusion {
+			leafHash, err := hasher.HashLeaf(vectors.Leaves[v.LeafIndex])
+			if err != nil {
+				t.Fatalf("HashLeaf() returned err = %v", err)
+			}
+			if err := verifier.VerifyInclusionProof(v.LeafIndex, v.TreeSize, v.Proof, root, leafHash); err != nil {
+				t.Errorf("size %v: VerifyInclusionProof(%v) returned err = %v", treeSize, v.LeafIndex, err)
+			}
+		}
+		for _, v := range vectors.Consistency {
+			if err := verifier.VerifyConsistencyProof(v.Size1, v.Size2, vectors.Roots[v.Size1], vectors.Roots[v.Size2], v.Proof); err != nil {
+				t.Errorf("size %v: VerifyConsistencyProof(%v, %v) returned err = %v", treeSize, v.Size1, v.Size2, err)
+			}
+		}
+
+		regenerated, err := GenerateProofVectors(strategy, treeSize)
+		if err != nil {
+			t.Fatalf("GenerateProofVectors(%v) = (_, %v), want = (_, nil)", treeSize, err)
+		}
+		if !reflect.DeepEqual(regenerated, vectors) {
+			t.Errorf("GenerateProofVectors(%v) isn't deterministic: got %v, then %v", treeSize, vectors, regenerated)
+		}
+	}
+}
+
+func TestGenerateProofVectorsErrors(t *testing.T) {
+	if _, err := GenerateProofVectors(trillian.HashStrategy_RFC6962_SHA256, -1); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("GenerateProofVectors(size = -1) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+	if _, err := GenerateProofVectors(trillian.HashStrategy_UNKNOWN_HASH_STRATEGY, 1); err == nil {
+		t.Error("GenerateProofVectors(UNKNOWN_HASH_STRATEGY) returned err = nil, want non-nil")
+	}
+}
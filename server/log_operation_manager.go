@@ -233,17 +233,19 @@ func NewLogOperationManager(info LogOperationInfo, logOperation LogOperation) *L
 	}
 }
 
-// getLogIDs returns the current set of active log IDs, whether we are master for them or not.
+// getLogIDs returns the current set of sequenceable log IDs, whether we are
+// master for them or not. Deleted, frozen and paused logs, and logs in states
+// like MAINTENANCE, aren't included; see AdminReader.ListSequenceableTreeIDs.
 func (l *LogOperationManager) getLogIDs(ctx context.Context) ([]int64, error) {
-	tx, err := l.info.Registry.LogStorage.Snapshot(ctx)
+	tx, err := l.info.Registry.AdminStorage.Snapshot(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tx for retrieving logIDs: %v", err)
 	}
 	defer tx.Close()
 
-	logIDs, err := tx.GetActiveLogIDs(ctx)
+	logIDs, err := tx.ListSequenceableTreeIDs(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active logIDs: %v", err)
+		return nil, fmt.Errorf("failed to get sequenceable logIDs: %v", err)
 	}
 
 	if err := tx.Commit(); err != nil {
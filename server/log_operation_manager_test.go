@@ -28,6 +28,8 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/extension"
 	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/memory"
+	stestonly "github.com/google/trillian/storage/testonly"
 	"github.com/google/trillian/util"
 )
 
@@ -45,11 +47,11 @@ func TestLogOperationManagerSnapshotFails(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockStorage := storage.NewMockLogStorage(ctrl)
-	mockStorage.EXPECT().Snapshot(gomock.Any()).Return(nil, errors.New("TX"))
+	mockAdmin := storage.NewMockAdminStorage(ctrl)
+	mockAdmin.EXPECT().Snapshot(gomock.Any()).Return(nil, errors.New("TX"))
 
 	registry := extension.Registry{
-		LogStorage: mockStorage,
+		AdminStorage: mockAdmin,
 	}
 
 	mockLogOp := NewMockLogOperation(ctrl)
@@ -65,14 +67,14 @@ func TestLogOperationManagerGetLogsFails(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockTx := storage.NewMockReadOnlyLogTX(ctrl)
-	mockTx.EXPECT().GetActiveLogIDs(gomock.Any()).Return(nil, errors.New("getactivelogs"))
+	mockTx := storage.NewMockReadOnlyAdminTX(ctrl)
+	mockTx.EXPECT().ListSequenceableTreeIDs(gomock.Any()).Return(nil, errors.New("listsequenceable"))
 	mockTx.EXPECT().Close().Return(nil)
-	mockStorage := storage.NewMockLogStorage(ctrl)
-	mockStorage.EXPECT().Snapshot(gomock.Any()).Return(mockTx, nil)
+	mockAdmin := storage.NewMockAdminStorage(ctrl)
+	mockAdmin.EXPECT().Snapshot(gomock.Any()).Return(mockTx, nil)
 
 	registry := extension.Registry{
-		LogStorage: mockStorage,
+		AdminStorage: mockAdmin,
 	}
 
 	mockLogOp := NewMockLogOperation(ctrl)
@@ -88,15 +90,15 @@ func TestLogOperationManagerCommitFails(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockTx := storage.NewMockReadOnlyLogTX(ctrl)
-	mockTx.EXPECT().GetActiveLogIDs(gomock.Any()).Return([]int64{}, nil)
+	mockTx := storage.NewMockReadOnlyAdminTX(ctrl)
+	mockTx.EXPECT().ListSequenceableTreeIDs(gomock.Any()).Return([]int64{}, nil)
 	mockTx.EXPECT().Commit().Return(errors.New("commit"))
 	mockTx.EXPECT().Close().Return(nil)
-	mockStorage := storage.NewMockLogStorage(ctrl)
-	mockStorage.EXPECT().Snapshot(gomock.Any()).Return(mockTx, nil)
+	mockAdmin := storage.NewMockAdminStorage(ctrl)
+	mockAdmin.EXPECT().Snapshot(gomock.Any()).Return(mockTx, nil)
 
 	registry := extension.Registry{
-		LogStorage: mockStorage,
+		AdminStorage: mockAdmin,
 	}
 
 	mockLogOp := NewMockLogOperation(ctrl)
@@ -134,14 +136,9 @@ func setupLogIDs(ctrl *gomock.Controller, logNames map[int64]string) (*storage.M
 	}
 
 	mockStorage := storage.NewMockLogStorage(ctrl)
-	mockTx := storage.NewMockReadOnlyLogTX(ctrl)
-	mockTx.EXPECT().GetActiveLogIDs(gomock.Any()).AnyTimes().Return(ids, nil)
-	mockTx.EXPECT().Commit().AnyTimes().Return(nil)
-	mockTx.EXPECT().Close().AnyTimes().Return(nil)
-	mockStorage.EXPECT().Snapshot(gomock.Any()).AnyTimes().Return(mockTx, nil)
-
 	mockAdmin := storage.NewMockAdminStorage(ctrl)
 	mockAdminTx := storage.NewMockReadOnlyAdminTX(ctrl)
+	mockAdminTx.EXPECT().ListSequenceableTreeIDs(gomock.Any()).AnyTimes().Return(ids, nil)
 	for id, name := range logNames {
 		mockAdminTx.EXPECT().GetTree(gomock.Any(), id).AnyTimes().Return(&trillian.Tree{TreeId: id, DisplayName: name}, nil)
 	}
@@ -178,6 +175,48 @@ func TestLogOperationManagerPassesIDs(t *testing.T) {
 	lom.OperationSingle(ctx)
 }
 
+func TestLogOperationManagerSkipsPausedLogs(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logStorage := memory.NewLogStorage(nil)
+	adminStorage := memory.NewAdminStorage(logStorage)
+	tx, err := adminStorage.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	active, err := tx.CreateTree(ctx, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree() = (_, %v), want = (_, nil)", err)
+	}
+	paused, err := tx.CreateTree(ctx, stestonly.LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree() = (_, %v), want = (_, nil)", err)
+	}
+	if _, err := tx.PauseSequencing(ctx, paused.TreeId); err != nil {
+		t.Fatalf("PauseSequencing() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+
+	registry := extension.Registry{
+		LogStorage:   logStorage,
+		AdminStorage: adminStorage,
+	}
+	// Only the active log is sequenced, gomock fails the test on an
+	// unexpected ExecutePass for the paused one.
+	mockLogOp := NewMockLogOperation(ctrl)
+	mockLogOp.EXPECT().ExecutePass(gomock.Any(), active.TreeId, gomock.Any())
+
+	info := defaultLogOperationInfo(registry)
+	lom := NewLogOperationManager(info, mockLogOp)
+
+	lom.OperationSingle(ctx)
+}
+
 func TestHeldInfo(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
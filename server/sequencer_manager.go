@@ -30,6 +30,11 @@ import (
 	"github.com/google/trillian/trees"
 )
 
+// sequencingProgressInterval is how stale the recorded LastSequencedTime of a
+// tree may get before a sequencing pass records progress again. It bounds how
+// often passes write to admin storage.
+const sequencingProgressInterval = time.Minute
+
 // SequencerManager provides sequencing operations for a collection of Logs.
 type SequencerManager struct {
 	guardWindow  time.Duration
@@ -89,9 +94,38 @@ func (s *SequencerManager) ExecutePass(ctx context.Context, logID int64, info *L
 	if err != nil {
 		return 0, fmt.Errorf("failed to sequence batch for %v: %v", logID, err)
 	}
+	if leaves > 0 {
+		// Progress is only informational, so failing to record it doesn't
+		// fail the pass.
+		if err := s.recordProgress(ctx, tree, info.TimeSource.Now()); err != nil {
+			glog.Warningf("%v: failed to record sequencing progress: %v", logID, err)
+		}
+	}
 	return leaves, nil
 }
 
+// recordProgress sets the LastSequencedTime of the given tree to now, unless
+// it was recorded less than sequencingProgressInterval ago.
+func (s *SequencerManager) recordProgress(ctx context.Context, tree *trillian.Tree, now time.Time) error {
+	if last, err := ptypes.Timestamp(tree.LastSequencedTime); err == nil && now.Sub(last) < sequencingProgressInterval {
+		return nil
+	}
+	logID := tree.TreeId
+	when, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return err
+	}
+	tx, err := s.registry.AdminStorage.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := tx.RecordSequencingProgress(ctx, logID, when); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // getSigner returns a signer for the given tree.
 // Signers are cached, so only one will be created per tree.
 func (s *SequencerManager) getSigner(ctx context.Context, tree *trillian.Tree) (*crypto.Signer, error) {
@@ -132,7 +132,7 @@ func TestSequencerManagerSingleLogNoLeaves(t *testing.T) {
 	mockTx.EXPECT().DequeueLeaves(gomock.Any(), 50, fakeTime).Return([]*trillian.LogLeaf{}, nil)
 
 	mockAdmin.EXPECT().Snapshot(gomock.Any()).Return(mockAdminTx, nil)
-	mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(stestonly.LogTree, nil)
+	mockAdminTx.EXPECT().GetTree(stestonly.PrivateKeyAccessContext(), logID).Return(stestonly.LogTree, nil)
 	mockAdminTx.EXPECT().Commit().Return(nil)
 	mockAdminTx.EXPECT().Close().Return(nil)
 
@@ -180,7 +180,7 @@ func TestSequencerManagerCachesSigners(t *testing.T) {
 	for i := 0; i < 2; i++ {
 		gomock.InOrder(
 			mockAdmin.EXPECT().Snapshot(gomock.Any()).Return(mockAdminTx, nil),
-			mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(stestonly.LogTree, nil),
+			mockAdminTx.EXPECT().GetTree(stestonly.PrivateKeyAccessContext(), logID).Return(stestonly.LogTree, nil),
 			mockAdminTx.EXPECT().Commit().Return(nil),
 			mockAdminTx.EXPECT().Close().Return(nil),
 		)
@@ -227,7 +227,7 @@ func TestSequencerManagerSingleLogNoSigner(t *testing.T) {
 
 	gomock.InOrder(
 		mockAdmin.EXPECT().Snapshot(gomock.Any()).Return(mockAdminTx, nil),
-		mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(stestonly.LogTree, nil),
+		mockAdminTx.EXPECT().GetTree(stestonly.PrivateKeyAccessContext(), logID).Return(stestonly.LogTree, nil),
 		mockAdminTx.EXPECT().Commit().Return(nil),
 		mockAdminTx.EXPECT().Close().Return(nil),
 	)
@@ -245,11 +245,37 @@ func TestSequencerManagerSingleLogNoSigner(t *testing.T) {
 }
 
 func TestSequencerManagerSingleLogOneLeaf(t *testing.T) {
+	tests := []struct {
+		desc              string
+		lastSequencedTime time.Time
+		wantRecord        bool
+	}{
+		{desc: "neverSequenced", wantRecord: true},
+		{desc: "recentlySequenced", lastSequencedTime: fakeTime.Add(-sequencingProgressInterval / 2)},
+		{desc: "staleSequenced", lastSequencedTime: fakeTime.Add(-2 * sequencingProgressInterval), wantRecord: true},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			tree := proto.Clone(stestonly.LogTree).(*trillian.Tree)
+			if !test.lastSequencedTime.IsZero() {
+				var err error
+				if tree.LastSequencedTime, err = ptypes.TimestampProto(test.lastSequencedTime); err != nil {
+					t.Fatalf("TimestampProto() = (_, %v), want = (_, nil)", err)
+				}
+			}
+			testSequencerManagerSingleLogOneLeaf(t, tree, test.wantRecord)
+		})
+	}
+}
+
+// testSequencerManagerSingleLogOneLeaf runs a pass integrating one leaf into
+// tree, expecting sequencing progress to be recorded if wantRecord is true.
+func testSequencerManagerSingleLogOneLeaf(t *testing.T, tree *trillian.Tree, wantRecord bool) {
 	ctx := context.Background()
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
-	logID := stestonly.LogTree.GetTreeId()
+	logID := tree.GetTreeId()
 	mockAdmin := storage.NewMockAdminStorage(mockCtrl)
 	mockAdminTx := storage.NewMockReadOnlyAdminTX(mockCtrl)
 	mockStorage := storage.NewMockLogStorage(mockCtrl)
@@ -281,10 +307,24 @@ func TestSequencerManagerSingleLogOneLeaf(t *testing.T) {
 	mockStorage.EXPECT().BeginForTree(gomock.Any(), logID).Return(mockTx, nil)
 
 	mockAdmin.EXPECT().Snapshot(gomock.Any()).Return(mockAdminTx, nil)
-	mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(stestonly.LogTree, nil)
+	mockAdminTx.EXPECT().GetTree(stestonly.PrivateKeyAccessContext(), logID).Return(tree, nil)
 	mockAdminTx.EXPECT().Commit().Return(nil)
 	mockAdminTx.EXPECT().Close().Return(nil)
 
+	// Integrating a leaf records sequencing progress, unless it was recorded
+	// recently.
+	if wantRecord {
+		fakeTimestamp, err := ptypes.TimestampProto(fakeTime)
+		if err != nil {
+			t.Fatalf("TimestampProto() = (_, %v), want = (_, nil)", err)
+		}
+		mockAdminWriteTx := storage.NewMockAdminTX(mockCtrl)
+		mockAdmin.EXPECT().Begin(gomock.Any()).Return(mockAdminWriteTx, nil)
+		mockAdminWriteTx.EXPECT().RecordSequencingProgress(gomock.Any(), logID, fakeTimestamp).Return(nil)
+		mockAdminWriteTx.EXPECT().Commit().Return(nil)
+		mockAdminWriteTx.EXPECT().Close().Return(nil)
+	}
+
 	registry := extension.Registry{
 		AdminStorage: mockAdmin,
 		LogStorage:   mockStorage,
@@ -328,7 +368,7 @@ func TestSequencerManagerGuardWindow(t *testing.T) {
 	mockTx.EXPECT().DequeueLeaves(gomock.Any(), 50, fakeTime.Add(-time.Second*5)).Return([]*trillian.LogLeaf{}, nil)
 
 	mockAdmin.EXPECT().Snapshot(gomock.Any()).Return(mockAdminTx, nil)
-	mockAdminTx.EXPECT().GetTree(gomock.Any(), logID).Return(stestonly.LogTree, nil)
+	mockAdminTx.EXPECT().GetTree(stestonly.PrivateKeyAccessContext(), logID).Return(stestonly.LogTree, nil)
 	mockAdminTx.EXPECT().Commit().Return(nil)
 	mockAdminTx.EXPECT().Close().Return(nil)
 
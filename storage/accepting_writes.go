@@ -0,0 +1,49 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"sync/atomic"
+
+	"github.com/google/trillian/errors"
+)
+
+// acceptingWrites is 1 if new trees may be created, 0 otherwise.
+var acceptingWrites int32 = 1
+
+// SetAcceptingWrites sets whether AdminStorage implementations accept tree
+// creations (CreateTree and ImportTree) for the whole process. It's meant to
+// stop new trees from being created during graceful shutdown.
+// Only transactions started after the call are affected: transactions that
+// are already open keep the behavior in effect when they began, so in-flight
+// operations may still commit.
+func SetAcceptingWrites(accepting bool) {
+	var v int32
+	if accepting {
+		v = 1
+	}
+	atomic.StoreInt32(&acceptingWrites, v)
+}
+
+// AcceptingWrites returns whether tree creations are accepted, as per
+// SetAcceptingWrites. AdminStorage implementations should call it when
+// beginning transactions.
+func AcceptingWrites() bool {
+	return atomic.LoadInt32(&acceptingWrites) == 1
+}
+
+// ErrNotAcceptingWrites is returned by tree creations on transactions that
+// began while SetAcceptingWrites(false) was in effect.
+var ErrNotAcceptingWrites = errors.New(errors.Unavailable, "storage is not accepting new trees")
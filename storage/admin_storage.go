@@ -16,8 +16,12 @@ package storage
 
 import (
 	"context"
+	"crypto/elliptic"
+	"time"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/trillian"
+	"github.com/google/trillian/util"
 )
 
 // ReadOnlyAdminTX is a transaction capable only of read operations in the
@@ -71,6 +75,87 @@ type AdminStorage interface {
 	CheckDatabaseAccessible(ctx context.Context) error
 }
 
+// AdminStorageOptions configures optional AdminStorage behavior. The zero
+// value is the default for all implementations.
+type AdminStorageOptions struct {
+	// AdvanceUpdateTimeOnNoop makes updates that don't modify the tree still
+	// set its UpdateTime to the current time. By default no-op updates leave
+	// the stored tree, including UpdateTime, unchanged.
+	AdvanceUpdateTimeOnNoop bool
+
+	// RequirePrivateKeyAccess makes all methods that return trees, reads
+	// and writes alike, return them without their private key, unless the
+	// context was derived from WithPrivateKeyAccess.
+	// Signing paths (via trees.GetTree) and storage helpers that need keys,
+	// such as ExportTreesSince and PlanReconciliation, grant access
+	// themselves.
+	RequirePrivateKeyAccess bool
+
+	// MaxDescriptionBytes makes ListTreesForDisplay truncate the Description
+	// of each returned tree to at most MaxDescriptionBytes bytes, ending in
+	// DescriptionEllipsis, as per TruncateDescription. Other reads, including
+	// GetTree and ListTrees, always return the full Description. Zero
+	// disables truncation.
+	MaxDescriptionBytes int
+
+	// FreezeIsTerminal makes updates reject transitions out of the FROZEN
+	// state, so frozen trees stay frozen. By default trees may be unfrozen.
+	FreezeIsTerminal bool
+
+	// VerifyConfigChecksumOnRead makes GetTree, ListTrees and other reads
+	// of full trees compare the TreeConfigHash of each tree read against the
+	// checksum stored when the tree was last written, returning a DataLoss
+	// error on mismatch. Trees without a stored checksum aren't verified.
+	// Storages that can't be corrupted independently of their checksums,
+	// such as in-memory ones, ignore it.
+	VerifyConfigChecksumOnRead bool
+
+	// AllowedECCurves lists the curves of ECDSA keys accepted by CreateTree,
+	// as per ValidateTreeKeyCurve. If empty, keys on any curve are accepted.
+	// Existing and imported trees aren't affected by it.
+	AllowedECCurves []elliptic.Curve
+
+	// MinRsaKeySizeInBits is the smallest RSA key accepted by CreateTree.
+	// Zero means keys.MinRsaKeySizeInBits. Existing and imported trees
+	// aren't affected by it.
+	MinRsaKeySizeInBits int
+
+	// MaxAppDataKeyLength, MaxAppDataValueLength and MaxAppDataSize limit
+	// the app_data accepted by tree validation: the length of individual keys
+	// and values, and the total length of all keys and values, respectively.
+	// Zero means DefaultMaxAppDataKeyLength, DefaultMaxAppDataValueLength and
+	// DefaultMaxAppDataSize. MaxAppDataKeyLength can't be raised past
+	// MaxAppDataKeyLengthLimit, larger values are treated as the limit.
+	MaxAppDataKeyLength   int
+	MaxAppDataValueLength int
+	MaxAppDataSize        int
+
+	// MaxListPageSize is the largest page returned by ListTreesPaginated.
+	// Larger page sizes are silently clamped to it. Zero means
+	// DefaultMaxListPageSize.
+	MaxListPageSize int
+
+	// TreeIDReservationTTL is how long IDs reserved via ReserveTreeID remain
+	// reserved. Expired reservations may not be used to create trees, and
+	// their IDs may be reused. Zero means DefaultTreeIDReservationTTL.
+	TreeIDReservationTTL time.Duration
+
+	// TimeSource provides the current time to storages, for the CreateTime,
+	// UpdateTime and DeleteTime of trees, and to storage helpers that
+	// timestamp what they write, such as RecordTreeCountSnapshot. Nil means
+	// util.SystemTimeSource.
+	TimeSource util.TimeSource
+}
+
+// TimeSourceFor returns the TimeSource configured in opts, or
+// util.SystemTimeSource if there's none.
+func TimeSourceFor(opts AdminStorageOptions) util.TimeSource {
+	if opts.TimeSource == nil {
+		return util.SystemTimeSource{}
+	}
+	return opts.TimeSource
+}
+
 // AdminReader provides a read-only interface for tree data.
 type AdminReader interface {
 	// GetTree returns the tree corresponding to treeID or an error.
@@ -85,32 +170,218 @@ type AdminReader interface {
 	// Note that there's no authorization restriction on the trees returned,
 	// so it should be used with caution in production code.
 	ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error)
+
+	// ListTreesForDisplay returns all trees in storage, as per ListTrees,
+	// with descriptions truncated as per AdminStorageOptions.MaxDescriptionBytes.
+	// Returned trees are lossy and meant for display only; they must not be
+	// written back to storage or exported.
+	ListTreesForDisplay(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error)
+
+	// ListSequenceableTreeIDs returns the IDs of all trees that should be
+	// processed by the sequencer, i.e., non-deleted LOG and PREORDERED_LOG
+	// trees in either the ACTIVE or DRAINING state, whose sequencing isn't
+	// paused.
+	ListSequenceableTreeIDs(ctx context.Context) ([]int64, error)
+
+	// GetTreeKeyInfo returns information about the signing key of treeID, as
+	// derived solely from its public key. Implementations must not read the
+	// tree's private key.
+	GetTreeKeyInfo(ctx context.Context, treeID int64) (*KeyInfo, error)
+
+	// ResolveTreeAlias returns the ID of the tree alias currently points to.
+	// Returns a NotFound error if alias isn't set.
+	ResolveTreeAlias(ctx context.Context, alias string) (int64, error)
+
+	// ListTreeCountSnapshots returns all snapshots written by
+	// WriteTreeCountSnapshot taken at or after from and before to, ordered
+	// by time.
+	ListTreeCountSnapshots(ctx context.Context, from, to time.Time) ([]CountSnapshot, error)
+
+	// ListHardDeletableTrees returns all soft-deleted trees whose DeleteTime
+	// is older than retention, as of now, ordered by DeleteTime. These are
+	// the trees eligible for HardDeleteTree under that retention window.
+	ListHardDeletableTrees(ctx context.Context, retention time.Duration, now time.Time) ([]*trillian.Tree, error)
+
+	// GetTreesByDisplayNames returns the trees with the specified display
+	// names, keyed by display name. Names that don't match any tree are
+	// omitted from the result. Soft-deleted trees are only considered if
+	// includeDeleted is true.
+	// Returns a FailedPrecondition error if a name matches more than one tree.
+	GetTreesByDisplayNames(ctx context.Context, names []string, includeDeleted bool) (map[string]*trillian.Tree, error)
+
+	// ListRecentTrees returns up to limit trees, newest first, ordered by
+	// CreateTime. Trees with the same CreateTime are ordered by descending
+	// tree ID. Soft-deleted trees are only included if includeDeleted is true.
+	// Returns an InvalidArgument error if limit isn't positive.
+	ListRecentTrees(ctx context.Context, limit int, includeDeleted bool) ([]*trillian.Tree, error)
+
+	// ListUntouchedTrees returns all trees that were never updated since
+	// creation, i.e. whose UpdateTime equals their CreateTime, ordered by
+	// tree ID. Soft-deleted trees are only included if includeDeleted is
+	// true.
+	ListUntouchedTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error)
+
+	// ExistingTreeIDs reports, for each of candidateIDs, whether a tree with
+	// that ID exists. The returned map has an entry for every candidate.
+	// Soft-deleted trees are only reported as existing if includeDeleted is
+	// true.
+	ExistingTreeIDs(ctx context.Context, candidateIDs []int64, includeDeleted bool) (map[int64]bool, error)
+
+	// CountTreesByLabel returns, for every label carried by at least one
+	// tree, the number of trees carrying it. Soft-deleted trees are only
+	// counted if includeDeleted is true.
+	CountTreesByLabel(ctx context.Context, includeDeleted bool) (map[string]int64, error)
+
+	// ListTreeRevisions returns the current revision (see TreeRevision) of
+	// every tree, keyed by tree ID. Soft-deleted trees are only included if
+	// includeDeleted is true.
+	ListTreeRevisions(ctx context.Context, includeDeleted bool) (map[int64]int64, error)
+
+	// StreamTreeConfigHashes streams the ID and TreeConfigHash of every
+	// tree, ordered by tree ID. Soft-deleted trees are only included if
+	// includeDeleted is true.
+	// The hash channel is closed once all hashes are sent, or if an error
+	// occurs, after which at most one error is sent on the error channel.
+	// If ctx is cancelled or expires the stream stops, and a Canceled or
+	// DeadlineExceeded error is sent.
+	// Implementations may read all hashes before sending the first one (see
+	// StreamTreeHashes), so memory use grows with the number of trees.
+	StreamTreeConfigHashes(ctx context.Context, includeDeleted bool) (<-chan TreeHash, <-chan error)
+
+	// GetTreeByExternalRef returns the tree whose ExternalRef is ref.
+	// Soft-deleted trees are only considered if includeDeleted is true.
+	// Returns an InvalidArgument error if ref is empty, a NotFound error if
+	// no tree matches, and a FailedPrecondition error if more than one does.
+	GetTreeByExternalRef(ctx context.Context, ref string, includeDeleted bool) (*trillian.Tree, error)
 }
 
 // AdminWriter provides a write-only interface for tree data.
 type AdminWriter interface {
 	// CreateTree inserts the specified tree in storage, returning a tree
 	// with all storage-generated fields set.
-	// Note that treeID and timestamps will be automatically generated by
-	// the storage layer, thus may be ignored by the implementation.
+	// Note that timestamps will be automatically generated by the storage
+	// layer, thus may be ignored by the implementation.
+	// If tree.TreeId was reserved via ReserveTreeID and the reservation is
+	// still live, the tree is created with that ID and the reservation is
+	// consumed. Otherwise tree.TreeId is ignored and a new ID is generated,
+	// so callers relying on a reservation should check the returned TreeId.
 	// Remaining fields must be set to valid values.
 	// Returns an error if the tree is invalid or creation fails.
 	CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error)
 
+	// CreateTreeWithAlias creates tree, as per CreateTree, and points alias
+	// to it, as per SetTreeAlias, in the same transaction.
+	// Returns an AlreadyExists error if alias is already in use, in which
+	// case no tree is created.
+	CreateTreeWithAlias(ctx context.Context, tree *trillian.Tree, alias string) (*trillian.Tree, error)
+
+	// ReserveTreeID allocates a new tree ID without creating a tree.
+	// Generated IDs are never assigned to reserved IDs. The reservation
+	// lasts for AdminStorageOptions.TreeIDReservationTTL, during which the
+	// ID may be used by CreateTree.
+	ReserveTreeID(ctx context.Context) (int64, error)
+
+	// ImportTree inserts the specified tree in storage as-is, preserving its
+	// treeID, timestamps and deletion status, as exported from another
+	// storage.
+	// Returns an AlreadyExists error if a tree with the same ID exists,
+	// or an error if the tree is invalid or insertion fails.
+	ImportTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error)
+
 	// UpdateTree updates the specified tree in storage, returning a tree
 	// with all storage-generated fields set.
 	// updateFunc is called to perform the desired tree modifications. Refer
 	// to trillian.Tree for details on which fields are mutable and what is
 	// considered valid.
+	// If updateFunc doesn't modify the tree, UpdateTime is left unchanged,
+	// unless AdminStorageOptions.AdvanceUpdateTimeOnNoop is set. Otherwise
+	// UpdateTime is set to the current time. updateFunc should replace
+	// nested messages rather than modify them in place.
 	// Returns an error if the tree is invalid or the update cannot be
 	// performed.
 	UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error)
 
+	// UpdateTrees updates multiple trees in storage, as per UpdateTree,
+	// returning the updated trees ordered by ID.
+	// updates maps tree IDs to the updateFunc to be called on each tree.
+	// Updates are all-or-nothing: every tree is validated before any is
+	// written, and if any update fails no tree is modified. In that case an
+	// errors.MultiError is returned, containing one error per failing tree.
+	UpdateTrees(ctx context.Context, updates map[int64]func(*trillian.Tree)) ([]*trillian.Tree, error)
+
+	// TouchTrees sets the UpdateTime of every tree in treeIDs to the same
+	// current time, without changing their config, and returns the touched
+	// trees ordered by ID.
+	// Touches are all-or-nothing: if any tree doesn't exist a NotFound error
+	// naming it is returned and no tree is modified.
+	TouchTrees(ctx context.Context, treeIDs []int64) ([]*trillian.Tree, error)
+
+	// ReplaceTree replaces the mutable fields of the stored tree with those
+	// of desired, as a single compare-and-swap update.
+	// desired must be a complete tree, usually obtained from GetTree and
+	// then modified, and is validated against the stored tree as per
+	// UpdateTree. The update is only applied if the stored tree's revision
+	// (see TreeRevision) is expectedRevision, otherwise a FailedPrecondition
+	// error is returned and the tree isn't modified.
+	ReplaceTree(ctx context.Context, desired *trillian.Tree, expectedRevision int64) (*trillian.Tree, error)
+
+	// SetTreeLabels replaces the labels of the specified tree, returning the
+	// updated tree.
+	// The previous labels are discarded. labels must be valid as per
+	// trillian.Tree, otherwise an error is returned and the tree isn't
+	// modified.
+	SetTreeLabels(ctx context.Context, treeID int64, labels []string) (*trillian.Tree, error)
+
+	// TransferLabels moves labels from fromTreeID to toTreeID, as a single
+	// update of both trees (see UpdateTrees). Labels already present on
+	// toTreeID are kept as-is.
+	// Returns an InvalidArgument error if fromTreeID doesn't have all labels
+	// or both IDs are the same, and NotFound if either tree doesn't exist.
+	// Neither tree is modified on error.
+	TransferLabels(ctx context.Context, fromTreeID, toTreeID int64, labels []string) error
+
+	// SetTreeAlias points alias to the specified tree, replacing any
+	// previous mapping of alias. Each alias maps to exactly one tree, but a
+	// tree may have any number of aliases.
+	// alias must be non-empty and the tree must exist, otherwise an error is
+	// returned.
+	SetTreeAlias(ctx context.Context, alias string, treeID int64) error
+
+	// WriteTreeCountSnapshot records snapshot, to be later read via
+	// ListTreeCountSnapshots. See RecordTreeCountSnapshot.
+	// Snapshot times are stored with millisecond precision; a snapshot
+	// taken in the same millisecond as a previous one replaces it.
+	WriteTreeCountSnapshot(ctx context.Context, snapshot *CountSnapshot) error
+
+	// RecordSequencingProgress sets the LastSequencedTime of the specified
+	// tree to when, meant to be called by the sequencer after integrating
+	// leaves. Other fields, including UpdateTime, are left unchanged.
+	// Returns a NotFound error if the tree doesn't exist.
+	RecordSequencingProgress(ctx context.Context, treeID int64, when *timestamp.Timestamp) error
+
+	// PauseSequencing sets SequencingPaused on the specified tree, removing
+	// it from ListSequenceableTreeIDs. The tree keeps accepting queued
+	// leaves, to be integrated once sequencing resumes. Pausing a paused tree
+	// is a no-op. Other fields, including UpdateTime, are left unchanged.
+	// Returns a NotFound error if the tree doesn't exist.
+	PauseSequencing(ctx context.Context, treeID int64) (*trillian.Tree, error)
+
+	// ResumeSequencing clears SequencingPaused on the specified tree, undoing
+	// PauseSequencing. Resuming a tree that isn't paused is a no-op.
+	// Returns a NotFound error if the tree doesn't exist.
+	ResumeSequencing(ctx context.Context, treeID int64) (*trillian.Tree, error)
+
 	// SoftDeleteTree soft deletes the specified tree.
 	// The tree must exist and not be already soft deleted, otherwise an error is returned.
 	// Soft deletion may be undone via UndeleteTree.
 	SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error)
 
+	// SoftDeleteTreeIfUnchanged soft deletes the specified tree, as per
+	// SoftDeleteTree, only if its revision (see TreeRevision) is
+	// expectedRevision. Otherwise a FailedPrecondition error is returned and
+	// the tree isn't deleted.
+	SoftDeleteTreeIfUnchanged(ctx context.Context, treeID int64, expectedRevision int64) (*trillian.Tree, error)
+
 	// HardDeleteTree hard deletes (i.e. completely removes from storage) the specified tree and all
 	// records related to it.
 	// The tree must exist and currently be soft deleted, as per SoftDeletedTree, otherwise an error
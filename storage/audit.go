@@ -0,0 +1,66 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+)
+
+// AuditStoredTrees checks all trees in adminStorage, including soft-deleted
+// ones, against the creation rules configured by opts, as per
+// ValidateTreeForCreation.
+// It's meant to find existing trees that would be rejected after validation
+// rules change.
+// Storage-managed fields (tree_id, timestamps and deletion status) are not
+// checked, and tree_state may be any known state, as trees may legitimately
+// leave ACTIVE after creation. Labels with ReservedLabelPrefix are allowed,
+// as they're set by internal code.
+// Returns a map of non-conforming tree IDs to their validation error.
+func AuditStoredTrees(ctx context.Context, adminStorage AdminStorage, opts AdminStorageOptions) (map[int64]error, error) {
+	// Creation rules require a private key.
+	ctx = WithPrivateKeyAccess(ctx)
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, true /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	validationCtx := WithReservedLabels(ctx)
+	failed := make(map[int64]error)
+	for _, tree := range trees {
+		audited := *tree
+		audited.TreeId = 0
+		audited.CreateTime = nil
+		audited.UpdateTime = nil
+		audited.Deleted = false
+		audited.DeleteTime = nil
+		if audited.TreeState != trillian.TreeState_UNKNOWN_TREE_STATE {
+			audited.TreeState = trillian.TreeState_ACTIVE
+		}
+		if err := ValidateTreeForCreation(validationCtx, opts, &audited); err != nil {
+			failed[tree.TreeId] = err
+		}
+	}
+	return failed, nil
+}
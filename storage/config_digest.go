@@ -0,0 +1,61 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"sort"
+)
+
+// StoreConfigDigest returns a SHA-256 digest of the config of every tree in
+// adminStorage, so that replicas can compare their configs by a single hash.
+// The digest is computed over each tree's ID and TreeConfigHash, ordered by
+// ID, thus it changes if any tree is added, removed or has its config
+// modified. Soft-deleted trees are only included if includeDeleted is true.
+// Digests embed TreeConfigHashVersion, so replicas running different
+// versions never report matching digests.
+func StoreConfigDigest(ctx context.Context, adminStorage AdminStorage, includeDeleted bool) ([32]byte, error) {
+	// TreeConfigHash covers private keys, so the digest mustn't depend on
+	// the caller's access.
+	ctx = WithPrivateKeyAccess(ctx)
+	var digest [32]byte
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return digest, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, includeDeleted)
+	if err != nil {
+		return digest, err
+	}
+	if err := tx.Commit(); err != nil {
+		return digest, err
+	}
+
+	sort.Slice(trees, func(i, j int) bool { return trees[i].TreeId < trees[j].TreeId })
+	h := sha256.New()
+	h.Write(uint32Bytes(uint32(TreeConfigHashVersion)))
+	for _, tree := range trees {
+		configHash, err := TreeConfigHash(tree)
+		if err != nil {
+			return digest, err
+		}
+		h.Write(int64Bytes(tree.TreeId))
+		h.Write(configHash)
+	}
+	copy(digest[:], h.Sum(nil))
+	return digest, nil
+}
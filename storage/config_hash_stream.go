@@ -0,0 +1,62 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+)
+
+// TreeHash is the config hash of a tree, as per TreeConfigHash.
+type TreeHash struct {
+	TreeID     int64
+	ConfigHash []byte
+}
+
+// StreamTreeHashes calls read and streams the returned TreeHashes, as per
+// AdminReader.StreamTreeConfigHashes.
+// read is called before StreamTreeHashes returns, so it may use the calling
+// transaction. All hashes returned by it are held in memory, and then
+// streamed from a separate goroutine until all are received or ctx is done.
+// It's meant to be used by AdminStorage implementations of
+// StreamTreeConfigHashes.
+func StreamTreeHashes(ctx context.Context, read func() ([]TreeHash, error)) (<-chan TreeHash, <-chan error) {
+	hashc := make(chan TreeHash)
+	errc := make(chan error, 1)
+	hashes, err := read()
+	if err != nil {
+		errc <- err
+		close(hashc)
+		close(errc)
+		return hashc, errc
+	}
+	go func() {
+		defer close(errc)
+		defer close(hashc)
+		for _, hash := range hashes {
+			// Check ctx first, as select picks randomly between ready cases.
+			if err := contextError(ctx); err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case hashc <- hash:
+			case <-ctx.Done():
+				errc <- contextError(ctx)
+				return
+			}
+		}
+	}()
+	return hashc, errc
+}
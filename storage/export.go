@@ -0,0 +1,127 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// ExportTreesSince writes all trees in adminStorage whose UpdateTime or
+// DeleteTime is at or after since to w, ordered by tree ID, and returns the
+// number of exported trees. A nil since exports all trees.
+// Soft-deleted trees are exported as-is, so trees deleted after since are
+// present in the export as tombstones. Hard-deleted trees leave no trace in
+// storage and can't be exported.
+// Trees are written as length-delimited Tree protos, see ReadExportedTrees.
+func ExportTreesSince(ctx context.Context, adminStorage AdminStorage, w io.Writer, since *timestamp.Timestamp) (int, error) {
+	var sinceTime time.Time
+	if since != nil {
+		var err error
+		sinceTime, err = ptypes.Timestamp(since)
+		if err != nil {
+			return 0, errors.Errorf(errors.InvalidArgument, "invalid since timestamp: %v", err)
+		}
+	}
+
+	// Exports are backups, so they must include private keys.
+	ctx = WithPrivateKeyAccess(ctx)
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, true /* includeDeleted */)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	sort.Slice(trees, func(i, j int) bool { return trees[i].TreeId < trees[j].TreeId })
+
+	count := 0
+	for _, tree := range trees {
+		if since != nil {
+			changed, err := changedSince(tree, sinceTime)
+			if err != nil {
+				return 0, err
+			}
+			if !changed {
+				continue
+			}
+		}
+		treeBytes, err := proto.Marshal(tree)
+		if err != nil {
+			return 0, fmt.Errorf("error marshaling tree %v: %v", tree.TreeId, err)
+		}
+		if _, err := w.Write(append(proto.EncodeVarint(uint64(len(treeBytes))), treeBytes...)); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ReadExportedTrees reads all trees written by ExportTreesSince from r.
+func ReadExportedTrees(r io.Reader) ([]*trillian.Tree, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var trees []*trillian.Tree
+	for len(data) > 0 {
+		size, n := proto.DecodeVarint(data)
+		if n == 0 || size > uint64(len(data)-n) {
+			return nil, errors.Errorf(errors.InvalidArgument, "truncated export at tree %v", len(trees))
+		}
+		tree := &trillian.Tree{}
+		if err := proto.Unmarshal(data[n:n+int(size)], tree); err != nil {
+			return nil, errors.Errorf(errors.InvalidArgument, "error unmarshaling tree %v of export: %v", len(trees), err)
+		}
+		trees = append(trees, tree)
+		data = data[n+int(size):]
+	}
+	return trees, nil
+}
+
+// changedSince returns whether tree was updated or deleted at or after since.
+func changedSince(tree *trillian.Tree, since time.Time) (bool, error) {
+	updateTime, err := ptypes.Timestamp(tree.UpdateTime)
+	if err != nil {
+		return false, fmt.Errorf("error parsing update_time of tree %v: %v", tree.TreeId, err)
+	}
+	if !updateTime.Before(since) {
+		return true, nil
+	}
+	if !tree.Deleted || tree.DeleteTime == nil {
+		return false, nil
+	}
+	deleteTime, err := ptypes.Timestamp(tree.DeleteTime)
+	if err != nil {
+		return false, fmt.Errorf("error parsing delete_time of tree %v: %v", tree.TreeId, err)
+	}
+	return !deleteTime.Before(since), nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// FieldPolicy decides which tree fields callers may edit.
+type FieldPolicy interface {
+	// CanEdit returns whether the caller identified by ctx may modify field,
+	// named as per FieldChange.Field (e.g. "max_root_duration").
+	CanEdit(ctx context.Context, field string) bool
+}
+
+// WithFieldPolicy returns an AdminStorage that enforces policy on tree
+// updates made through s.
+// UpdateTree, UpdateTrees and ReplaceTree fail with a PermissionDenied error
+// if the update changes a field policy doesn't allow editing, as per
+// TreeConfigDiff. SetTreeLabels and TransferLabels are subject to the
+// "labels" field. Other operations are passed through.
+func WithFieldPolicy(s AdminStorage, policy FieldPolicy) AdminStorage {
+	return &fieldPolicyStorage{AdminStorage: s, policy: policy}
+}
+
+type fieldPolicyStorage struct {
+	AdminStorage
+	policy FieldPolicy
+}
+
+func (s *fieldPolicyStorage) Begin(ctx context.Context) (AdminTX, error) {
+	tx, err := s.AdminStorage.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fieldPolicyTX{AdminTX: tx, policy: s.policy}, nil
+}
+
+type fieldPolicyTX struct {
+	AdminTX
+	policy FieldPolicy
+}
+
+// checkFields returns a PermissionDenied error if any field edited between
+// before and after isn't allowed by the policy.
+func (t *fieldPolicyTX) checkFields(ctx context.Context, before, after *trillian.Tree) error {
+	for _, change := range TreeConfigDiff(before, after) {
+		if err := t.checkField(ctx, change.Field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *fieldPolicyTX) checkField(ctx context.Context, field string) error {
+	if !t.policy.CanEdit(ctx, field) {
+		return errors.Errorf(errors.PermissionDenied, "not allowed to edit field %v", field)
+	}
+	return nil
+}
+
+// policyUpdateFunc wraps updateFunc so that disallowed edits are reverted and
+// reported via the returned error pointer.
+func (t *fieldPolicyTX) policyUpdateFunc(ctx context.Context, updateFunc func(*trillian.Tree)) (func(*trillian.Tree), *error) {
+	var policyErr error
+	return func(tree *trillian.Tree) {
+		before := proto.Clone(tree).(*trillian.Tree)
+		updateFunc(tree)
+		if err := t.checkFields(ctx, before, tree); err != nil {
+			policyErr = err
+			*tree = *before
+		}
+	}, &policyErr
+}
+
+func (t *fieldPolicyTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	wrapped, policyErr := t.policyUpdateFunc(ctx, updateFunc)
+	tree, err := t.AdminTX.UpdateTree(ctx, treeID, wrapped)
+	if *policyErr != nil {
+		return nil, *policyErr
+	}
+	return tree, err
+}
+
+func (t *fieldPolicyTX) UpdateTrees(ctx context.Context, updates map[int64]func(*trillian.Tree)) ([]*trillian.Tree, error) {
+	var errs errors.MultiError
+	policyErrs := make(map[int64]*error)
+	wrapped := make(map[int64]func(*trillian.Tree))
+	for id, updateFunc := range updates {
+		wrapped[id], policyErrs[id] = t.policyUpdateFunc(ctx, updateFunc)
+	}
+	trees, err := t.AdminTX.UpdateTrees(ctx, wrapped)
+	for id, policyErr := range policyErrs {
+		if *policyErr != nil {
+			errs = append(errs, errors.Errorf(errors.PermissionDenied, "tree %v: %v", id, *policyErr))
+		}
+	}
+	if policyErr := errs.ErrorOrNil(); policyErr != nil {
+		return nil, policyErr
+	}
+	return trees, err
+}
+
+func (t *fieldPolicyTX) ReplaceTree(ctx context.Context, desired *trillian.Tree, expectedRevision int64) (*trillian.Tree, error) {
+	storedTree, err := t.AdminTX.GetTree(ctx, desired.TreeId)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkFields(ctx, storedTree, desired); err != nil {
+		return nil, err
+	}
+	return t.AdminTX.ReplaceTree(ctx, desired, expectedRevision)
+}
+
+func (t *fieldPolicyTX) SetTreeLabels(ctx context.Context, treeID int64, labels []string) (*trillian.Tree, error) {
+	if err := t.checkField(ctx, "labels"); err != nil {
+		return nil, err
+	}
+	return t.AdminTX.SetTreeLabels(ctx, treeID, labels)
+}
+
+func (t *fieldPolicyTX) TransferLabels(ctx context.Context, fromTreeID, toTreeID int64, labels []string) error {
+	if err := t.checkField(ctx, "labels"); err != nil {
+		return err
+	}
+	return t.AdminTX.TransferLabels(ctx, fromTreeID, toTreeID, labels)
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+)
+
+// DistinctHashStrategies returns the number of trees in adminStorage using
+// each hash strategy. Strategies not used by any tree are omitted.
+// Soft-deleted trees are only counted if includeDeleted is true.
+// It's meant to find which trees are affected before deprecating a hasher.
+func DistinctHashStrategies(ctx context.Context, adminStorage AdminStorage, includeDeleted bool) (map[trillian.HashStrategy]int64, error) {
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[trillian.HashStrategy]int64)
+	for _, tree := range trees {
+		counts[tree.HashStrategy]++
+	}
+	return counts, nil
+}
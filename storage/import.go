@@ -0,0 +1,136 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// CollisionPolicy determines what ImportTrees does when an imported tree's ID
+// is already in use in the target storage.
+type CollisionPolicy int
+
+const (
+	// OnCollisionFail aborts the import, without importing any trees.
+	OnCollisionFail CollisionPolicy = iota
+
+	// OnCollisionSkip leaves the existing tree untouched and doesn't import
+	// the colliding one.
+	OnCollisionSkip
+
+	// OnCollisionRenumber imports the colliding tree under a freshly
+	// generated ID.
+	OnCollisionRenumber
+)
+
+// ImportOptions are the options for ImportTrees.
+type ImportOptions struct {
+	// OnCollision is the policy applied to trees whose ID already exists in
+	// the target storage. Defaults to OnCollisionFail.
+	OnCollision CollisionPolicy
+}
+
+// ImportResult describes the outcome of ImportTrees.
+type ImportResult struct {
+	// Imported contains the IDs of all imported trees, as stored in the
+	// target storage.
+	Imported []int64
+
+	// Skipped contains the IDs of trees not imported due to a collision.
+	Skipped []int64
+
+	// Renumbered maps the original IDs of renumbered trees to their new IDs.
+	Renumbered map[int64]int64
+}
+
+// ImportTrees imports trees into adminStorage, as per AdminTX.ImportTree,
+// resolving ID collisions according to opts.OnCollision.
+// All trees are imported in a single transaction: if any tree fails to import
+// no trees are imported and an error is returned.
+// If ctx is cancelled or expires the import is aborted, without importing any
+// trees, and a Canceled or DeadlineExceeded error is returned.
+func ImportTrees(ctx context.Context, adminStorage AdminStorage, trees []*trillian.Tree, opts ImportOptions) (*ImportResult, error) {
+	tx, err := adminStorage.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	result, err := importTrees(ctx, tx, trees, opts)
+	// Context errors take precedence, as storage may report them arbitrarily.
+	if ctxErr := contextError(ctx); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func importTrees(ctx context.Context, tx AdminTX, trees []*trillian.Tree, opts ImportOptions) (*ImportResult, error) {
+	result := &ImportResult{Renumbered: make(map[int64]int64)}
+	for _, tree := range trees {
+		if err := contextError(ctx); err != nil {
+			return nil, err
+		}
+
+		switch _, err := tx.GetTree(ctx, tree.TreeId); {
+		case errors.ErrorCode(err) == errors.NotFound:
+			// ID is free, import as-is.
+		case err != nil:
+			return nil, err
+		case opts.OnCollision == OnCollisionSkip:
+			result.Skipped = append(result.Skipped, tree.TreeId)
+			continue
+		case opts.OnCollision == OnCollisionRenumber:
+			id, err := NewTreeID()
+			if err != nil {
+				return nil, err
+			}
+			result.Renumbered[tree.TreeId] = id
+			renumbered := *tree
+			renumbered.TreeId = id
+			tree = &renumbered
+		default:
+			return nil, errors.Errorf(errors.AlreadyExists, "tree %v already exists", tree.TreeId)
+		}
+
+		imported, err := tx.ImportTree(ctx, tree)
+		if err != nil {
+			return nil, err
+		}
+		result.Imported = append(result.Imported, imported.TreeId)
+	}
+	return result, nil
+}
+
+// contextError returns a Canceled or DeadlineExceeded error if ctx is done,
+// nil otherwise.
+func contextError(ctx context.Context) error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		return errors.New(errors.DeadlineExceeded, "context deadline exceeded")
+	default:
+		return errors.New(errors.Canceled, "context canceled")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	gocrypto "crypto"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/errors"
+	"github.com/google/trillian/merkle/hashers"
+)
+
+// CreateLogTreeWithInitialRoot creates the LOG tree in adminS and stores its
+// first, size-0 root in logS, signed by signer with the tree's hash algorithm
+// and signature params.
+//
+// Admin and log storage don't share transactions, so the tree is created and
+// committed before its root is stored. If storing the root fails the tree is
+// soft-deleted on a best-effort basis and an error is returned; a successfully
+// returned tree always has a signed root.
+// signer must match the tree's public key, otherwise an InvalidArgument error
+// is returned and no tree is created.
+func CreateLogTreeWithInitialRoot(ctx context.Context, adminS AdminStorage, logS LogStorage, tree *trillian.Tree, signer gocrypto.Signer) (*trillian.Tree, error) {
+	if tree.GetTreeType() != trillian.TreeType_LOG {
+		return nil, errors.Errorf(errors.InvalidArgument, "tree_type must be LOG, got %s", tree.GetTreeType())
+	}
+	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		return nil, errors.Errorf(errors.InvalidArgument, "invalid hash_strategy: %v", err)
+	}
+	signerDER, err := der.MarshalPublicKey(signer.Public())
+	if err != nil {
+		return nil, errors.Errorf(errors.InvalidArgument, "error marshaling signer public key: %v", err)
+	}
+	if !bytes.Equal(signerDER, tree.GetPublicKey().GetDer()) {
+		return nil, errors.New(errors.InvalidArgument, "signer doesn't match tree public_key")
+	}
+	rootSigner, err := crypto.NewSignerForTree(tree, signer)
+	if err != nil {
+		return nil, errors.Errorf(errors.InvalidArgument, "invalid signature params: %v", err)
+	}
+
+	tx, err := adminS.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	newTree, err := tx.CreateTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	root := trillian.SignedLogRoot{
+		LogId:          newTree.TreeId,
+		RootHash:       hasher.EmptyRoot(),
+		TimestampNanos: time.Now().UnixNano(),
+	}
+	if err := storeInitialRoot(ctx, logS, root, rootSigner); err != nil {
+		if deleteErr := softDeleteTree(ctx, adminS, newTree.TreeId); deleteErr != nil {
+			glog.Warningf("%v: failed to delete tree without initial root: %v", newTree.TreeId, deleteErr)
+		}
+		return nil, err
+	}
+	return newTree, nil
+}
+
+// storeInitialRoot signs root and stores it in its own transaction.
+func storeInitialRoot(ctx context.Context, logS LogStorage, root trillian.SignedLogRoot, signer *crypto.Signer) error {
+	hash, err := crypto.HashLogRoot(root)
+	if err != nil {
+		return err
+	}
+	if root.Signature, err = signer.Sign(hash); err != nil {
+		return err
+	}
+
+	tx, err := logS.BeginForTree(ctx, root.LogId)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := tx.StoreSignedLogRoot(ctx, root); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// softDeleteTree soft-deletes treeID in its own transaction.
+func softDeleteTree(ctx context.Context, adminS AdminStorage, treeID int64) error {
+	tx, err := adminS.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if _, err := tx.SoftDeleteTree(ctx, treeID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
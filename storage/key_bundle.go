@@ -0,0 +1,59 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/trillian/crypto/keyspb"
+)
+
+// TreeKeyEntry is the public, trust bundle-suitable view of a tree's keys.
+// It never contains private key material.
+type TreeKeyEntry struct {
+	TreeID      int64
+	DisplayName string
+	PublicKey   *keyspb.PublicKey
+}
+
+// ExportPublicKeyBundle returns the public keys of all trees in adminStorage,
+// ordered by tree ID. Soft-deleted trees are only included if includeDeleted
+// is true.
+func ExportPublicKeyBundle(ctx context.Context, adminStorage AdminStorage, includeDeleted bool) ([]TreeKeyEntry, error) {
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	bundle := make([]TreeKeyEntry, 0, len(trees))
+	for _, tree := range trees {
+		bundle = append(bundle, TreeKeyEntry{
+			TreeID:      tree.TreeId,
+			DisplayName: tree.DisplayName,
+			PublicKey:   tree.PublicKey,
+		})
+	}
+	sort.Slice(bundle, func(i, j int) bool { return bundle[i].TreeID < bundle[j].TreeID })
+	return bundle, nil
+}
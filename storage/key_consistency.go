@@ -0,0 +1,68 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/errors"
+)
+
+// AuditKeyConsistency checks that the stored public key of every tree in
+// adminStorage, including soft-deleted ones, matches the public key derived
+// from its private key. Signers are obtained by factory, usually
+// keys.NewSigner.
+// Trees whose private key can't be loaded are skipped, as there's no key to
+// compare against.
+// Returns a map of mismatching tree IDs to a DataLoss error.
+func AuditKeyConsistency(ctx context.Context, adminStorage AdminStorage, factory keys.ProtoHandler) (map[int64]error, error) {
+	ctx = WithPrivateKeyAccess(ctx)
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, true /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	mismatched := make(map[int64]error)
+	for _, tree := range trees {
+		var privateKey ptypes.DynamicAny
+		if err := ptypes.UnmarshalAny(tree.PrivateKey, &privateKey); err != nil {
+			continue
+		}
+		signer, err := factory(ctx, privateKey.Message)
+		if err != nil {
+			continue
+		}
+		publicKeyDER, err := der.MarshalPublicKey(signer.Public())
+		if err != nil {
+			continue
+		}
+		if !bytes.Equal(publicKeyDER, tree.GetPublicKey().GetDer()) {
+			mismatched[tree.TreeId] = errors.Errorf(errors.DataLoss, "tree %v: public_key doesn't match the key derived from private_key", tree.TreeId)
+		}
+	}
+	return mismatched, nil
+}
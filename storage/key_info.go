@@ -0,0 +1,65 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/errors"
+)
+
+// KeyInfo describes a tree's signing key, as derived from its public key.
+// It never contains private key material.
+type KeyInfo struct {
+	// SignatureAlgorithm is the algorithm of the key.
+	SignatureAlgorithm sigpb.DigitallySigned_SignatureAlgorithm
+	// Curve is the name of the elliptic curve of ECDSA keys (e.g. "P-256").
+	// Empty for other algorithms.
+	Curve string
+	// KeySizeBits is the size of the key, i.e., the curve size for ECDSA keys
+	// and the modulus size for RSA keys.
+	KeySizeBits int
+	// Fingerprint is the SHA-256 hash of the DER-encoded public key.
+	Fingerprint []byte
+}
+
+// NewKeyInfo returns the KeyInfo of publicKey.
+// Returns an InvalidArgument error if publicKey can't be parsed or is of an
+// unsupported type.
+func NewKeyInfo(publicKey *keyspb.PublicKey) (*KeyInfo, error) {
+	key, err := der.UnmarshalPublicKey(publicKey.GetDer())
+	if err != nil {
+		return nil, errors.Errorf(errors.InvalidArgument, "invalid public_key: %v", err)
+	}
+	fingerprint := sha256.Sum256(publicKey.GetDer())
+	info := &KeyInfo{Fingerprint: fingerprint[:]}
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		info.SignatureAlgorithm = sigpb.DigitallySigned_ECDSA
+		info.Curve = key.Curve.Params().Name
+		info.KeySizeBits = key.Curve.Params().BitSize
+	case *rsa.PublicKey:
+		info.SignatureAlgorithm = sigpb.DigitallySigned_RSA
+		info.KeySizeBits = key.N.BitLen()
+	default:
+		return nil, errors.Errorf(errors.InvalidArgument, "unsupported public_key type: %T", key)
+	}
+	return info, nil
+}
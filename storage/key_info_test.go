@@ -0,0 +1,108 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/errors"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestNewKeyInfo(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() = (_, %v), want = (_, nil)", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() = (_, %v), want = (_, nil)", err)
+	}
+
+	// Trees are verify-only, i.e., have no private key, as KeyInfo only
+	// depends on the public key.
+	verifyOnlyTree := func(key crypto.PublicKey) *trillian.Tree {
+		publicKey, err := der.ToPublicProto(key)
+		if err != nil {
+			t.Fatalf("ToPublicProto() = (_, %v), want = (_, nil)", err)
+		}
+		return &trillian.Tree{PublicKey: publicKey}
+	}
+	fingerprint := func(tree *trillian.Tree) []byte {
+		hash := sha256.Sum256(tree.PublicKey.GetDer())
+		return hash[:]
+	}
+	ecdsaTree := verifyOnlyTree(ecdsaKey.Public())
+	rsaTree := verifyOnlyTree(rsaKey.Public())
+
+	tests := []struct {
+		desc     string
+		tree     *trillian.Tree
+		want     *KeyInfo
+		wantCode errors.Code
+	}{
+		{
+			desc: "ecdsa",
+			tree: ecdsaTree,
+			want: &KeyInfo{
+				SignatureAlgorithm: sigpb.DigitallySigned_ECDSA,
+				Curve:              "P-384",
+				KeySizeBits:        384,
+				Fingerprint:        fingerprint(ecdsaTree),
+			},
+		},
+		{
+			desc: "rsa",
+			tree: rsaTree,
+			want: &KeyInfo{
+				SignatureAlgorithm: sigpb.DigitallySigned_RSA,
+				KeySizeBits:        2048,
+				Fingerprint:        fingerprint(rsaTree),
+			},
+		},
+		{
+			desc:     "invalidPublicKey",
+			tree:     &trillian.Tree{PublicKey: &keyspb.PublicKey{Der: []byte("foobar")}},
+			wantCode: errors.InvalidArgument,
+		},
+		{
+			desc:     "nilPublicKey",
+			tree:     &trillian.Tree{},
+			wantCode: errors.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		got, err := NewKeyInfo(test.tree.PublicKey)
+		if errors.ErrorCode(err) != test.wantCode {
+			t.Errorf("%v: NewKeyInfo() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if diff := pretty.Compare(got, test.want); diff != "" {
+			t.Errorf("%v: NewKeyInfo() diff (-got +want):\n%v", test.desc, diff)
+		}
+	}
+}
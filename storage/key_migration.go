@@ -0,0 +1,61 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// MigrateKeyHandler replaces the private key of the specified tree with
+// newPriv, a reference to the same logical key held by a different key
+// handler (e.g. moving an inline keyspb.PrivateKey to a PKCS#11 handle).
+// newPriv must be of a different type than the current private key, and the
+// signer it resolves to must match the tree's public key, otherwise an
+// InvalidArgument error is returned and the tree isn't modified.
+func MigrateKeyHandler(ctx context.Context, adminStorage AdminStorage, treeID int64, newPriv *any.Any) (*trillian.Tree, error) {
+	tx, err := adminStorage.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	// The current private key is needed to compare handlers, but the
+	// returned tree is redacted as per the caller's access.
+	tree, err := tx.GetTree(WithPrivateKeyAccess(ctx), treeID)
+	if err != nil {
+		return nil, err
+	}
+	if newPriv.GetTypeUrl() == tree.PrivateKey.GetTypeUrl() {
+		return nil, errors.Errorf(errors.InvalidArgument, "tree %v: new private_key has the same type as the current one: %q", treeID, newPriv.GetTypeUrl())
+	}
+	if err := validatePrivateKey(ctx, newPriv, tree.PublicKey); err != nil {
+		return nil, errors.Errorf(errors.InvalidArgument, "tree %v: %v", treeID, err)
+	}
+
+	tree, err = tx.UpdateTree(ctx, treeID, func(tree *trillian.Tree) {
+		tree.PrivateKey = newPriv
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+)
+
+// LabelDeriver returns labels derived from a tree's config (e.g. parsed from
+// its display name), to be added to the tree on creation.
+type LabelDeriver func(tree *trillian.Tree) []string
+
+var (
+	labelDeriversMu sync.RWMutex
+	labelDerivers   = make(map[string]LabelDeriver)
+)
+
+// RegisterLabelDeriver registers a LabelDeriver under name, to be invoked on
+// every tree passed to AdminWriter.CreateTree.
+// If a deriver with the same name has already been registered, it will be
+// replaced.
+func RegisterLabelDeriver(name string, deriver LabelDeriver) {
+	labelDeriversMu.Lock()
+	defer labelDeriversMu.Unlock()
+	if _, alreadyExists := labelDerivers[name]; alreadyExists {
+		glog.Warningf("Overriding LabelDeriver %q", name)
+	}
+	labelDerivers[name] = deriver
+}
+
+// UnregisterLabelDeriver removes a previously-registered LabelDeriver.
+// See RegisterLabelDeriver().
+func UnregisterLabelDeriver(name string) {
+	labelDeriversMu.Lock()
+	defer labelDeriversMu.Unlock()
+	delete(labelDerivers, name)
+}
+
+// DeriveLabels returns a copy of tree with the labels of all registered
+// LabelDerivers appended, in name order. Labels already present are not
+// added again. If no derivers are registered tree is returned as-is.
+// It's meant to be called by AdminStorage implementations before validating
+// trees for creation, so derived labels are validated like any others.
+func DeriveLabels(tree *trillian.Tree) *trillian.Tree {
+	labelDeriversMu.RLock()
+	defer labelDeriversMu.RUnlock()
+	if tree == nil || len(labelDerivers) == 0 {
+		return tree
+	}
+	names := make([]string, 0, len(labelDerivers))
+	for name := range labelDerivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	derived := proto.Clone(tree).(*trillian.Tree)
+	seen := make(map[string]bool)
+	for _, label := range derived.Labels {
+		seen[label] = true
+	}
+	for _, name := range names {
+		for _, label := range labelDerivers[name](tree) {
+			if !seen[label] {
+				seen[label] = true
+				derived.Labels = append(derived.Labels, label)
+			}
+		}
+	}
+	return derived
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+)
+
+// GetTreeAndLatestRoot returns the LOG tree identified by treeID and its
+// latest signed root. If the log has no roots yet, a nil root is returned.
+//
+// Admin and log storage don't share transactions, so the tree and its root
+// are read in back-to-back snapshots, one from each storage.
+func GetTreeAndLatestRoot(ctx context.Context, adminS AdminStorage, logS LogStorage, treeID int64) (*trillian.Tree, *trillian.SignedLogRoot, error) {
+	tx, err := adminS.Snapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Close()
+	tree, err := tx.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	root, err := latestSignedLogRoot(ctx, logS, treeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if root.TimestampNanos == 0 {
+		// No roots yet
+		return tree, nil, nil
+	}
+	return tree, &root, nil
+}
+
+// latestSignedLogRoot returns the latest signed root of treeID, read in its
+// own snapshot. If the tree has no roots an empty root is returned.
+func latestSignedLogRoot(ctx context.Context, logS LogStorage, treeID int64) (trillian.SignedLogRoot, error) {
+	tx, err := logS.SnapshotForTree(ctx, treeID)
+	if err != nil {
+		return trillian.SignedLogRoot{}, err
+	}
+	defer tx.Close()
+	root, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		return trillian.SignedLogRoot{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return trillian.SignedLogRoot{}, err
+	}
+	return root, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// GetTreeLineage returns treeID followed by the trees it was cloned from, as
+// per Tree.ClonedFrom, ending at its original ancestor. Trees that aren't
+// clones have a lineage of just themselves.
+// Soft-deleted trees are included. If an ancestor has been hard-deleted the
+// lineage ends at its clone.
+func GetTreeLineage(ctx context.Context, s AdminStorage, treeID int64) ([]*trillian.Tree, error) {
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	tree, err := tx.GetTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	lineage := []*trillian.Tree{tree}
+	seen := map[int64]bool{tree.TreeId: true}
+	for tree.ClonedFrom != 0 {
+		if seen[tree.ClonedFrom] {
+			return nil, errors.Errorf(errors.FailedPrecondition, "lineage of tree %v has a cycle at tree %v", treeID, tree.ClonedFrom)
+		}
+		parent, err := tx.GetTree(ctx, tree.ClonedFrom)
+		switch {
+		case errors.ErrorCode(err) == errors.NotFound:
+			return lineage, tx.Commit()
+		case err != nil:
+			return nil, err
+		}
+		lineage = append(lineage, parent)
+		seen[parent.TreeId] = true
+		tree = parent
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return lineage, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// DescriptionEllipsis marks descriptions truncated by TruncateDescription.
+const DescriptionEllipsis = "…"
+
+// TruncateDescription truncates the Description of tree to at most
+// opts.MaxDescriptionBytes bytes, if set. Truncated descriptions end in
+// DescriptionEllipsis and are cut at a rune boundary, so multibyte
+// characters are never split.
+// It's meant to be called by AdminStorage implementations before returning
+// trees from AdminReader.ListTreesForDisplay. tree must not be shared with storage, as
+// it's modified.
+func TruncateDescription(opts AdminStorageOptions, tree *trillian.Tree) {
+	max := opts.MaxDescriptionBytes
+	if max <= 0 || len(tree.Description) <= max {
+		return
+	}
+	suffix := DescriptionEllipsis
+	if max < len(suffix) {
+		suffix = ""
+	}
+	end := max - len(suffix)
+	for end > 0 && !utf8.RuneStart(tree.Description[end]) {
+		end--
+	}
+	tree.Description = tree.Description[:end] + suffix
+}
+
+// DefaultMaxListPageSize is the largest page returned by ListTreesPaginated,
+// unless overridden by AdminStorageOptions.MaxListPageSize.
+const DefaultMaxListPageSize = 1000
+
+// ListTreesPaginated returns a page of at most pageSize trees, ordered by ID,
+// starting after pageToken. An empty pageToken starts from the first tree.
+// A pageSize that is zero or larger than opts.MaxListPageSize is treated as
+// opts.MaxListPageSize.
+// The returned token is non-empty if more trees exist, and should be passed
+// as pageToken to fetch the next page. Tokens are opaque to callers.
+func ListTreesPaginated(ctx context.Context, tx ReadOnlyAdminTX, opts AdminStorageOptions, includeDeleted bool, pageSize int, pageToken string) ([]*trillian.Tree, string, error) {
+	maxPageSize := opts.MaxListPageSize
+	if maxPageSize == 0 {
+		maxPageSize = DefaultMaxListPageSize
+	}
+	switch {
+	case pageSize < 0:
+		return nil, "", errors.Errorf(errors.InvalidArgument, "invalid page size: %v", pageSize)
+	case pageSize == 0 || pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+	var lastID int64
+	if pageToken != "" {
+		var err error
+		lastID, err = strconv.ParseInt(pageToken, 10, 64)
+		if err != nil {
+			return nil, "", errors.Errorf(errors.InvalidArgument, "invalid page token: %q", pageToken)
+		}
+	}
+
+	trees, err := tx.ListTrees(ctx, includeDeleted)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(trees, func(i, j int) bool { return trees[i].TreeId < trees[j].TreeId })
+	start := sort.Search(len(trees), func(i int) bool { return trees[i].TreeId > lastID })
+	trees = trees[start:]
+
+	if len(trees) <= pageSize {
+		return trees, "", nil
+	}
+	page := trees[:pageSize]
+	return page, strconv.FormatInt(page[pageSize-1].TreeId, 10), nil
+}
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
 )
 
 // ReadOnlyLogTX provides a read-only view into log data.
@@ -26,6 +27,20 @@ import (
 type ReadOnlyLogTX interface {
 	LogMetadata
 
+	// GetLatestSignedLogRoots returns the most recent SignedLogRoot of each of
+	// the specified trees, keyed by tree ID. Trees that don't have a root yet
+	// are omitted from the result.
+	GetLatestSignedLogRoots(ctx context.Context, treeIDs []int64) (map[int64]*trillian.SignedLogRoot, error)
+
+	// EstimateTreeStorageBytes returns an approximate size, in bytes, of the
+	// log data stored for the specified tree, such as leaves, nodes and
+	// roots. Estimates are meant for capacity planning: how they're computed
+	// is implementation-specific, and they needn't match the actual disk
+	// usage. Implementations may read all of the tree's data, so it's not
+	// meant to be called often.
+	// Returns a NotFound error if the tree doesn't exist.
+	EstimateTreeStorageBytes(ctx context.Context, treeID int64) (int64, error)
+
 	// Commit ensures the data read by the TX is consistent in the database. Only after Commit the
 	// data read should be regarded as valid.
 	Commit() error
@@ -93,6 +108,7 @@ type LeafQueuer interface {
 	//  - nil otherwise.
 	// Duplicates are only reported if the underlying tree does not permit duplicates, and are
 	// considered duplicate if their leaf.LeafIdentityHash matches.
+	// PREORDERED_LOG trees don't accept queued leaves, see CheckLeavesQueueable.
 	QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error)
 }
 
@@ -104,6 +120,12 @@ type LeafDequeuer interface {
 	// guard intervals to be configured.
 	DequeueLeaves(ctx context.Context, limit int, cutoffTime time.Time) ([]*trillian.LogLeaf, error)
 	UpdateSequencedLeaves(ctx context.Context, leaves []*trillian.LogLeaf) error
+	// RequeueLeaves queues again the leaves identified by leafIdentityHashes, so they're
+	// returned by later calls to DequeueLeaves. It's meant to recover leaves that were dequeued
+	// but not integrated, e.g. due to a sequencing failure mid-batch.
+	// Leaves that are already integrated or queued, or that were never queued, are skipped.
+	// Returns the number of leaves requeued.
+	RequeueLeaves(ctx context.Context, leafIdentityHashes [][]byte) (int, error)
 }
 
 // LeafReader provides a read only interface to stored tree leaves
@@ -113,6 +135,11 @@ type LeafReader interface {
 	GetSequencedLeafCount(ctx context.Context) (int64, error)
 	// GetLeavesByIndex returns leaf metadata and data for a set of specified sequenced leaf indexes.
 	GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*trillian.LogLeaf, error)
+	// GetLeavesByRange returns up to count sequenced leaves, ordered by index, starting at
+	// index start. Fewer leaves are returned if the range extends past the end of the tree.
+	// Returns an OutOfRange error if start is negative, or InvalidArgument if count isn't
+	// positive.
+	GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error)
 	// GetLeavesByHash looks up sequenced leaf metadata and data by their Merkle leaf hash. If the
 	// tree permits duplicate leaves callers must be prepared to handle multiple results with the
 	// same hash but different sequence numbers. If orderBySequence is true then the returned data
@@ -147,3 +174,24 @@ type LogMetadata interface {
 	// Consider carefully whether you really need to call it!
 	GetUnsequencedCounts(ctx context.Context) (CountByLogID, error)
 }
+
+// CheckLogTreeType returns an InvalidArgument error unless tree is of one of
+// the types served by LogStorage, i.e., LOG or PREORDERED_LOG.
+func CheckLogTreeType(tree *trillian.Tree) error {
+	switch tree.TreeType {
+	case trillian.TreeType_LOG, trillian.TreeType_PREORDERED_LOG:
+		return nil
+	}
+	return errors.Errorf(errors.InvalidArgument, "operation not allowed for %s-type trees (wanted LOG or PREORDERED_LOG-type)", tree.TreeType)
+}
+
+// CheckLeavesQueueable returns a FailedPrecondition error if leaves can't be
+// queued to tree. Leaves of PREORDERED_LOG trees are sequenced by their source
+// log, so they must be added at their existing indices through the pre-ordered
+// path rather than queued for sequencing.
+func CheckLeavesQueueable(tree *trillian.Tree) error {
+	if tree.TreeType == trillian.TreeType_PREORDERED_LOG {
+		return errors.Errorf(errors.FailedPrecondition, "tree %v is a PREORDERED_LOG: leaves must be added at their existing indices through the pre-ordered path, not queued", tree.TreeId)
+	}
+	return nil
+}
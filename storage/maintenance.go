@@ -0,0 +1,82 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// maintenanceRestoreTimeout bounds the restoration of a tree's state after
+// maintenance.
+const maintenanceRestoreTimeout = 30 * time.Second
+
+// WithTreeMaintenance puts the specified tree in the MAINTENANCE state, runs
+// fn and then restores the tree's previous state.
+// The previous state is restored even if fn returns an error or panics, or ctx
+// is done by then, so trees aren't left in MAINTENANCE when ctx is canceled.
+// fn's error is returned, or the restoration error if fn succeeded.
+// Returns a FailedPrecondition error, without calling fn, if the tree is
+// already under maintenance.
+func WithTreeMaintenance(ctx context.Context, adminStorage AdminStorage, treeID int64, fn func() error) (err error) {
+	prevState, err := setTreeState(ctx, adminStorage, treeID, trillian.TreeState_MAINTENANCE)
+	if err != nil {
+		return err
+	}
+	if prevState == trillian.TreeState_MAINTENANCE {
+		// Leave the tree to its current maintainer.
+		return errors.Errorf(errors.FailedPrecondition, "tree %v is already under maintenance", treeID)
+	}
+
+	defer func() {
+		restoreCtx, cancel := context.WithTimeout(context.Background(), maintenanceRestoreTimeout)
+		defer cancel()
+		_, restoreErr := setTreeState(restoreCtx, adminStorage, treeID, prevState)
+		switch {
+		case restoreErr == nil:
+		case err == nil:
+			err = restoreErr
+		default:
+			glog.Errorf("tree %v: failed to restore state %s after maintenance: %v", treeID, prevState, restoreErr)
+		}
+	}()
+	return fn()
+}
+
+// setTreeState sets the state of the specified tree to state, in its own
+// transaction, and returns the previous state.
+func setTreeState(ctx context.Context, adminStorage AdminStorage, treeID int64, state trillian.TreeState) (trillian.TreeState, error) {
+	tx, err := adminStorage.Begin(ctx)
+	if err != nil {
+		return trillian.TreeState_UNKNOWN_TREE_STATE, err
+	}
+	defer tx.Close()
+
+	var prevState trillian.TreeState
+	if _, err := tx.UpdateTree(ctx, treeID, func(tree *trillian.Tree) {
+		prevState = tree.TreeState
+		tree.TreeState = state
+	}); err != nil {
+		return trillian.TreeState_UNKNOWN_TREE_STATE, err
+	}
+	if err := tx.Commit(); err != nil {
+		return trillian.TreeState_UNKNOWN_TREE_STATE, err
+	}
+	return prevState, nil
+}
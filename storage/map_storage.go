@@ -24,6 +24,11 @@ import (
 // ReadOnlyMapTX provides a read-only view into log data.
 // A ReadOnlyMapTX, unlike ReadOnlyMapTreeTX, is not tied to a particular tree.
 type ReadOnlyMapTX interface {
+	// EstimateTreeStorageBytes returns an approximate size, in bytes, of the
+	// map data stored for the specified tree, as per
+	// ReadOnlyLogTX.EstimateTreeStorageBytes.
+	EstimateTreeStorageBytes(ctx context.Context, treeID int64) (int64, error)
+
 	// Commit ensures the data read by the TX is consistent in the database. Only after Commit the
 	// data read should be regarded as valid.
 	Commit() error
@@ -41,6 +46,7 @@ type ReadOnlyMapTreeTX interface {
 	ReadOnlyTreeTX
 	MapRootReader
 	Getter
+	ProofGetter
 }
 
 // MapTreeTX is the transactional interface for reading/modifying a Map.
@@ -53,6 +59,7 @@ type MapTreeTX interface {
 	MapRootReader
 	MapRootWriter
 	Getter
+	ProofGetter
 	Setter
 }
 
@@ -102,6 +109,20 @@ type Getter interface {
 	Get(ctx context.Context, revision int64, keyHashes [][]byte) ([]trillian.MapLeaf, error)
 }
 
+// ProofGetter allows access to the values stored in the map, along with
+// proofs of their inclusion.
+type ProofGetter interface {
+	// GetMapLeavesWithProof retrieves the values associated with indexes at
+	// the specified revision, each with an inclusion proof against the map
+	// root of that revision.
+	// One MapLeafInclusion is returned per index, in the same order. Indexes
+	// without a value are returned as an empty leaf with a non-inclusion
+	// proof.
+	// Returns an InvalidArgument error if an index isn't of the map hasher's
+	// size.
+	GetMapLeavesWithProof(ctx context.Context, revision int64, indexes [][]byte) ([]*trillian.MapLeafInclusion, error)
+}
+
 // MapRootReader provides access to the map roots.
 type MapRootReader interface {
 	// GetSignedMapRoot returns the SignedMapRoot associated with the
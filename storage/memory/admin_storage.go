@@ -17,24 +17,35 @@ package memory
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
 	"github.com/google/trillian/storage"
 )
 
 // NewAdminStorage returns a storage.AdminStorage implementation backed by
 // memoryTreeStorage.
 func NewAdminStorage(ms storage.LogStorage) storage.AdminStorage {
-	return &memoryAdminStorage{ms.(*memoryLogStorage).memoryTreeStorage}
+	return NewAdminStorageWithOptions(ms, storage.AdminStorageOptions{})
+}
+
+// NewAdminStorageWithOptions returns a storage.AdminStorage implementation
+// backed by memoryTreeStorage and configured by opts.
+func NewAdminStorageWithOptions(ms storage.LogStorage, opts storage.AdminStorageOptions) storage.AdminStorage {
+	return &memoryAdminStorage{ms: ms.(*memoryLogStorage).memoryTreeStorage, opts: opts}
 }
 
 // memoryAdminStorage implements storage.AdminStorage
 type memoryAdminStorage struct {
-	ms *memoryTreeStorage
+	ms   *memoryTreeStorage
+	opts storage.AdminStorageOptions
 }
 
 func (s *memoryAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
@@ -42,7 +53,7 @@ func (s *memoryAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdmi
 }
 
 func (s *memoryAdminStorage) Begin(ctx context.Context) (storage.AdminTX, error) {
-	return &adminTX{ms: s.ms}, nil
+	return &adminTX{ms: s.ms, opts: s.opts, acceptingWrites: storage.AcceptingWrites()}, nil
 }
 
 func (s *memoryAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
@@ -50,20 +61,38 @@ func (s *memoryAdminStorage) CheckDatabaseAccessible(ctx context.Context) error
 }
 
 type adminTX struct {
-	ms *memoryTreeStorage
-	// mu guards reads/writes on closed, which happen only on
-	// Commit/Rollback/IsClosed/Close methods.
-	// We don't check closed on *all* methods (apart from the ones above),
-	// as we trust tx to keep tabs on its state (and consequently fail to do
-	// queries after closed).
+	ms   *memoryTreeStorage
+	opts storage.AdminStorageOptions
+	// acceptingWrites is the value of storage.AcceptingWrites() when the
+	// transaction began.
+	acceptingWrites bool
+	// mu guards reads/writes on closed.
+	// All operations check closed, so that use of a committed or rolled
+	// back transaction consistently fails with FailedPrecondition.
 	mu     sync.RWMutex
 	closed bool
 }
 
+// checkOpen returns a FailedPrecondition error if t is already closed.
+func (t *adminTX) checkOpen() error {
+	if t.IsClosed() {
+		return errors.New(errors.FailedPrecondition, "transaction already closed")
+	}
+	return nil
+}
+
+// now returns the current time, as per the TimeSource of t.opts.
+func (t *adminTX) now() time.Time {
+	return storage.TimeSourceFor(t.opts).Now()
+}
+
 func (t *adminTX) Commit() error {
 	// TODO(al): The admin implementation isn't transactional
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if t.closed {
+		return errors.New(errors.FailedPrecondition, "transaction already closed")
+	}
 	t.closed = true
 	return nil
 }
@@ -72,6 +101,9 @@ func (t *adminTX) Rollback() error {
 	// TODO(al): The admin implementation isn't transactional
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if t.closed {
+		return errors.New(errors.FailedPrecondition, "transaction already closed")
+	}
 	t.closed = true
 	return nil
 }
@@ -99,17 +131,28 @@ func (t *adminTX) Close() error {
 }
 
 func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTreeID(treeID); err != nil {
+		return nil, err
+	}
 	tree := t.ms.getTree(treeID)
-	tree.RLock()
-	defer tree.RUnlock()
-
 	if tree == nil {
-		return nil, fmt.Errorf("no such treeID %d", treeID)
+		return nil, errors.Errorf(errors.NotFound, "no such treeID %d", treeID)
 	}
-	return tree.meta, nil
+	tree.RLock()
+	defer tree.RUnlock()
+	ret := proto.Clone(tree.meta).(*trillian.Tree)
+	storage.DecorateTree(ctx, ret)
+	storage.RedactTree(ctx, t.opts, ret)
+	return ret, nil
 }
 
 func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
 	t.ms.mu.RLock()
 	defer t.ms.mu.RUnlock()
 
@@ -121,33 +164,390 @@ func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64
 }
 
 func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
 	t.ms.mu.RLock()
 	defer t.ms.mu.RUnlock()
 
 	var ret []*trillian.Tree
 	for _, v := range t.ms.trees {
-		ret = append(ret, v.meta)
+		tree := proto.Clone(v.meta).(*trillian.Tree)
+		storage.DecorateTree(ctx, tree)
+		storage.RedactTree(ctx, t.opts, tree)
+		ret = append(ret, tree)
+	}
+	return ret, nil
+}
+
+func (t *adminTX) ListTreesForDisplay(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	trees, err := t.ListTrees(ctx, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range trees {
+		storage.TruncateDescription(t.opts, tree)
+	}
+	return trees, nil
+}
+
+func (t *adminTX) ListSequenceableTreeIDs(ctx context.Context) ([]int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	ret := []int64{}
+	for _, v := range t.ms.trees {
+		switch {
+		case v.meta.Deleted:
+		case v.meta.TreeType != trillian.TreeType_LOG && v.meta.TreeType != trillian.TreeType_PREORDERED_LOG:
+		case v.meta.TreeState != trillian.TreeState_ACTIVE && v.meta.TreeState != trillian.TreeState_DRAINING:
+		case v.meta.SequencingPaused:
+		default:
+			ret = append(ret, v.meta.TreeId)
+		}
+	}
+	return ret, nil
+}
+
+func (t *adminTX) GetTreesByDisplayNames(ctx context.Context, names []string, includeDeleted bool) (map[string]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+	matches := make(map[string][]*trillian.Tree)
+	for _, v := range t.ms.trees {
+		if !wanted[v.meta.DisplayName] || (v.meta.Deleted && !includeDeleted) {
+			continue
+		}
+		matches[v.meta.DisplayName] = append(matches[v.meta.DisplayName], v.meta)
+	}
+
+	ret := make(map[string]*trillian.Tree)
+	for name, trees := range matches {
+		if len(trees) > 1 {
+			treeIDs := make([]int64, 0, len(trees))
+			for _, tree := range trees {
+				treeIDs = append(treeIDs, tree.TreeId)
+			}
+			sort.Slice(treeIDs, func(i, j int) bool { return treeIDs[i] < treeIDs[j] })
+			return nil, errors.Errorf(errors.FailedPrecondition, "display name %q is ambiguous, matches trees %v", name, treeIDs)
+		}
+		tree := proto.Clone(trees[0]).(*trillian.Tree)
+		storage.DecorateTree(ctx, tree)
+		storage.RedactTree(ctx, t.opts, tree)
+		ret[name] = tree
+	}
+	return ret, nil
+}
+
+func (t *adminTX) ExistingTreeIDs(ctx context.Context, candidateIDs []int64, includeDeleted bool) (map[int64]bool, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	ret := make(map[int64]bool)
+	for _, id := range candidateIDs {
+		v, ok := t.ms.trees[id]
+		ret[id] = ok && (!v.meta.Deleted || includeDeleted)
+	}
+	return ret, nil
+}
+
+func (t *adminTX) CountTreesByLabel(ctx context.Context, includeDeleted bool) (map[string]int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for _, v := range t.ms.trees {
+		if v.meta.Deleted && !includeDeleted {
+			continue
+		}
+		for _, label := range v.meta.Labels {
+			counts[label]++
+		}
+	}
+	return counts, nil
+}
+
+func (t *adminTX) ListTreeRevisions(ctx context.Context, includeDeleted bool) (map[int64]int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	revisions := make(map[int64]int64)
+	for id, v := range t.ms.trees {
+		if v.meta.Deleted && !includeDeleted {
+			continue
+		}
+		revisions[id] = v.meta.Revision
+	}
+	return revisions, nil
+}
+
+func (t *adminTX) StreamTreeConfigHashes(ctx context.Context, includeDeleted bool) (<-chan storage.TreeHash, <-chan error) {
+	return storage.StreamTreeHashes(ctx, func() ([]storage.TreeHash, error) {
+		if err := t.checkOpen(); err != nil {
+			return nil, err
+		}
+		t.ms.mu.RLock()
+		defer t.ms.mu.RUnlock()
+
+		hashes := []storage.TreeHash{}
+		for _, v := range t.ms.trees {
+			if v.meta.Deleted && !includeDeleted {
+				continue
+			}
+			hash, err := storage.TreeConfigHash(v.meta)
+			if err != nil {
+				return nil, err
+			}
+			hashes = append(hashes, storage.TreeHash{TreeID: v.meta.TreeId, ConfigHash: hash})
+		}
+		sort.Slice(hashes, func(i, j int) bool { return hashes[i].TreeID < hashes[j].TreeID })
+		return hashes, nil
+	})
+}
+
+func (t *adminTX) GetTreeByExternalRef(ctx context.Context, ref string, includeDeleted bool) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		return nil, errors.New(errors.InvalidArgument, "an external_ref is required")
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	var matches []*trillian.Tree
+	for _, v := range t.ms.trees {
+		if v.meta.ExternalRef == ref && (!v.meta.Deleted || includeDeleted) {
+			matches = append(matches, v.meta)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, errors.Errorf(errors.NotFound, "no tree with external_ref %q", ref)
+	case 1:
+		tree := proto.Clone(matches[0]).(*trillian.Tree)
+		storage.DecorateTree(ctx, tree)
+		storage.RedactTree(ctx, t.opts, tree)
+		return tree, nil
+	}
+	treeIDs := make([]int64, 0, len(matches))
+	for _, tree := range matches {
+		treeIDs = append(treeIDs, tree.TreeId)
+	}
+	sort.Slice(treeIDs, func(i, j int) bool { return treeIDs[i] < treeIDs[j] })
+	return nil, errors.Errorf(errors.FailedPrecondition, "external_ref %q is ambiguous, matches trees %v", ref, treeIDs)
+}
+
+func (t *adminTX) ListRecentTrees(ctx context.Context, limit int, includeDeleted bool) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, errors.Errorf(errors.InvalidArgument, "invalid limit: %v", limit)
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	type recentTree struct {
+		tree       *trillian.Tree
+		createTime time.Time
+	}
+	var recent []recentTree
+	for _, v := range t.ms.trees {
+		if v.meta.Deleted && !includeDeleted {
+			continue
+		}
+		createTime, err := ptypes.Timestamp(v.meta.CreateTime)
+		if err != nil {
+			return nil, fmt.Errorf("tree %v: invalid create_time: %v", v.meta.TreeId, err)
+		}
+		recent = append(recent, recentTree{tree: v.meta, createTime: createTime})
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		if !recent[i].createTime.Equal(recent[j].createTime) {
+			return recent[i].createTime.After(recent[j].createTime)
+		}
+		return recent[i].tree.TreeId > recent[j].tree.TreeId
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+
+	ret := []*trillian.Tree{}
+	for _, r := range recent {
+		tree := proto.Clone(r.tree).(*trillian.Tree)
+		storage.DecorateTree(ctx, tree)
+		storage.RedactTree(ctx, t.opts, tree)
+		ret = append(ret, tree)
+	}
+	return ret, nil
+}
+
+func (t *adminTX) ListUntouchedTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	ret := []*trillian.Tree{}
+	for _, v := range t.ms.trees {
+		if v.meta.Deleted && !includeDeleted {
+			continue
+		}
+		if !proto.Equal(v.meta.CreateTime, v.meta.UpdateTime) {
+			continue
+		}
+		tree := proto.Clone(v.meta).(*trillian.Tree)
+		storage.DecorateTree(ctx, tree)
+		storage.RedactTree(ctx, t.opts, tree)
+		ret = append(ret, tree)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].TreeId < ret[j].TreeId })
+	return ret, nil
+}
+
+func (t *adminTX) ListHardDeletableTrees(ctx context.Context, retention time.Duration, now time.Time) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	cutoff := now.Add(-retention)
+	ret := []*trillian.Tree{}
+	for _, v := range t.ms.trees {
+		if !v.meta.Deleted {
+			continue
+		}
+		deleteTime, err := ptypes.Timestamp(v.meta.DeleteTime)
+		if err != nil {
+			return nil, fmt.Errorf("tree %v: invalid delete_time: %v", v.meta.TreeId, err)
+		}
+		if !deleteTime.Before(cutoff) {
+			continue
+		}
+		tree := proto.Clone(v.meta).(*trillian.Tree)
+		storage.DecorateTree(ctx, tree)
+		storage.RedactTree(ctx, t.opts, tree)
+		ret = append(ret, tree)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		ti, _ := ptypes.Timestamp(ret[i].DeleteTime)
+		tj, _ := ptypes.Timestamp(ret[j].DeleteTime)
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return ret[i].TreeId < ret[j].TreeId
+	})
+	return ret, nil
+}
+
+func (t *adminTX) GetTreeKeyInfo(ctx context.Context, treeID int64) (*storage.KeyInfo, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	tree := t.ms.getTree(treeID)
+	if tree == nil {
+		return nil, errors.Errorf(errors.NotFound, "no such treeID %d", treeID)
+	}
+	tree.RLock()
+	defer tree.RUnlock()
+	return storage.NewKeyInfo(tree.meta.PublicKey)
+}
+
+func (t *adminTX) ResolveTreeAlias(ctx context.Context, alias string) (int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return 0, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+	id, ok := t.ms.aliases[alias]
+	if !ok {
+		return 0, errors.Errorf(errors.NotFound, "alias %q not found", alias)
+	}
+	return id, nil
+}
+
+func (t *adminTX) ListTreeCountSnapshots(ctx context.Context, from, to time.Time) ([]storage.CountSnapshot, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+	var ret []storage.CountSnapshot
+	for _, snapshot := range t.ms.countSnapshots {
+		if !snapshot.Time.Before(from) && snapshot.Time.Before(to) {
+			ret = append(ret, snapshot)
+		}
 	}
 	return ret, nil
 }
 
 func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.Tree, error) {
-	if err := storage.ValidateTreeForCreation(ctx, tr); err != nil {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if !t.acceptingWrites {
+		return nil, storage.ErrNotAcceptingWrites
+	}
+	tr = storage.DeriveLabels(tr)
+	if err := storage.ValidateTreeForCreation(ctx, t.opts, tr); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTreeKeyCurve(t.opts, tr); err != nil {
 		return nil, err
 	}
 	if err := validateStorageSettings(tr); err != nil {
 		return nil, err
 	}
 
-	id, err := storage.NewTreeID()
-	if err != nil {
-		return nil, err
+	now := t.now()
+
+	t.ms.mu.Lock()
+	defer t.ms.mu.Unlock()
+
+	if tr.ClonedFrom != 0 {
+		if _, ok := t.ms.trees[tr.ClonedFrom]; !ok {
+			return nil, errors.Errorf(errors.InvalidArgument, "cloned_from tree %v not found", tr.ClonedFrom)
+		}
 	}
 
-	now := time.Now()
+	id := tr.TreeId
+	if expiry, ok := t.ms.reservations[id]; id != 0 && ok && now.Before(expiry) {
+		delete(t.ms.reservations, id)
+	} else {
+		var err error
+		if id, err = t.newUnusedTreeID(now); err != nil {
+			return nil, err
+		}
+	}
 
+	var err error
 	meta := *tr
 	meta.TreeId = id
+	meta.LastSequencedTime = nil // New trees were never sequenced.
+	meta.SequencingPaused = false
+	meta.Revision = 1
 	meta.CreateTime, err = ptypes.TimestampProto(now)
 	if err != nil {
 		return nil, err
@@ -156,43 +556,421 @@ func (t *adminTX) CreateTree(ctx context.Context, tr *trillian.Tree) (*trillian.
 	if err != nil {
 		return nil, err
 	}
+	storage.CanonicalizeTree(&meta)
+	t.ms.trees[id] = newTree(meta)
 
+	glog.Infof("trees: %v", t.ms.trees)
+
+	storage.RedactTree(ctx, t.opts, &meta)
+	return &meta, nil
+}
+
+func (t *adminTX) CreateTreeWithAlias(ctx context.Context, tree *trillian.Tree, alias string) (*trillian.Tree, error) {
+	return storage.CreateTreeWithAlias(ctx, t, tree, alias, t.addTreeAlias)
+}
+
+// addTreeAlias points the free alias to treeID.
+func (t *adminTX) addTreeAlias(ctx context.Context, alias string, treeID int64) error {
 	t.ms.mu.Lock()
 	defer t.ms.mu.Unlock()
-	t.ms.trees[id] = newTree(meta)
+	if _, ok := t.ms.aliases[alias]; ok {
+		return errors.Errorf(errors.AlreadyExists, "alias %q already in use", alias)
+	}
+	t.ms.aliases[alias] = treeID
+	return nil
+}
 
-	glog.Infof("trees: %v", t.ms.trees)
+func (t *adminTX) ReserveTreeID(ctx context.Context) (int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return 0, err
+	}
+	now := t.now()
+
+	t.ms.mu.Lock()
+	defer t.ms.mu.Unlock()
+	id, err := t.newUnusedTreeID(now)
+	if err != nil {
+		return 0, err
+	}
+	t.ms.reservations[id] = storage.TreeIDReservationExpiry(t.opts, now)
+	return id, nil
+}
 
+// newUnusedTreeID generates a tree ID that is neither assigned to a tree nor
+// reserved as of now. t.ms.mu must be held.
+func (t *adminTX) newUnusedTreeID(now time.Time) (int64, error) {
+	for {
+		id, err := storage.NewTreeID()
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := t.ms.trees[id]; ok {
+			continue
+		}
+		if expiry, ok := t.ms.reservations[id]; ok && now.Before(expiry) {
+			continue
+		}
+		return id, nil
+	}
+}
+
+func (t *adminTX) ImportTree(ctx context.Context, tr *trillian.Tree) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if !t.acceptingWrites {
+		return nil, storage.ErrNotAcceptingWrites
+	}
+	if err := storage.ValidateTreeForImport(ctx, t.opts, tr); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tr); err != nil {
+		return nil, err
+	}
+
+	t.ms.mu.Lock()
+	defer t.ms.mu.Unlock()
+	if _, ok := t.ms.trees[tr.TreeId]; ok {
+		return nil, errors.Errorf(errors.AlreadyExists, "tree %v already exists", tr.TreeId)
+	}
+	meta := *tr
+	storage.CanonicalizeTree(&meta)
+	// Imported trees keep their revision, if they have one.
+	if meta.Revision == 0 {
+		meta.Revision = 1
+	}
+	t.ms.trees[meta.TreeId] = newTree(meta)
+	storage.RedactTree(ctx, t.opts, &meta)
 	return &meta, nil
 }
 
 func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	return t.updateTree(ctx, treeID, nil /* precondition */, updateFunc)
+}
+
+func (t *adminTX) ReplaceTree(ctx context.Context, desired *trillian.Tree, expectedRevision int64) (*trillian.Tree, error) {
+	precondition := func(storedTree *trillian.Tree) error {
+		return storage.CheckTreeRevision(storedTree, expectedRevision)
+	}
+	return t.updateTree(ctx, desired.TreeId, precondition, func(tree *trillian.Tree) {
+		revision := tree.Revision
+		*tree = *proto.Clone(desired).(*trillian.Tree)
+		tree.Revision = revision
+	})
+}
+
+// updateTree updates treeID as per UpdateTree. If precondition is non-nil,
+// it's called on the stored tree while holding its lock, and the update is
+// aborted if it returns an error.
+func (t *adminTX) updateTree(ctx context.Context, treeID int64, precondition func(*trillian.Tree) error, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTreeID(treeID); err != nil {
+		return nil, err
+	}
 	mTree := t.ms.getTree(treeID)
+	if mTree == nil {
+		return nil, errors.Errorf(errors.NotFound, "no such treeID %d", treeID)
+	}
 	mTree.mu.Lock()
 	defer mTree.mu.Unlock()
+	if precondition != nil {
+		if err := precondition(mTree.meta); err != nil {
+			return nil, err
+		}
+	}
 
-	tree := mTree.meta
-	beforeUpdate := *tree
+	// Update a copy, so the stored tree is unchanged if validation fails.
+	tree := proto.Clone(mTree.meta).(*trillian.Tree)
 	updateFunc(tree)
-	if err := storage.ValidateTreeForUpdate(ctx, &beforeUpdate, tree); err != nil {
+	storage.CanonicalizeTree(tree)
+	if err := storage.ValidateTreeForUpdate(ctx, t.opts, mTree.meta, tree); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTreeStateTransition(t.opts, mTree.meta, tree); err != nil {
 		return nil, err
 	}
 	if err := validateStorageSettings(tree); err != nil {
 		return nil, err
 	}
+	if proto.Equal(mTree.meta, tree) && !t.opts.AdvanceUpdateTimeOnNoop {
+		storage.RedactTree(ctx, t.opts, tree)
+		return tree, nil
+	}
 
 	var err error
-	tree.UpdateTime, err = ptypes.TimestampProto(time.Now())
+	tree.UpdateTime, err = ptypes.TimestampProto(t.now())
 	if err != nil {
 		return nil, err
 	}
-	return tree, nil
+	tree.Revision++
+	mTree.meta = tree
+	ret := proto.Clone(tree).(*trillian.Tree)
+	storage.RedactTree(ctx, t.opts, ret)
+	return ret, nil
+}
+
+func (t *adminTX) UpdateTrees(ctx context.Context, updates map[int64]func(*trillian.Tree)) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(updates))
+	for id := range updates {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// Lock all trees for the duration of the update, in ID order.
+	var mTrees []*tree
+	defer func() {
+		for _, mTree := range mTrees {
+			mTree.mu.Unlock()
+		}
+	}()
+
+	// Apply updates to copies, so that nothing changes unless all of them
+	// are valid.
+	var errs errors.MultiError
+	trees := make([]*trillian.Tree, 0, len(ids))
+	for _, id := range ids {
+		mTree := t.ms.getTree(id)
+		if mTree == nil {
+			errs = append(errs, errors.Errorf(errors.NotFound, "tree %v: no such treeID", id))
+			continue
+		}
+		mTree.mu.Lock()
+		mTrees = append(mTrees, mTree)
+
+		tree := proto.Clone(mTree.meta).(*trillian.Tree)
+		updates[id](tree)
+		storage.CanonicalizeTree(tree)
+		if err := storage.ValidateTreeForUpdate(ctx, t.opts, mTree.meta, tree); err != nil {
+			errs = append(errs, errors.Errorf(errors.ErrorCode(err), "tree %v: %v", id, err))
+			continue
+		}
+		if err := storage.ValidateTreeStateTransition(t.opts, mTree.meta, tree); err != nil {
+			errs = append(errs, errors.Errorf(errors.ErrorCode(err), "tree %v: %v", id, err))
+			continue
+		}
+		if err := validateStorageSettings(tree); err != nil {
+			errs = append(errs, errors.Errorf(errors.ErrorCode(err), "tree %v: %v", id, err))
+			continue
+		}
+		trees = append(trees, tree)
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	ret := make([]*trillian.Tree, 0, len(trees))
+	for i, tree := range trees {
+		if !proto.Equal(mTrees[i].meta, tree) || t.opts.AdvanceUpdateTimeOnNoop {
+			var err error
+			tree.UpdateTime, err = ptypes.TimestampProto(now)
+			if err != nil {
+				return nil, err
+			}
+			tree.Revision++
+			mTrees[i].meta = tree
+		}
+		// Return copies, as trees may now be stored.
+		updated := proto.Clone(tree).(*trillian.Tree)
+		storage.RedactTree(ctx, t.opts, updated)
+		ret = append(ret, updated)
+	}
+	return ret, nil
+}
+
+func (t *adminTX) TouchTrees(ctx context.Context, treeIDs []int64) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool)
+	ids := make([]int64, 0, len(treeIDs))
+	for _, id := range treeIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// Lock all trees for the duration of the touch, in ID order.
+	var mTrees []*tree
+	defer func() {
+		for _, mTree := range mTrees {
+			mTree.mu.Unlock()
+		}
+	}()
+	for _, id := range ids {
+		mTree := t.ms.getTree(id)
+		if mTree == nil {
+			return nil, errors.Errorf(errors.NotFound, "no such treeID %d", id)
+		}
+		mTree.mu.Lock()
+		mTrees = append(mTrees, mTree)
+	}
+
+	updateTime, err := ptypes.TimestampProto(t.now())
+	if err != nil {
+		return nil, err
+	}
+	trees := make([]*trillian.Tree, 0, len(mTrees))
+	for _, mTree := range mTrees {
+		tree := *mTree.meta
+		tree.UpdateTime = updateTime
+		tree.Revision++
+		mTree.meta = &tree
+		ret := proto.Clone(&tree).(*trillian.Tree)
+		storage.DecorateTree(ctx, ret)
+		storage.RedactTree(ctx, t.opts, ret)
+		trees = append(trees, ret)
+	}
+	return trees, nil
+}
+
+func (t *adminTX) SetTreeLabels(ctx context.Context, treeID int64, labels []string) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	mTree := t.ms.getTree(treeID)
+	if mTree == nil {
+		return nil, errors.Errorf(errors.NotFound, "no such treeID %d", treeID)
+	}
+	mTree.mu.Lock()
+	defer mTree.mu.Unlock()
+
+	// Update a copy, so the stored tree is unchanged if validation fails.
+	tree := *mTree.meta
+	tree.Labels = labels
+	storage.CanonicalizeTree(&tree)
+	if err := storage.ValidateTreeForUpdate(ctx, t.opts, mTree.meta, &tree); err != nil {
+		return nil, err
+	}
+	if proto.Equal(mTree.meta, &tree) && !t.opts.AdvanceUpdateTimeOnNoop {
+		storage.RedactTree(ctx, t.opts, &tree)
+		return &tree, nil
+	}
+
+	var err error
+	tree.UpdateTime, err = ptypes.TimestampProto(t.now())
+	if err != nil {
+		return nil, err
+	}
+	tree.Revision++
+	mTree.meta = &tree
+	ret := proto.Clone(&tree).(*trillian.Tree)
+	storage.RedactTree(ctx, t.opts, ret)
+	return ret, nil
+}
+
+func (t *adminTX) TransferLabels(ctx context.Context, fromTreeID, toTreeID int64, labels []string) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	return storage.TransferLabels(ctx, t, fromTreeID, toTreeID, labels)
+}
+
+func (t *adminTX) SetTreeAlias(ctx context.Context, alias string, treeID int64) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	if alias == "" {
+		return errors.New(errors.InvalidArgument, "empty tree alias")
+	}
+	t.ms.mu.Lock()
+	defer t.ms.mu.Unlock()
+	if _, ok := t.ms.trees[treeID]; !ok {
+		return errors.Errorf(errors.NotFound, "no such treeID %d", treeID)
+	}
+	t.ms.aliases[alias] = treeID
+	return nil
+}
+
+func (t *adminTX) WriteTreeCountSnapshot(ctx context.Context, snapshot *storage.CountSnapshot) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	t.ms.mu.Lock()
+	defer t.ms.mu.Unlock()
+	// Mirror the millisecond precision of persistent storages, where a
+	// snapshot taken in the same millisecond replaces the previous one.
+	snap := *snapshot
+	snap.Time = snap.Time.Truncate(time.Millisecond)
+	for i := range t.ms.countSnapshots {
+		if t.ms.countSnapshots[i].Time.Equal(snap.Time) {
+			t.ms.countSnapshots[i] = snap
+			return nil
+		}
+	}
+	snapshots := append(t.ms.countSnapshots, snap)
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Time.Before(snapshots[j].Time) })
+	t.ms.countSnapshots = snapshots
+	return nil
+}
+
+func (t *adminTX) RecordSequencingProgress(ctx context.Context, treeID int64, when *timestamp.Timestamp) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	if _, err := ptypes.Timestamp(when); err != nil {
+		return errors.Errorf(errors.InvalidArgument, "invalid sequencing time: %v", err)
+	}
+	mTree := t.ms.getTree(treeID)
+	if mTree == nil {
+		return errors.Errorf(errors.NotFound, "no such treeID %d", treeID)
+	}
+	mTree.mu.Lock()
+	defer mTree.mu.Unlock()
+
+	tree := *mTree.meta
+	tree.LastSequencedTime = when
+	mTree.meta = &tree
+	return nil
+}
+
+func (t *adminTX) PauseSequencing(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.setSequencingPaused(ctx, treeID, true)
+}
+
+func (t *adminTX) ResumeSequencing(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.setSequencingPaused(ctx, treeID, false)
+}
+
+func (t *adminTX) setSequencingPaused(ctx context.Context, treeID int64, paused bool) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	mTree := t.ms.getTree(treeID)
+	if mTree == nil {
+		return nil, errors.Errorf(errors.NotFound, "no such treeID %d", treeID)
+	}
+	mTree.mu.Lock()
+	defer mTree.mu.Unlock()
+
+	tree := *mTree.meta
+	tree.SequencingPaused = paused
+	tree.Revision++
+	mTree.meta = &tree
+
+	ret := proto.Clone(&tree).(*trillian.Tree)
+	storage.DecorateTree(ctx, ret)
+	storage.RedactTree(ctx, t.opts, ret)
+	return ret, nil
 }
 
 func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
 	return nil, fmt.Errorf("method not supported: SoftDeleteTree")
 }
 
+func (t *adminTX) SoftDeleteTreeIfUnchanged(ctx context.Context, treeID int64, expectedRevision int64) (*trillian.Tree, error) {
+	return nil, fmt.Errorf("method not supported: SoftDeleteTreeIfUnchanged")
+}
+
 func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
 	return fmt.Errorf("method not supported: HardDeleteTree")
 }
@@ -0,0 +1,71 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/google/trillian/storage"
+	"github.com/google/trillian/storage/testonly"
+)
+
+func TestMemoryAdminStorage(t *testing.T) {
+	// Soft and hard deletion aren't implemented, so tests deleting trees are
+	// skipped. Writes are applied immediately, so rolled back transactions
+	// aren't undone either.
+	const (
+		noDeletion = "tree deletion not supported"
+		noRollback = "rollback not supported"
+	)
+	tester := &testonly.AdminStorageTester{
+		NewAdminStorage: func() storage.AdminStorage {
+			return NewAdminStorage(NewLogStorage(nil))
+		},
+		NewAdminStorageWithOptions: func(opts storage.AdminStorageOptions) storage.AdminStorage {
+			return NewAdminStorageWithOptions(NewLogStorage(nil), opts)
+		},
+		UnsupportedTests: map[string]string{
+			"TestSoftDeleteTreeIfUnchanged":           noDeletion,
+			"TestConcurrentSoftDeleteTreeIfUnchanged": noDeletion,
+			"TestTimestampMonotonicity":               noDeletion,
+			"TestPrivateKeyAccessWrites":              noDeletion,
+			"TestAuditStoredTrees":                    noDeletion,
+			"TestExportPublicKeyBundle":               noDeletion,
+			"TestGetTreesByDisplayNames":              noDeletion,
+			"TestGetTreeByExternalRef":                noDeletion,
+			"TestExistingTreeIDs":                     noDeletion,
+			"TestCountTreesByLabel":                   noDeletion,
+			"TestListTreeRevisions":                   noDeletion,
+			"TestStreamTreeConfigHashes":              noDeletion,
+			"TestListUntouchedTrees":                  noDeletion,
+			"TestTreeCountSnapshots":                  noDeletion,
+			"TestDistinctHashStrategies":              noDeletion,
+			"TestExportTreesSince":                    noDeletion,
+			"TestVerifyStoredTreeRoundTrip":           noDeletion,
+			"TestListTrees":                           noDeletion,
+			"TestListSequenceableTreeIDs":             noDeletion,
+			"TestSoftDeleteTree":                      noDeletion,
+			"TestSoftDeleteTreeErrors":                noDeletion,
+			"TestHardDeleteTree":                      noDeletion,
+			"TestHardDeleteTreeErrors":                noDeletion,
+			"TestUndeleteTree":                        noDeletion,
+			"TestUndeleteTreeErrors":                  noDeletion,
+			"TestInvalidTreeIDs":                      noDeletion,
+			"TestImportTreesCanceled":                 noRollback,
+			"TestAdminTXClose":                        noRollback,
+		},
+	}
+	tester.RunAllTests(t)
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/google/btree"
 	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
@@ -138,10 +139,13 @@ func (m *memoryLogStorage) beginInternal(ctx context.Context, treeID int64, read
 		ctx,
 		m.admin,
 		treeID,
-		trees.GetOpts{TreeType: trillian.TreeType_LOG, Readonly: readonly})
+		trees.GetOpts{Readonly: readonly})
 	if err != nil {
 		return nil, err
 	}
+	if err := storage.CheckLogTreeType(tree); err != nil {
+		return nil, err
+	}
 	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
 	if err != nil {
 		return nil, err
@@ -156,6 +160,7 @@ func (m *memoryLogStorage) beginInternal(ctx context.Context, treeID int64, read
 	ltx := &logTreeTX{
 		treeTX: ttx,
 		ls:     m,
+		config: tree,
 	}
 
 	ltx.root, err = ltx.fetchLatestRoot(ctx)
@@ -182,8 +187,9 @@ func (m *memoryLogStorage) SnapshotForTree(ctx context.Context, treeID int64) (s
 
 type logTreeTX struct {
 	treeTX
-	ls   *memoryLogStorage
-	root trillian.SignedLogRoot
+	ls     *memoryLogStorage
+	config *trillian.Tree
+	root   trillian.SignedLogRoot
 }
 
 func (t *logTreeTX) ReadRevision() int64 {
@@ -210,6 +216,9 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 }
 
 func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	if err := storage.CheckLeavesQueueable(t.config); err != nil {
+		return nil, err
+	}
 	// Don't accept batches if any of the leaves are invalid.
 	for _, leaf := range leaves {
 		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
@@ -226,6 +235,12 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	return []*trillian.LogLeaf{}, nil
 }
 
+// RequeueLeaves is a noop, as leaves stay queued until UpdateSequencedLeaves
+// is called for them, so dequeued leaves are never lost.
+func (t *logTreeTX) RequeueLeaves(ctx context.Context, leafIdentityHashes [][]byte) (int, error) {
+	return 0, nil
+}
+
 func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
 	var sequencedLeafCount int64
 
@@ -247,6 +262,27 @@ func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*tr
 	return ret, nil
 }
 
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	switch {
+	case start < 0:
+		return nil, errors.Errorf(errors.OutOfRange, "invalid start index: %v", start)
+	case count <= 0:
+		return nil, errors.Errorf(errors.InvalidArgument, "invalid count: %v", count)
+	}
+
+	var ret []*trillian.LogLeaf
+	t.tx.AscendGreaterOrEqual(seqLeafKey(t.treeID, start), func(i btree.Item) bool {
+		leaf, ok := i.(*kv).v.(*trillian.LogLeaf)
+		if !ok || leaf.LeafIndex != start+int64(len(ret)) {
+			// Past this tree's leaves, or a gap in the sequence.
+			return false
+		}
+		ret = append(ret, leaf)
+		return int64(len(ret)) < count
+	})
+	return ret, nil
+}
+
 func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
 	m := t.tx.Get(hashToSeqKey(t.treeID)).(*kv).v.(map[string][]int64)
 
@@ -349,6 +385,32 @@ func (t *logTreeTX) GetActiveLogIDs(ctx context.Context) ([]int64, error) {
 	return t.getActiveLogIDs(ctx)
 }
 
+func (t *readOnlyLogTX) GetLatestSignedLogRoots(ctx context.Context, treeIDs []int64) (map[int64]*trillian.SignedLogRoot, error) {
+	t.ms.mu.RLock()
+	defer t.ms.mu.RUnlock()
+
+	roots := make(map[int64]*trillian.SignedLogRoot)
+	for _, id := range treeIDs {
+		tree, ok := t.ms.trees[id]
+		if !ok {
+			continue
+		}
+		tree.RLock()
+		r := tree.store.Get(sthKey(id, tree.currentSTH))
+		tree.RUnlock()
+		if r == nil {
+			continue
+		}
+		root := r.(*kv).v.(trillian.SignedLogRoot)
+		roots[id] = &root
+	}
+	return roots, nil
+}
+
+func (t *readOnlyLogTX) EstimateTreeStorageBytes(ctx context.Context, treeID int64) (int64, error) {
+	return 0, fmt.Errorf("method not supported: EstimateTreeStorageBytes")
+}
+
 func (t *readOnlyLogTX) GetUnsequencedCounts(ctx context.Context) (storage.CountByLogID, error) {
 	t.ms.mu.RLock()
 	defer t.ms.mu.RUnlock()
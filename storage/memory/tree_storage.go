@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
@@ -80,14 +81,23 @@ func (t *tree) RUnlock() {
 // memoryTreeStorage is shared between the memoryLog and (forthcoming) memoryMap-
 // Storage implementations, and contains functionality which is common to both,
 type memoryTreeStorage struct {
-	// mu only protects access to the trees map.
+	// mu only protects access to the trees, reservations and aliases maps,
+	// and to countSnapshots.
 	mu    sync.RWMutex
 	trees map[int64]*tree
+	// reservations maps reserved tree IDs to their expiry time.
+	reservations map[int64]time.Time
+	// aliases maps tree aliases to tree IDs.
+	aliases map[string]int64
+	// countSnapshots contains all tree count snapshots, ordered by time.
+	countSnapshots []storage.CountSnapshot
 }
 
 func newTreeStorage() *memoryTreeStorage {
 	return &memoryTreeStorage{
-		trees: make(map[int64]*tree),
+		trees:        make(map[int64]*tree),
+		reservations: make(map[int64]time.Time),
+		aliases:      make(map[string]int64),
 	}
 }
 
@@ -0,0 +1,48 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+)
+
+// FindTreesMissingPublicKey returns the IDs of all trees in adminStorage,
+// including soft-deleted ones, that have no public key (or an empty DER).
+// Such trees predate public key validation and can't have their signatures
+// verified. IDs are returned in ascending order.
+func FindTreesMissingPublicKey(ctx context.Context, adminStorage AdminStorage) ([]int64, error) {
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, true /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, tree := range trees {
+		if len(tree.GetPublicKey().GetDer()) == 0 {
+			ids = append(ids, tree.TreeId)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
@@ -7,6 +7,7 @@ package storage
 import (
 	context "context"
 	gomock "github.com/golang/mock/gomock"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	trillian "github.com/google/trillian"
 	reflect "reflect"
 	time "time"
@@ -120,6 +121,19 @@ func (mr *MockAdminTXMockRecorder) Commit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockAdminTX)(nil).Commit))
 }
 
+// CountTreesByLabel mocks base method
+func (m *MockAdminTX) CountTreesByLabel(arg0 context.Context, arg1 bool) (map[string]int64, error) {
+	ret := m.ctrl.Call(m, "CountTreesByLabel", arg0, arg1)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTreesByLabel indicates an expected call of CountTreesByLabel
+func (mr *MockAdminTXMockRecorder) CountTreesByLabel(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTreesByLabel", reflect.TypeOf((*MockAdminTX)(nil).CountTreesByLabel), arg0, arg1)
+}
+
 // CreateTree mocks base method
 func (m *MockAdminTX) CreateTree(arg0 context.Context, arg1 *trillian.Tree) (*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "CreateTree", arg0, arg1)
@@ -133,6 +147,32 @@ func (mr *MockAdminTXMockRecorder) CreateTree(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTree", reflect.TypeOf((*MockAdminTX)(nil).CreateTree), arg0, arg1)
 }
 
+// CreateTreeWithAlias mocks base method
+func (m *MockAdminTX) CreateTreeWithAlias(arg0 context.Context, arg1 *trillian.Tree, arg2 string) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "CreateTreeWithAlias", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTreeWithAlias indicates an expected call of CreateTreeWithAlias
+func (mr *MockAdminTXMockRecorder) CreateTreeWithAlias(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTreeWithAlias", reflect.TypeOf((*MockAdminTX)(nil).CreateTreeWithAlias), arg0, arg1, arg2)
+}
+
+// ExistingTreeIDs mocks base method
+func (m *MockAdminTX) ExistingTreeIDs(arg0 context.Context, arg1 []int64, arg2 bool) (map[int64]bool, error) {
+	ret := m.ctrl.Call(m, "ExistingTreeIDs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[int64]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistingTreeIDs indicates an expected call of ExistingTreeIDs
+func (mr *MockAdminTXMockRecorder) ExistingTreeIDs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistingTreeIDs", reflect.TypeOf((*MockAdminTX)(nil).ExistingTreeIDs), arg0, arg1, arg2)
+}
+
 // GetTree mocks base method
 func (m *MockAdminTX) GetTree(arg0 context.Context, arg1 int64) (*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "GetTree", arg0, arg1)
@@ -146,6 +186,45 @@ func (mr *MockAdminTXMockRecorder) GetTree(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockAdminTX)(nil).GetTree), arg0, arg1)
 }
 
+// GetTreeByExternalRef mocks base method
+func (m *MockAdminTX) GetTreeByExternalRef(arg0 context.Context, arg1 string, arg2 bool) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "GetTreeByExternalRef", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeByExternalRef indicates an expected call of GetTreeByExternalRef
+func (mr *MockAdminTXMockRecorder) GetTreeByExternalRef(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeByExternalRef", reflect.TypeOf((*MockAdminTX)(nil).GetTreeByExternalRef), arg0, arg1, arg2)
+}
+
+// GetTreeKeyInfo mocks base method
+func (m *MockAdminTX) GetTreeKeyInfo(arg0 context.Context, arg1 int64) (*KeyInfo, error) {
+	ret := m.ctrl.Call(m, "GetTreeKeyInfo", arg0, arg1)
+	ret0, _ := ret[0].(*KeyInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeKeyInfo indicates an expected call of GetTreeKeyInfo
+func (mr *MockAdminTXMockRecorder) GetTreeKeyInfo(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeKeyInfo", reflect.TypeOf((*MockAdminTX)(nil).GetTreeKeyInfo), arg0, arg1)
+}
+
+// GetTreesByDisplayNames mocks base method
+func (m *MockAdminTX) GetTreesByDisplayNames(arg0 context.Context, arg1 []string, arg2 bool) (map[string]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "GetTreesByDisplayNames", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreesByDisplayNames indicates an expected call of GetTreesByDisplayNames
+func (mr *MockAdminTXMockRecorder) GetTreesByDisplayNames(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreesByDisplayNames", reflect.TypeOf((*MockAdminTX)(nil).GetTreesByDisplayNames), arg0, arg1, arg2)
+}
+
 // HardDeleteTree mocks base method
 func (m *MockAdminTX) HardDeleteTree(arg0 context.Context, arg1 int64) error {
 	ret := m.ctrl.Call(m, "HardDeleteTree", arg0, arg1)
@@ -158,6 +237,19 @@ func (mr *MockAdminTXMockRecorder) HardDeleteTree(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteTree", reflect.TypeOf((*MockAdminTX)(nil).HardDeleteTree), arg0, arg1)
 }
 
+// ImportTree mocks base method
+func (m *MockAdminTX) ImportTree(arg0 context.Context, arg1 *trillian.Tree) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ImportTree", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportTree indicates an expected call of ImportTree
+func (mr *MockAdminTXMockRecorder) ImportTree(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportTree", reflect.TypeOf((*MockAdminTX)(nil).ImportTree), arg0, arg1)
+}
+
 // IsClosed mocks base method
 func (m *MockAdminTX) IsClosed() bool {
 	ret := m.ctrl.Call(m, "IsClosed")
@@ -170,6 +262,58 @@ func (mr *MockAdminTXMockRecorder) IsClosed() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsClosed", reflect.TypeOf((*MockAdminTX)(nil).IsClosed))
 }
 
+// ListHardDeletableTrees mocks base method
+func (m *MockAdminTX) ListHardDeletableTrees(arg0 context.Context, arg1 time.Duration, arg2 time.Time) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ListHardDeletableTrees", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHardDeletableTrees indicates an expected call of ListHardDeletableTrees
+func (mr *MockAdminTXMockRecorder) ListHardDeletableTrees(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHardDeletableTrees", reflect.TypeOf((*MockAdminTX)(nil).ListHardDeletableTrees), arg0, arg1, arg2)
+}
+
+// ListRecentTrees mocks base method
+func (m *MockAdminTX) ListRecentTrees(arg0 context.Context, arg1 int, arg2 bool) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ListRecentTrees", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentTrees indicates an expected call of ListRecentTrees
+func (mr *MockAdminTXMockRecorder) ListRecentTrees(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentTrees", reflect.TypeOf((*MockAdminTX)(nil).ListRecentTrees), arg0, arg1, arg2)
+}
+
+// ListSequenceableTreeIDs mocks base method
+func (m *MockAdminTX) ListSequenceableTreeIDs(arg0 context.Context) ([]int64, error) {
+	ret := m.ctrl.Call(m, "ListSequenceableTreeIDs", arg0)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSequenceableTreeIDs indicates an expected call of ListSequenceableTreeIDs
+func (mr *MockAdminTXMockRecorder) ListSequenceableTreeIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSequenceableTreeIDs", reflect.TypeOf((*MockAdminTX)(nil).ListSequenceableTreeIDs), arg0)
+}
+
+// ListTreeCountSnapshots mocks base method
+func (m *MockAdminTX) ListTreeCountSnapshots(arg0 context.Context, arg1 time.Time, arg2 time.Time) ([]CountSnapshot, error) {
+	ret := m.ctrl.Call(m, "ListTreeCountSnapshots", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]CountSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTreeCountSnapshots indicates an expected call of ListTreeCountSnapshots
+func (mr *MockAdminTXMockRecorder) ListTreeCountSnapshots(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTreeCountSnapshots", reflect.TypeOf((*MockAdminTX)(nil).ListTreeCountSnapshots), arg0, arg1, arg2)
+}
+
 // ListTreeIDs mocks base method
 func (m *MockAdminTX) ListTreeIDs(arg0 context.Context, arg1 bool) ([]int64, error) {
 	ret := m.ctrl.Call(m, "ListTreeIDs", arg0, arg1)
@@ -183,6 +327,19 @@ func (mr *MockAdminTXMockRecorder) ListTreeIDs(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTreeIDs", reflect.TypeOf((*MockAdminTX)(nil).ListTreeIDs), arg0, arg1)
 }
 
+// ListTreeRevisions mocks base method
+func (m *MockAdminTX) ListTreeRevisions(arg0 context.Context, arg1 bool) (map[int64]int64, error) {
+	ret := m.ctrl.Call(m, "ListTreeRevisions", arg0, arg1)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTreeRevisions indicates an expected call of ListTreeRevisions
+func (mr *MockAdminTXMockRecorder) ListTreeRevisions(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTreeRevisions", reflect.TypeOf((*MockAdminTX)(nil).ListTreeRevisions), arg0, arg1)
+}
+
 // ListTrees mocks base method
 func (m *MockAdminTX) ListTrees(arg0 context.Context, arg1 bool) ([]*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "ListTrees", arg0, arg1)
@@ -196,6 +353,109 @@ func (mr *MockAdminTXMockRecorder) ListTrees(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrees", reflect.TypeOf((*MockAdminTX)(nil).ListTrees), arg0, arg1)
 }
 
+// ListTreesForDisplay mocks base method
+func (m *MockAdminTX) ListTreesForDisplay(arg0 context.Context, arg1 bool) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ListTreesForDisplay", arg0, arg1)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTreesForDisplay indicates an expected call of ListTreesForDisplay
+func (mr *MockAdminTXMockRecorder) ListTreesForDisplay(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTreesForDisplay", reflect.TypeOf((*MockAdminTX)(nil).ListTreesForDisplay), arg0, arg1)
+}
+
+// ListUntouchedTrees mocks base method
+func (m *MockAdminTX) ListUntouchedTrees(arg0 context.Context, arg1 bool) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ListUntouchedTrees", arg0, arg1)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUntouchedTrees indicates an expected call of ListUntouchedTrees
+func (mr *MockAdminTXMockRecorder) ListUntouchedTrees(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUntouchedTrees", reflect.TypeOf((*MockAdminTX)(nil).ListUntouchedTrees), arg0, arg1)
+}
+
+// PauseSequencing mocks base method
+func (m *MockAdminTX) PauseSequencing(arg0 context.Context, arg1 int64) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "PauseSequencing", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseSequencing indicates an expected call of PauseSequencing
+func (mr *MockAdminTXMockRecorder) PauseSequencing(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseSequencing", reflect.TypeOf((*MockAdminTX)(nil).PauseSequencing), arg0, arg1)
+}
+
+// RecordSequencingProgress mocks base method
+func (m *MockAdminTX) RecordSequencingProgress(arg0 context.Context, arg1 int64, arg2 *timestamp.Timestamp) error {
+	ret := m.ctrl.Call(m, "RecordSequencingProgress", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSequencingProgress indicates an expected call of RecordSequencingProgress
+func (mr *MockAdminTXMockRecorder) RecordSequencingProgress(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSequencingProgress", reflect.TypeOf((*MockAdminTX)(nil).RecordSequencingProgress), arg0, arg1, arg2)
+}
+
+// ReplaceTree mocks base method
+func (m *MockAdminTX) ReplaceTree(arg0 context.Context, arg1 *trillian.Tree, arg2 int64) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ReplaceTree", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceTree indicates an expected call of ReplaceTree
+func (mr *MockAdminTXMockRecorder) ReplaceTree(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceTree", reflect.TypeOf((*MockAdminTX)(nil).ReplaceTree), arg0, arg1, arg2)
+}
+
+// ReserveTreeID mocks base method
+func (m *MockAdminTX) ReserveTreeID(arg0 context.Context) (int64, error) {
+	ret := m.ctrl.Call(m, "ReserveTreeID", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveTreeID indicates an expected call of ReserveTreeID
+func (mr *MockAdminTXMockRecorder) ReserveTreeID(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveTreeID", reflect.TypeOf((*MockAdminTX)(nil).ReserveTreeID), arg0)
+}
+
+// ResolveTreeAlias mocks base method
+func (m *MockAdminTX) ResolveTreeAlias(arg0 context.Context, arg1 string) (int64, error) {
+	ret := m.ctrl.Call(m, "ResolveTreeAlias", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveTreeAlias indicates an expected call of ResolveTreeAlias
+func (mr *MockAdminTXMockRecorder) ResolveTreeAlias(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveTreeAlias", reflect.TypeOf((*MockAdminTX)(nil).ResolveTreeAlias), arg0, arg1)
+}
+
+// ResumeSequencing mocks base method
+func (m *MockAdminTX) ResumeSequencing(arg0 context.Context, arg1 int64) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ResumeSequencing", arg0, arg1)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeSequencing indicates an expected call of ResumeSequencing
+func (mr *MockAdminTXMockRecorder) ResumeSequencing(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSequencing", reflect.TypeOf((*MockAdminTX)(nil).ResumeSequencing), arg0, arg1)
+}
+
 // Rollback mocks base method
 func (m *MockAdminTX) Rollback() error {
 	ret := m.ctrl.Call(m, "Rollback")
@@ -208,6 +468,31 @@ func (mr *MockAdminTXMockRecorder) Rollback() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockAdminTX)(nil).Rollback))
 }
 
+// SetTreeAlias mocks base method
+func (m *MockAdminTX) SetTreeAlias(arg0 context.Context, arg1 string, arg2 int64) error {
+	ret := m.ctrl.Call(m, "SetTreeAlias", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTreeAlias indicates an expected call of SetTreeAlias
+func (mr *MockAdminTXMockRecorder) SetTreeAlias(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTreeAlias", reflect.TypeOf((*MockAdminTX)(nil).SetTreeAlias), arg0, arg1, arg2)
+}
+
+// SetTreeLabels mocks base method
+func (m *MockAdminTX) SetTreeLabels(arg0 context.Context, arg1 int64, arg2 []string) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "SetTreeLabels", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTreeLabels indicates an expected call of SetTreeLabels
+func (mr *MockAdminTXMockRecorder) SetTreeLabels(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTreeLabels", reflect.TypeOf((*MockAdminTX)(nil).SetTreeLabels), arg0, arg1, arg2)
+}
+
 // SoftDeleteTree mocks base method
 func (m *MockAdminTX) SoftDeleteTree(arg0 context.Context, arg1 int64) (*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "SoftDeleteTree", arg0, arg1)
@@ -221,6 +506,57 @@ func (mr *MockAdminTXMockRecorder) SoftDeleteTree(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteTree", reflect.TypeOf((*MockAdminTX)(nil).SoftDeleteTree), arg0, arg1)
 }
 
+// SoftDeleteTreeIfUnchanged mocks base method
+func (m *MockAdminTX) SoftDeleteTreeIfUnchanged(arg0 context.Context, arg1 int64, arg2 int64) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "SoftDeleteTreeIfUnchanged", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteTreeIfUnchanged indicates an expected call of SoftDeleteTreeIfUnchanged
+func (mr *MockAdminTXMockRecorder) SoftDeleteTreeIfUnchanged(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteTreeIfUnchanged", reflect.TypeOf((*MockAdminTX)(nil).SoftDeleteTreeIfUnchanged), arg0, arg1, arg2)
+}
+
+// StreamTreeConfigHashes mocks base method
+func (m *MockAdminTX) StreamTreeConfigHashes(arg0 context.Context, arg1 bool) (<-chan TreeHash, <-chan error) {
+	ret := m.ctrl.Call(m, "StreamTreeConfigHashes", arg0, arg1)
+	ret0, _ := ret[0].(<-chan TreeHash)
+	ret1, _ := ret[1].(<-chan error)
+	return ret0, ret1
+}
+
+// StreamTreeConfigHashes indicates an expected call of StreamTreeConfigHashes
+func (mr *MockAdminTXMockRecorder) StreamTreeConfigHashes(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTreeConfigHashes", reflect.TypeOf((*MockAdminTX)(nil).StreamTreeConfigHashes), arg0, arg1)
+}
+
+// TouchTrees mocks base method
+func (m *MockAdminTX) TouchTrees(arg0 context.Context, arg1 []int64) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "TouchTrees", arg0, arg1)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TouchTrees indicates an expected call of TouchTrees
+func (mr *MockAdminTXMockRecorder) TouchTrees(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchTrees", reflect.TypeOf((*MockAdminTX)(nil).TouchTrees), arg0, arg1)
+}
+
+// TransferLabels mocks base method
+func (m *MockAdminTX) TransferLabels(arg0 context.Context, arg1 int64, arg2 int64, arg3 []string) error {
+	ret := m.ctrl.Call(m, "TransferLabels", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferLabels indicates an expected call of TransferLabels
+func (mr *MockAdminTXMockRecorder) TransferLabels(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferLabels", reflect.TypeOf((*MockAdminTX)(nil).TransferLabels), arg0, arg1, arg2, arg3)
+}
+
 // UndeleteTree mocks base method
 func (m *MockAdminTX) UndeleteTree(arg0 context.Context, arg1 int64) (*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "UndeleteTree", arg0, arg1)
@@ -247,6 +583,31 @@ func (mr *MockAdminTXMockRecorder) UpdateTree(arg0, arg1, arg2 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTree", reflect.TypeOf((*MockAdminTX)(nil).UpdateTree), arg0, arg1, arg2)
 }
 
+// UpdateTrees mocks base method
+func (m *MockAdminTX) UpdateTrees(arg0 context.Context, arg1 map[int64]func(*trillian.Tree)) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "UpdateTrees", arg0, arg1)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTrees indicates an expected call of UpdateTrees
+func (mr *MockAdminTXMockRecorder) UpdateTrees(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTrees", reflect.TypeOf((*MockAdminTX)(nil).UpdateTrees), arg0, arg1)
+}
+
+// WriteTreeCountSnapshot mocks base method
+func (m *MockAdminTX) WriteTreeCountSnapshot(arg0 context.Context, arg1 *CountSnapshot) error {
+	ret := m.ctrl.Call(m, "WriteTreeCountSnapshot", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteTreeCountSnapshot indicates an expected call of WriteTreeCountSnapshot
+func (mr *MockAdminTXMockRecorder) WriteTreeCountSnapshot(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTreeCountSnapshot", reflect.TypeOf((*MockAdminTX)(nil).WriteTreeCountSnapshot), arg0, arg1)
+}
+
 // MockLogStorage is a mock of LogStorage interface
 type MockLogStorage struct {
 	ctrl     *gomock.Controller
@@ -407,6 +768,19 @@ func (mr *MockLogTreeTXMockRecorder) GetLeavesByIndex(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByIndex", reflect.TypeOf((*MockLogTreeTX)(nil).GetLeavesByIndex), arg0, arg1)
 }
 
+// GetLeavesByRange mocks base method
+func (m *MockLogTreeTX) GetLeavesByRange(arg0 context.Context, arg1 int64, arg2 int64) ([]*trillian.LogLeaf, error) {
+	ret := m.ctrl.Call(m, "GetLeavesByRange", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*trillian.LogLeaf)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeavesByRange indicates an expected call of GetLeavesByRange
+func (mr *MockLogTreeTXMockRecorder) GetLeavesByRange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByRange", reflect.TypeOf((*MockLogTreeTX)(nil).GetLeavesByRange), arg0, arg1, arg2)
+}
+
 // GetMerkleNodes mocks base method
 func (m *MockLogTreeTX) GetMerkleNodes(arg0 context.Context, arg1 int64, arg2 []NodeID) ([]Node, error) {
 	ret := m.ctrl.Call(m, "GetMerkleNodes", arg0, arg1, arg2)
@@ -483,6 +857,19 @@ func (mr *MockLogTreeTXMockRecorder) ReadRevision() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRevision", reflect.TypeOf((*MockLogTreeTX)(nil).ReadRevision))
 }
 
+// RequeueLeaves mocks base method
+func (m *MockLogTreeTX) RequeueLeaves(arg0 context.Context, arg1 [][]byte) (int, error) {
+	ret := m.ctrl.Call(m, "RequeueLeaves", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueLeaves indicates an expected call of RequeueLeaves
+func (mr *MockLogTreeTXMockRecorder) RequeueLeaves(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueLeaves", reflect.TypeOf((*MockLogTreeTX)(nil).RequeueLeaves), arg0, arg1)
+}
+
 // Rollback mocks base method
 func (m *MockLogTreeTX) Rollback() error {
 	ret := m.ctrl.Call(m, "Rollback")
@@ -677,6 +1064,19 @@ func (mr *MockMapTreeTXMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMapTreeTX)(nil).Get), arg0, arg1, arg2)
 }
 
+// GetMapLeavesWithProof mocks base method
+func (m *MockMapTreeTX) GetMapLeavesWithProof(arg0 context.Context, arg1 int64, arg2 [][]byte) ([]*trillian.MapLeafInclusion, error) {
+	ret := m.ctrl.Call(m, "GetMapLeavesWithProof", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*trillian.MapLeafInclusion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMapLeavesWithProof indicates an expected call of GetMapLeavesWithProof
+func (mr *MockMapTreeTXMockRecorder) GetMapLeavesWithProof(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMapLeavesWithProof", reflect.TypeOf((*MockMapTreeTX)(nil).GetMapLeavesWithProof), arg0, arg1, arg2)
+}
+
 // GetMerkleNodes mocks base method
 func (m *MockMapTreeTX) GetMerkleNodes(arg0 context.Context, arg1 int64, arg2 []NodeID) ([]Node, error) {
 	ret := m.ctrl.Call(m, "GetMerkleNodes", arg0, arg1, arg2)
@@ -847,6 +1247,32 @@ func (mr *MockReadOnlyAdminTXMockRecorder) Commit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).Commit))
 }
 
+// CountTreesByLabel mocks base method
+func (m *MockReadOnlyAdminTX) CountTreesByLabel(arg0 context.Context, arg1 bool) (map[string]int64, error) {
+	ret := m.ctrl.Call(m, "CountTreesByLabel", arg0, arg1)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTreesByLabel indicates an expected call of CountTreesByLabel
+func (mr *MockReadOnlyAdminTXMockRecorder) CountTreesByLabel(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTreesByLabel", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).CountTreesByLabel), arg0, arg1)
+}
+
+// ExistingTreeIDs mocks base method
+func (m *MockReadOnlyAdminTX) ExistingTreeIDs(arg0 context.Context, arg1 []int64, arg2 bool) (map[int64]bool, error) {
+	ret := m.ctrl.Call(m, "ExistingTreeIDs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[int64]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistingTreeIDs indicates an expected call of ExistingTreeIDs
+func (mr *MockReadOnlyAdminTXMockRecorder) ExistingTreeIDs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistingTreeIDs", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ExistingTreeIDs), arg0, arg1, arg2)
+}
+
 // GetTree mocks base method
 func (m *MockReadOnlyAdminTX) GetTree(arg0 context.Context, arg1 int64) (*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "GetTree", arg0, arg1)
@@ -860,6 +1286,45 @@ func (mr *MockReadOnlyAdminTXMockRecorder) GetTree(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).GetTree), arg0, arg1)
 }
 
+// GetTreeByExternalRef mocks base method
+func (m *MockReadOnlyAdminTX) GetTreeByExternalRef(arg0 context.Context, arg1 string, arg2 bool) (*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "GetTreeByExternalRef", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeByExternalRef indicates an expected call of GetTreeByExternalRef
+func (mr *MockReadOnlyAdminTXMockRecorder) GetTreeByExternalRef(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeByExternalRef", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).GetTreeByExternalRef), arg0, arg1, arg2)
+}
+
+// GetTreeKeyInfo mocks base method
+func (m *MockReadOnlyAdminTX) GetTreeKeyInfo(arg0 context.Context, arg1 int64) (*KeyInfo, error) {
+	ret := m.ctrl.Call(m, "GetTreeKeyInfo", arg0, arg1)
+	ret0, _ := ret[0].(*KeyInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeKeyInfo indicates an expected call of GetTreeKeyInfo
+func (mr *MockReadOnlyAdminTXMockRecorder) GetTreeKeyInfo(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeKeyInfo", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).GetTreeKeyInfo), arg0, arg1)
+}
+
+// GetTreesByDisplayNames mocks base method
+func (m *MockReadOnlyAdminTX) GetTreesByDisplayNames(arg0 context.Context, arg1 []string, arg2 bool) (map[string]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "GetTreesByDisplayNames", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreesByDisplayNames indicates an expected call of GetTreesByDisplayNames
+func (mr *MockReadOnlyAdminTXMockRecorder) GetTreesByDisplayNames(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreesByDisplayNames", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).GetTreesByDisplayNames), arg0, arg1, arg2)
+}
+
 // IsClosed mocks base method
 func (m *MockReadOnlyAdminTX) IsClosed() bool {
 	ret := m.ctrl.Call(m, "IsClosed")
@@ -872,6 +1337,58 @@ func (mr *MockReadOnlyAdminTXMockRecorder) IsClosed() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsClosed", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).IsClosed))
 }
 
+// ListHardDeletableTrees mocks base method
+func (m *MockReadOnlyAdminTX) ListHardDeletableTrees(arg0 context.Context, arg1 time.Duration, arg2 time.Time) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ListHardDeletableTrees", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHardDeletableTrees indicates an expected call of ListHardDeletableTrees
+func (mr *MockReadOnlyAdminTXMockRecorder) ListHardDeletableTrees(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHardDeletableTrees", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListHardDeletableTrees), arg0, arg1, arg2)
+}
+
+// ListRecentTrees mocks base method
+func (m *MockReadOnlyAdminTX) ListRecentTrees(arg0 context.Context, arg1 int, arg2 bool) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ListRecentTrees", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentTrees indicates an expected call of ListRecentTrees
+func (mr *MockReadOnlyAdminTXMockRecorder) ListRecentTrees(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentTrees", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListRecentTrees), arg0, arg1, arg2)
+}
+
+// ListSequenceableTreeIDs mocks base method
+func (m *MockReadOnlyAdminTX) ListSequenceableTreeIDs(arg0 context.Context) ([]int64, error) {
+	ret := m.ctrl.Call(m, "ListSequenceableTreeIDs", arg0)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSequenceableTreeIDs indicates an expected call of ListSequenceableTreeIDs
+func (mr *MockReadOnlyAdminTXMockRecorder) ListSequenceableTreeIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSequenceableTreeIDs", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListSequenceableTreeIDs), arg0)
+}
+
+// ListTreeCountSnapshots mocks base method
+func (m *MockReadOnlyAdminTX) ListTreeCountSnapshots(arg0 context.Context, arg1 time.Time, arg2 time.Time) ([]CountSnapshot, error) {
+	ret := m.ctrl.Call(m, "ListTreeCountSnapshots", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]CountSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTreeCountSnapshots indicates an expected call of ListTreeCountSnapshots
+func (mr *MockReadOnlyAdminTXMockRecorder) ListTreeCountSnapshots(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTreeCountSnapshots", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListTreeCountSnapshots), arg0, arg1, arg2)
+}
+
 // ListTreeIDs mocks base method
 func (m *MockReadOnlyAdminTX) ListTreeIDs(arg0 context.Context, arg1 bool) ([]int64, error) {
 	ret := m.ctrl.Call(m, "ListTreeIDs", arg0, arg1)
@@ -885,6 +1402,19 @@ func (mr *MockReadOnlyAdminTXMockRecorder) ListTreeIDs(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTreeIDs", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListTreeIDs), arg0, arg1)
 }
 
+// ListTreeRevisions mocks base method
+func (m *MockReadOnlyAdminTX) ListTreeRevisions(arg0 context.Context, arg1 bool) (map[int64]int64, error) {
+	ret := m.ctrl.Call(m, "ListTreeRevisions", arg0, arg1)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTreeRevisions indicates an expected call of ListTreeRevisions
+func (mr *MockReadOnlyAdminTXMockRecorder) ListTreeRevisions(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTreeRevisions", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListTreeRevisions), arg0, arg1)
+}
+
 // ListTrees mocks base method
 func (m *MockReadOnlyAdminTX) ListTrees(arg0 context.Context, arg1 bool) ([]*trillian.Tree, error) {
 	ret := m.ctrl.Call(m, "ListTrees", arg0, arg1)
@@ -898,6 +1428,45 @@ func (mr *MockReadOnlyAdminTXMockRecorder) ListTrees(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrees", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListTrees), arg0, arg1)
 }
 
+// ListTreesForDisplay mocks base method
+func (m *MockReadOnlyAdminTX) ListTreesForDisplay(arg0 context.Context, arg1 bool) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ListTreesForDisplay", arg0, arg1)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTreesForDisplay indicates an expected call of ListTreesForDisplay
+func (mr *MockReadOnlyAdminTXMockRecorder) ListTreesForDisplay(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTreesForDisplay", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListTreesForDisplay), arg0, arg1)
+}
+
+// ListUntouchedTrees mocks base method
+func (m *MockReadOnlyAdminTX) ListUntouchedTrees(arg0 context.Context, arg1 bool) ([]*trillian.Tree, error) {
+	ret := m.ctrl.Call(m, "ListUntouchedTrees", arg0, arg1)
+	ret0, _ := ret[0].([]*trillian.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUntouchedTrees indicates an expected call of ListUntouchedTrees
+func (mr *MockReadOnlyAdminTXMockRecorder) ListUntouchedTrees(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUntouchedTrees", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ListUntouchedTrees), arg0, arg1)
+}
+
+// ResolveTreeAlias mocks base method
+func (m *MockReadOnlyAdminTX) ResolveTreeAlias(arg0 context.Context, arg1 string) (int64, error) {
+	ret := m.ctrl.Call(m, "ResolveTreeAlias", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveTreeAlias indicates an expected call of ResolveTreeAlias
+func (mr *MockReadOnlyAdminTXMockRecorder) ResolveTreeAlias(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveTreeAlias", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).ResolveTreeAlias), arg0, arg1)
+}
+
 // Rollback mocks base method
 func (m *MockReadOnlyAdminTX) Rollback() error {
 	ret := m.ctrl.Call(m, "Rollback")
@@ -910,6 +1479,19 @@ func (mr *MockReadOnlyAdminTXMockRecorder) Rollback() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).Rollback))
 }
 
+// StreamTreeConfigHashes mocks base method
+func (m *MockReadOnlyAdminTX) StreamTreeConfigHashes(arg0 context.Context, arg1 bool) (<-chan TreeHash, <-chan error) {
+	ret := m.ctrl.Call(m, "StreamTreeConfigHashes", arg0, arg1)
+	ret0, _ := ret[0].(<-chan TreeHash)
+	ret1, _ := ret[1].(<-chan error)
+	return ret0, ret1
+}
+
+// StreamTreeConfigHashes indicates an expected call of StreamTreeConfigHashes
+func (mr *MockReadOnlyAdminTXMockRecorder) StreamTreeConfigHashes(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTreeConfigHashes", reflect.TypeOf((*MockReadOnlyAdminTX)(nil).StreamTreeConfigHashes), arg0, arg1)
+}
+
 // MockReadOnlyLogTX is a mock of ReadOnlyLogTX interface
 type MockReadOnlyLogTX struct {
 	ctrl     *gomock.Controller
@@ -957,6 +1539,19 @@ func (mr *MockReadOnlyLogTXMockRecorder) Commit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockReadOnlyLogTX)(nil).Commit))
 }
 
+// EstimateTreeStorageBytes mocks base method
+func (m *MockReadOnlyLogTX) EstimateTreeStorageBytes(arg0 context.Context, arg1 int64) (int64, error) {
+	ret := m.ctrl.Call(m, "EstimateTreeStorageBytes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateTreeStorageBytes indicates an expected call of EstimateTreeStorageBytes
+func (mr *MockReadOnlyLogTXMockRecorder) EstimateTreeStorageBytes(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateTreeStorageBytes", reflect.TypeOf((*MockReadOnlyLogTX)(nil).EstimateTreeStorageBytes), arg0, arg1)
+}
+
 // GetActiveLogIDs mocks base method
 func (m *MockReadOnlyLogTX) GetActiveLogIDs(arg0 context.Context) ([]int64, error) {
 	ret := m.ctrl.Call(m, "GetActiveLogIDs", arg0)
@@ -970,6 +1565,19 @@ func (mr *MockReadOnlyLogTXMockRecorder) GetActiveLogIDs(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveLogIDs", reflect.TypeOf((*MockReadOnlyLogTX)(nil).GetActiveLogIDs), arg0)
 }
 
+// GetLatestSignedLogRoots mocks base method
+func (m *MockReadOnlyLogTX) GetLatestSignedLogRoots(arg0 context.Context, arg1 []int64) (map[int64]*trillian.SignedLogRoot, error) {
+	ret := m.ctrl.Call(m, "GetLatestSignedLogRoots", arg0, arg1)
+	ret0, _ := ret[0].(map[int64]*trillian.SignedLogRoot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestSignedLogRoots indicates an expected call of GetLatestSignedLogRoots
+func (mr *MockReadOnlyLogTXMockRecorder) GetLatestSignedLogRoots(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSignedLogRoots", reflect.TypeOf((*MockReadOnlyLogTX)(nil).GetLatestSignedLogRoots), arg0, arg1)
+}
+
 // GetUnsequencedCounts mocks base method
 func (m *MockReadOnlyLogTX) GetUnsequencedCounts(arg0 context.Context) (CountByLogID, error) {
 	ret := m.ctrl.Call(m, "GetUnsequencedCounts", arg0)
@@ -1068,6 +1676,19 @@ func (mr *MockReadOnlyLogTreeTXMockRecorder) GetLeavesByIndex(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByIndex", reflect.TypeOf((*MockReadOnlyLogTreeTX)(nil).GetLeavesByIndex), arg0, arg1)
 }
 
+// GetLeavesByRange mocks base method
+func (m *MockReadOnlyLogTreeTX) GetLeavesByRange(arg0 context.Context, arg1 int64, arg2 int64) ([]*trillian.LogLeaf, error) {
+	ret := m.ctrl.Call(m, "GetLeavesByRange", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*trillian.LogLeaf)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeavesByRange indicates an expected call of GetLeavesByRange
+func (mr *MockReadOnlyLogTreeTXMockRecorder) GetLeavesByRange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeavesByRange", reflect.TypeOf((*MockReadOnlyLogTreeTX)(nil).GetLeavesByRange), arg0, arg1, arg2)
+}
+
 // GetMerkleNodes mocks base method
 func (m *MockReadOnlyLogTreeTX) GetMerkleNodes(arg0 context.Context, arg1 int64, arg2 []NodeID) ([]Node, error) {
 	ret := m.ctrl.Call(m, "GetMerkleNodes", arg0, arg1, arg2)
@@ -1203,6 +1824,19 @@ func (mr *MockReadOnlyMapTreeTXMockRecorder) Get(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockReadOnlyMapTreeTX)(nil).Get), arg0, arg1, arg2)
 }
 
+// GetMapLeavesWithProof mocks base method
+func (m *MockReadOnlyMapTreeTX) GetMapLeavesWithProof(arg0 context.Context, arg1 int64, arg2 [][]byte) ([]*trillian.MapLeafInclusion, error) {
+	ret := m.ctrl.Call(m, "GetMapLeavesWithProof", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*trillian.MapLeafInclusion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMapLeavesWithProof indicates an expected call of GetMapLeavesWithProof
+func (mr *MockReadOnlyMapTreeTXMockRecorder) GetMapLeavesWithProof(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMapLeavesWithProof", reflect.TypeOf((*MockReadOnlyMapTreeTX)(nil).GetMapLeavesWithProof), arg0, arg1, arg2)
+}
+
 // GetMerkleNodes mocks base method
 func (m *MockReadOnlyMapTreeTX) GetMerkleNodes(arg0 context.Context, arg1 int64, arg2 []NodeID) ([]Node, error) {
 	ret := m.ctrl.Call(m, "GetMerkleNodes", arg0, arg1, arg2)
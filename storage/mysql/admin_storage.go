@@ -15,9 +15,11 @@
 package mysql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -25,6 +27,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keyspb"
 	spb "github.com/google/trillian/crypto/sigpb"
@@ -40,6 +43,34 @@ const (
 	selectTreeIDs           = "SELECT TreeId FROM Trees"
 	selectNonDeletedTreeIDs = selectTreeIDs + nonDeletedWhere
 
+	selectLabelCountsFrom = `
+		SELECT TreeLabels.Label, COUNT(*) FROM TreeLabels
+		JOIN Trees ON Trees.TreeId = TreeLabels.TreeId`
+	selectLabelCounts           = selectLabelCountsFrom + " GROUP BY TreeLabels.Label"
+	selectNonDeletedLabelCounts = selectLabelCountsFrom + nonDeletedWhere + " GROUP BY TreeLabels.Label"
+
+	selectTreeRevisions           = "SELECT TreeId, Revision FROM Trees"
+	selectNonDeletedTreeRevisions = selectTreeRevisions + nonDeletedWhere
+
+	selectTreeIDsIn           = selectTreeIDs + " WHERE TreeId IN (" + placeholderSQL + ")"
+	selectNonDeletedTreeIDsIn = selectNonDeletedTreeIDs + " AND TreeId IN (" + placeholderSQL + ")"
+
+	selectSequenceableTreeIDs = selectNonDeletedTreeIDs + " AND TreeType IN (?, ?) AND TreeState IN (?, ?) AND NOT SequencingPaused"
+
+	// DisplayName is NULL for trees without a display name.
+	selectTreesByDisplayNames           = selectTrees + " WHERE COALESCE(DisplayName, '') IN (" + placeholderSQL + ") ORDER BY TreeId"
+	selectNonDeletedTreesByDisplayNames = selectNonDeletedTrees + " AND COALESCE(DisplayName, '') IN (" + placeholderSQL + ") ORDER BY TreeId"
+
+	selectTreesByExternalRef           = selectTrees + " WHERE ExternalRef = ? ORDER BY TreeId"
+	selectNonDeletedTreesByExternalRef = selectNonDeletedTrees + " AND ExternalRef = ? ORDER BY TreeId"
+
+	recentTreesOrder            = " ORDER BY CreateTimeMillis DESC, TreeId DESC LIMIT ?"
+	selectRecentTrees           = selectTrees + recentTreesOrder
+	selectNonDeletedRecentTrees = selectNonDeletedTrees + recentTreesOrder
+
+	selectUntouchedTrees           = selectTrees + " WHERE CreateTimeMillis = UpdateTimeMillis ORDER BY TreeId"
+	selectNonDeletedUntouchedTrees = selectNonDeletedTrees + " AND CreateTimeMillis = UpdateTimeMillis ORDER BY TreeId"
+
 	selectTrees = `
 		SELECT
 			TreeId,
@@ -56,20 +87,84 @@ const (
 			PublicKey,
 			MaxRootDurationMillis,
 			Deleted,
-			DeleteTimeMillis
+			DeleteTimeMillis,
+			WritesDisabled,
+			LastSequencedTimeMillis,
+			SignatureParams,
+			SequencingPaused,
+			ExternalRef,
+			PreferredReadConsistency,
+			ClonedFrom,
+			Revision
 		FROM Trees`
 	selectNonDeletedTrees = selectTrees + nonDeletedWhere
 	selectTreeByID        = selectTrees + " WHERE TreeId = ?"
+
+	selectPublicKeyByID = "SELECT PublicKey FROM Trees WHERE TreeId = ?"
+
+	selectHardDeletableTrees = selectTrees + `
+		WHERE Deleted = TRUE AND DeleteTimeMillis IS NOT NULL AND DeleteTimeMillis < ?
+		ORDER BY DeleteTimeMillis, TreeId`
+
+	selectReservationByID = "SELECT ExpiryTimeMillis FROM TreeIdReservations WHERE TreeId = ?"
+
+	// repairMissingDeleteTimes and repairStaleDeleteTimes fix trees whose
+	// Deleted flag and DeleteTimeMillis disagree, as per
+	// storage.RepairDeletedConsistency.
+	repairMissingDeleteTimes = `
+		UPDATE Trees SET DeleteTimeMillis = UpdateTimeMillis, Revision = Revision + 1
+		WHERE Deleted = TRUE AND DeleteTimeMillis IS NULL`
+	repairStaleDeleteTimes = `
+		UPDATE Trees SET DeleteTimeMillis = NULL, Revision = Revision + 1
+		WHERE (Deleted IS NULL OR Deleted = FALSE) AND DeleteTimeMillis IS NOT NULL`
+
+	selectTreeIDByAlias = "SELECT TreeId FROM TreeAliases WHERE Alias = ?"
+	insertTreeAlias     = "INSERT INTO TreeAliases(Alias, TreeId) VALUES(?, ?)"
+
+	insertTreeCountSnapshot = `
+		INSERT INTO TreeCountSnapshots(SnapshotTimeMillis, ActiveTrees, DeletedTrees)
+		VALUES(?, ?, ?)`
+	updateTreeCountSnapshot = `
+		UPDATE TreeCountSnapshots SET ActiveTrees = ?, DeletedTrees = ?
+		WHERE SnapshotTimeMillis = ?`
+
+	selectTreeCountSnapshots = `
+		SELECT SnapshotTimeMillis, ActiveTrees, DeletedTrees
+		FROM TreeCountSnapshots
+		WHERE SnapshotTimeMillis >= ? AND SnapshotTimeMillis < ?
+		ORDER BY SnapshotTimeMillis`
+
+	selectLabelsOfTrees  = "SELECT TreeId, Label FROM TreeLabels WHERE TreeId IN (" + placeholderSQL + ") ORDER BY TreeId, LabelIndex"
+	selectAppDataOfTrees = "SELECT TreeId, AppDataKey, AppDataValue FROM TreeAppData WHERE TreeId IN (" + placeholderSQL + ")"
+
+	// maxTreeIDAttempts is the number of IDs generated before giving up on
+	// finding one that isn't in use.
+	maxTreeIDAttempts = 10
+
+	// treeIDsPerQuery is the maximum number of tree IDs bound to a single
+	// "TreeId IN (...)" query, to stay within the bind variable limits of
+	// the database.
+	treeIDsPerQuery = 500
 )
 
+// newTreeID generates tree IDs. It's a variable so tests may replace it.
+var newTreeID = storage.NewTreeID
+
 // NewAdminStorage returns a MySQL storage.AdminStorage implementation backed by DB.
 func NewAdminStorage(db *sql.DB) storage.AdminStorage {
-	return &mysqlAdminStorage{db}
+	return NewAdminStorageWithOptions(db, storage.AdminStorageOptions{})
+}
+
+// NewAdminStorageWithOptions returns a MySQL storage.AdminStorage
+// implementation backed by DB and configured by opts.
+func NewAdminStorageWithOptions(db *sql.DB, opts storage.AdminStorageOptions) storage.AdminStorage {
+	return &mysqlAdminStorage{db: db, opts: opts}
 }
 
 // mysqlAdminStorage implements storage.AdminStorage
 type mysqlAdminStorage struct {
-	db *sql.DB
+	db   *sql.DB
+	opts storage.AdminStorageOptions
 }
 
 func (s *mysqlAdminStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
@@ -81,28 +176,73 @@ func (s *mysqlAdminStorage) Begin(ctx context.Context) (storage.AdminTX, error)
 	if err != nil {
 		return nil, err
 	}
-	return &adminTX{tx: tx}, nil
+	return &adminTX{tx: tx, opts: s.opts, acceptingWrites: storage.AcceptingWrites()}, nil
 }
 
 func (s *mysqlAdminStorage) CheckDatabaseAccessible(ctx context.Context) error {
 	return checkDatabaseAccessible(ctx, s.db)
 }
 
-type adminTX struct {
-	tx *sql.Tx
+// RepairDeletedConsistency implements storage.DeletedConsistencyRepairer.
+func (s *mysqlAdminStorage) RepairDeletedConsistency(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil /* opts */)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
-	// mu guards *direct* reads/writes on closed, which happen only on
-	// Commit/Rollback/IsClosed/Close methods.
-	// We don't check closed on *all* methods (apart from the ones above),
-	// as we trust tx to keep tabs on its state (and consequently fail to do
-	// queries after closed).
+	repaired := int64(0)
+	for _, repair := range []string{repairMissingDeleteTimes, repairStaleDeleteTimes} {
+		res, err := tx.ExecContext(ctx, repair)
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		repaired += n
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(repaired), nil
+}
+
+type adminTX struct {
+	tx   *sql.Tx
+	opts storage.AdminStorageOptions
+	// acceptingWrites is the value of storage.AcceptingWrites() when the
+	// transaction began.
+	acceptingWrites bool
+
+	// mu guards *direct* reads/writes on closed.
+	// All operations check closed before touching tx, so that use of a
+	// committed or rolled back transaction consistently fails with
+	// FailedPrecondition.
 	mu     sync.RWMutex
 	closed bool
 }
 
+// checkOpen returns a FailedPrecondition error if t is already closed.
+func (t *adminTX) checkOpen() error {
+	if t.IsClosed() {
+		return errors.New(errors.FailedPrecondition, "transaction already closed")
+	}
+	return nil
+}
+
+// now returns the current time, as per the TimeSource of t.opts.
+func (t *adminTX) now() time.Time {
+	return storage.TimeSourceFor(t.opts).Now()
+}
+
 func (t *adminTX) Commit() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if t.closed {
+		return errors.New(errors.FailedPrecondition, "transaction already closed")
+	}
 	t.closed = true
 	return t.tx.Commit()
 }
@@ -110,6 +250,9 @@ func (t *adminTX) Commit() error {
 func (t *adminTX) Rollback() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if t.closed {
+		return errors.New(errors.FailedPrecondition, "transaction already closed")
+	}
 	t.closed = true
 	return t.tx.Rollback()
 }
@@ -137,6 +280,81 @@ func (t *adminTX) Close() error {
 }
 
 func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTreeID(treeID); err != nil {
+		return nil, err
+	}
+	tree, err := t.getTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	if t.opts.VerifyConfigChecksumOnRead {
+		if err := t.verifyConfigChecksum(ctx, tree); err != nil {
+			return nil, err
+		}
+	}
+	storage.DecorateTree(ctx, tree)
+	storage.RedactTree(ctx, t.opts, tree)
+	return tree, nil
+}
+
+// verifyConfigChecksum returns a DataLoss error if the stored checksum of
+// tree doesn't match its config. tree must be as stored, i.e., neither
+// decorated nor redacted.
+func (t *adminTX) verifyConfigChecksum(ctx context.Context, tree *trillian.Tree) error {
+	var checksum []byte
+	if err := t.tx.QueryRowContext(ctx, "SELECT ConfigChecksum FROM Trees WHERE TreeId = ?", tree.TreeId).Scan(&checksum); err != nil {
+		return err
+	}
+	if checksum == nil {
+		return nil
+	}
+	// Checksums written before TreeConfigHashVersion changed use the previous
+	// version; they're replaced on the tree's next config write.
+	for _, version := range []int{storage.TreeConfigHashVersion, storage.CanonicalTreeBytesV1} {
+		hash, err := storage.TreeConfigHashWithVersion(tree, version)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(hash, checksum) {
+			return nil
+		}
+	}
+	return errors.Errorf(errors.DataLoss, "tree %v: config doesn't match stored checksum, storage may be corrupt", tree.TreeId)
+}
+
+// writeConfigChecksum stores the TreeConfigHash of treeID, as read back from
+// storage. It must be called whenever a tree's config is written.
+func (t *adminTX) writeConfigChecksum(ctx context.Context, treeID int64) error {
+	tree, err := t.getTree(ctx, treeID)
+	if err != nil {
+		return err
+	}
+	hash, err := storage.TreeConfigHash(tree)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.ExecContext(ctx, "UPDATE Trees SET ConfigChecksum = ? WHERE TreeId = ?", hash, treeID)
+	return err
+}
+
+// redactedTree returns the tree corresponding to treeID, as per getTree,
+// redacted as per storage.RedactTree. It's meant for trees returned by
+// writes, which aren't decorated.
+func (t *adminTX) redactedTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	tree, err := t.getTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	storage.RedactTree(ctx, t.opts, tree)
+	return tree, nil
+}
+
+// getTree returns the tree corresponding to treeID, as stored (i.e., without
+// applying ReadDecorators).
+func (t *adminTX) getTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
 	stmt, err := t.tx.PrepareContext(ctx, selectTreeByID)
 	if err != nil {
 		return nil, err
@@ -152,9 +370,152 @@ func (t *adminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, er
 	case err != nil:
 		return nil, fmt.Errorf("error reading tree %v: %v", treeID, err)
 	}
+	if tree.Labels, err = t.readLabels(ctx, treeID); err != nil {
+		return nil, fmt.Errorf("error reading labels of tree %v: %v", treeID, err)
+	}
+	if tree.AppData, err = t.readAppData(ctx, treeID); err != nil {
+		return nil, fmt.Errorf("error reading app data of tree %v: %v", treeID, err)
+	}
 	return tree, nil
 }
 
+// readLabels returns the labels of treeID, in the order they were set.
+func (t *adminTX) readLabels(ctx context.Context, treeID int64) ([]string, error) {
+	rows, err := t.tx.QueryContext(ctx, "SELECT Label FROM TreeLabels WHERE TreeId = ? ORDER BY LabelIndex", treeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var labels []string
+	for rows.Next() {
+		var label string
+		if err := rows.Scan(&label); err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return labels, rows.Err()
+}
+
+// readLabelsOfTrees returns the labels of treeIDs, keyed by tree ID.
+func (t *adminTX) readLabelsOfTrees(ctx context.Context, treeIDs []int64) (map[int64][]string, error) {
+	labels := make(map[int64][]string)
+	err := t.queryByTreeIDs(ctx, selectLabelsOfTrees, treeIDs, func(rows *sql.Rows) error {
+		var treeID int64
+		var label string
+		if err := rows.Scan(&treeID, &label); err != nil {
+			return err
+		}
+		labels[treeID] = append(labels[treeID], label)
+		return nil
+	})
+	return labels, err
+}
+
+// writeLabels replaces the labels of treeID with labels.
+func (t *adminTX) writeLabels(ctx context.Context, treeID int64, labels []string) error {
+	if _, err := t.tx.ExecContext(ctx, "DELETE FROM TreeLabels WHERE TreeId = ?", treeID); err != nil {
+		return err
+	}
+	for i, label := range labels {
+		if _, err := t.tx.ExecContext(
+			ctx,
+			"INSERT INTO TreeLabels(TreeId, Label, LabelIndex) VALUES(?, ?, ?)",
+			treeID, label, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readAppData returns the app data of treeID, or nil if it has none.
+func (t *adminTX) readAppData(ctx context.Context, treeID int64) (map[string]string, error) {
+	rows, err := t.tx.QueryContext(ctx, "SELECT AppDataKey, AppDataValue FROM TreeAppData WHERE TreeId = ?", treeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var appData map[string]string
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		if appData == nil {
+			appData = make(map[string]string)
+		}
+		appData[key] = value
+	}
+	return appData, rows.Err()
+}
+
+// readAppDataOfTrees returns the app data of treeIDs, keyed by tree ID.
+func (t *adminTX) readAppDataOfTrees(ctx context.Context, treeIDs []int64) (map[int64]map[string]string, error) {
+	appData := make(map[int64]map[string]string)
+	err := t.queryByTreeIDs(ctx, selectAppDataOfTrees, treeIDs, func(rows *sql.Rows) error {
+		var treeID int64
+		var key, value string
+		if err := rows.Scan(&treeID, &key, &value); err != nil {
+			return err
+		}
+		if appData[treeID] == nil {
+			appData[treeID] = make(map[string]string)
+		}
+		appData[treeID][key] = value
+		return nil
+	})
+	return appData, err
+}
+
+// queryByTreeIDs runs query, which must have a placeholderSQL taking tree
+// IDs, for treeIDs in batches of at most treeIDsPerQuery, calling scan for
+// each returned row.
+func (t *adminTX) queryByTreeIDs(ctx context.Context, query string, treeIDs []int64, scan func(*sql.Rows) error) error {
+	for len(treeIDs) > 0 {
+		batch := treeIDs
+		if len(batch) > treeIDsPerQuery {
+			batch = batch[:treeIDsPerQuery]
+		}
+		treeIDs = treeIDs[len(batch):]
+
+		args := make([]interface{}, 0, len(batch))
+		for _, treeID := range batch {
+			args = append(args, treeID)
+		}
+		rows, err := t.tx.QueryContext(ctx, expandPlaceholderSQL(query, len(args), "?", "?"), args...)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAppData replaces the app data of treeID with appData.
+func (t *adminTX) writeAppData(ctx context.Context, treeID int64, appData map[string]string) error {
+	if _, err := t.tx.ExecContext(ctx, "DELETE FROM TreeAppData WHERE TreeId = ?", treeID); err != nil {
+		return err
+	}
+	for key, value := range appData {
+		if _, err := t.tx.ExecContext(
+			ctx,
+			"INSERT INTO TreeAppData(TreeId, AppDataKey, AppDataValue) VALUES(?, ?, ?)",
+			treeID, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // There's no common interface between sql.Row and sql.Rows(!), so we have to
 // define one.
 type row interface {
@@ -167,10 +528,11 @@ func readTree(row row) (*trillian.Tree, error) {
 	// Enums and Datetimes need an extra conversion step
 	var treeState, treeType, hashStrategy, hashAlgorithm, signatureAlgorithm string
 	var createMillis, updateMillis, maxRootDurationMillis int64
-	var displayName, description sql.NullString
+	var displayName, description, externalRef, readConsistency sql.NullString
 	var privateKey, publicKey []byte
 	var deleted sql.NullBool
-	var deleteMillis sql.NullInt64
+	var deleteMillis, lastSequencedMillis, clonedFrom sql.NullInt64
+	var signatureParams sql.NullString
 	err := row.Scan(
 		&tree.TreeId,
 		&treeState,
@@ -187,6 +549,14 @@ func readTree(row row) (*trillian.Tree, error) {
 		&maxRootDurationMillis,
 		&deleted,
 		&deleteMillis,
+		&tree.WritesDisabled,
+		&lastSequencedMillis,
+		&signatureParams,
+		&tree.SequencingPaused,
+		&externalRef,
+		&readConsistency,
+		&clonedFrom,
+		&tree.Revision,
 	)
 	if err != nil {
 		return nil, err
@@ -194,6 +564,17 @@ func readTree(row row) (*trillian.Tree, error) {
 
 	setNullStringIfValid(displayName, &tree.DisplayName)
 	setNullStringIfValid(description, &tree.Description)
+	setNullStringIfValid(externalRef, &tree.ExternalRef)
+	if clonedFrom.Valid {
+		tree.ClonedFrom = clonedFrom.Int64
+	}
+	if readConsistency.Valid {
+		rc, ok := trillian.ReadConsistency_value[readConsistency.String]
+		if !ok {
+			return nil, fmt.Errorf("unknown PreferredReadConsistency: %v", readConsistency.String)
+		}
+		tree.PreferredReadConsistency = trillian.ReadConsistency(rc)
+	}
 
 	// Convert all things!
 	if ts, ok := trillian.TreeState_value[treeState]; ok {
@@ -252,12 +633,26 @@ func readTree(row row) (*trillian.Tree, error) {
 	tree.PublicKey = &keyspb.PublicKey{Der: publicKey}
 
 	tree.Deleted = deleted.Valid && deleted.Bool
-	if tree.Deleted && deleteMillis.Valid {
+	// DeleteTime is read regardless of Deleted, so inconsistent trees may be
+	// found and repaired.
+	if deleteMillis.Valid {
 		tree.DeleteTime, err = ptypes.TimestampProto(fromMillisSinceEpoch(deleteMillis.Int64))
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse delete time: %v", err)
 		}
 	}
+	if lastSequencedMillis.Valid {
+		tree.LastSequencedTime, err = ptypes.TimestampProto(fromMillisSinceEpoch(lastSequencedMillis.Int64))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last sequenced time: %v", err)
+		}
+	}
+	if signatureParams.Valid {
+		tree.SignatureParams = &trillian.SignatureParams{}
+		if err := proto.Unmarshal([]byte(signatureParams.String), tree.SignatureParams); err != nil {
+			return nil, fmt.Errorf("could not unmarshal SignatureParams: %v", err)
+		}
+	}
 
 	return tree, nil
 }
@@ -269,7 +664,19 @@ func setNullStringIfValid(src sql.NullString, dest *string) {
 	}
 }
 
+// readConsistencyValue returns the PreferredReadConsistency column value for
+// rc, which is NULL if rc is unspecified.
+func readConsistencyValue(rc trillian.ReadConsistency) interface{} {
+	if rc == trillian.ReadConsistency_UNSPECIFIED_READ_CONSISTENCY {
+		return nil
+	}
+	return rc.String()
+}
+
 func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
 	var query string
 	if includeDeleted {
 		query = selectTreeIDs
@@ -300,20 +707,68 @@ func (t *adminTX) ListTreeIDs(ctx context.Context, includeDeleted bool) ([]int64
 	return treeIDs, nil
 }
 
+func (t *adminTX) ListSequenceableTreeIDs(ctx context.Context) ([]int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	rows, err := t.tx.QueryContext(
+		ctx,
+		selectSequenceableTreeIDs,
+		trillian.TreeType_LOG.String(), trillian.TreeType_PREORDERED_LOG.String(),
+		trillian.TreeState_ACTIVE.String(), trillian.TreeState_DRAINING.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	treeIDs := []int64{}
+	for rows.Next() {
+		var treeID int64
+		if err := rows.Scan(&treeID); err != nil {
+			return nil, err
+		}
+		treeIDs = append(treeIDs, treeID)
+	}
+	return treeIDs, rows.Err()
+}
+
 func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
 	var query string
 	if includeDeleted {
 		query = selectTrees
 	} else {
 		query = selectNonDeletedTrees
 	}
+	return t.queryTrees(ctx, query)
+}
 
-	stmt, err := t.tx.PrepareContext(ctx, query)
+// queryTrees returns the trees selected by query, a selectTrees query taking
+// args, in the order returned by it. Trees are read as per GetTree, i.e.,
+// verified if so configured, decorated and redacted.
+func (t *adminTX) queryTrees(ctx context.Context, query string, args ...interface{}) ([]*trillian.Tree, error) {
+	trees, err := t.queryStoredTrees(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
-	rows, err := stmt.QueryContext(ctx)
+	for _, tree := range trees {
+		if t.opts.VerifyConfigChecksumOnRead {
+			if err := t.verifyConfigChecksum(ctx, tree); err != nil {
+				return nil, err
+			}
+		}
+		storage.DecorateTree(ctx, tree)
+		storage.RedactTree(ctx, t.opts, tree)
+	}
+	return trees, nil
+}
+
+// queryStoredTrees is like queryTrees, but returns trees exactly as stored,
+// i.e., neither verified, decorated nor redacted.
+func (t *adminTX) queryStoredTrees(ctx context.Context, query string, args ...interface{}) ([]*trillian.Tree, error) {
+	rows, err := t.tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -326,205 +781,1062 @@ func (t *adminTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trilli
 		}
 		trees = append(trees, tree)
 	}
-	return trees, nil
-}
-
-func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
-	if err := storage.ValidateTreeForCreation(ctx, tree); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	if err := validateStorageSettings(tree); err != nil {
-		return nil, err
+	rows.Close()
+	if len(trees) == 0 {
+		return trees, nil
 	}
 
-	id, err := storage.NewTreeID()
-	if err != nil {
-		return nil, err
+	treeIDs := make([]int64, 0, len(trees))
+	for _, tree := range trees {
+		treeIDs = append(treeIDs, tree.TreeId)
 	}
-
-	// Use the time truncated-to-millis throughout, as that's what's stored.
-	nowMillis := toMillisSinceEpoch(time.Now())
-	now := fromMillisSinceEpoch(nowMillis)
-
-	newTree := *tree
-	newTree.TreeId = id
-	newTree.CreateTime, err = ptypes.TimestampProto(now)
+	labels, err := t.readLabelsOfTrees(ctx, treeIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build create time: %v", err)
+		return nil, fmt.Errorf("error reading labels: %v", err)
 	}
-	newTree.UpdateTime, err = ptypes.TimestampProto(now)
+	appData, err := t.readAppDataOfTrees(ctx, treeIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build update time: %v", err)
+		return nil, fmt.Errorf("error reading app data: %v", err)
 	}
-	rootDuration, err := ptypes.Duration(newTree.MaxRootDuration)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse MaxRootDuration: %v", err)
+	for _, tree := range trees {
+		tree.Labels = labels[tree.TreeId]
+		tree.AppData = appData[tree.TreeId]
 	}
+	return trees, nil
+}
 
-	insertTreeStmt, err := t.tx.PrepareContext(
-		ctx,
-		`INSERT INTO Trees(
-			TreeId,
-			TreeState,
-			TreeType,
-			HashStrategy,
-			HashAlgorithm,
-			SignatureAlgorithm,
-			DisplayName,
-			Description,
-			CreateTimeMillis,
-			UpdateTimeMillis,
-			PrivateKey,
-			PublicKey,
-			MaxRootDurationMillis)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+func (t *adminTX) ListTreesForDisplay(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	trees, err := t.ListTrees(ctx, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
-	defer insertTreeStmt.Close()
-
-	privateKey, err := proto.Marshal(newTree.PrivateKey)
-	if err != nil {
-		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
+	for _, tree := range trees {
+		storage.TruncateDescription(t.opts, tree)
 	}
+	return trees, nil
+}
 
-	_, err = insertTreeStmt.ExecContext(
-		ctx,
-		newTree.TreeId,
-		newTree.TreeState.String(),
-		newTree.TreeType.String(),
-		newTree.HashStrategy.String(),
-		newTree.HashAlgorithm.String(),
-		newTree.SignatureAlgorithm.String(),
-		newTree.DisplayName,
-		newTree.Description,
-		nowMillis,
-		nowMillis,
-		privateKey,
-		newTree.PublicKey.GetDer(),
-		rootDuration/time.Millisecond,
-	)
-	if err != nil {
+func (t *adminTX) ListHardDeletableTrees(ctx context.Context, retention time.Duration, now time.Time) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
 		return nil, err
 	}
+	return t.queryTrees(ctx, selectHardDeletableTrees, toMillisSinceEpoch(now.Add(-retention)))
+}
 
-	// MySQL silently truncates data when running in non-strict mode.
-	// We shouldn't be using non-strict modes, but let's guard against it
-	// anyway.
-	if _, err := t.GetTree(ctx, newTree.TreeId); err != nil {
-		// GetTree will fail for truncated enums (they get recorded as
-		// empty strings, which will not match any known value).
-		return nil, fmt.Errorf("enum truncated: %v", err)
+func (t *adminTX) GetTreeKeyInfo(ctx context.Context, treeID int64) (*storage.KeyInfo, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	// Only read the public key, so private keys stay untouched.
+	var publicKey []byte
+	switch err := t.tx.QueryRowContext(ctx, selectPublicKeyByID, treeID).Scan(&publicKey); {
+	case err == sql.ErrNoRows:
+		return nil, errors.Errorf(errors.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return nil, fmt.Errorf("error reading public key of tree %v: %v", treeID, err)
 	}
+	return storage.NewKeyInfo(&keyspb.PublicKey{Der: publicKey})
+}
 
-	// TODO(codingllama): There's a strong disconnect between trillian.Tree and TreeControl. Are we OK with that?
-	insertControlStmt, err := t.tx.PrepareContext(
-		ctx,
-		`INSERT INTO TreeControl(
-			TreeId,
-			SigningEnabled,
-			SequencingEnabled,
-			SequenceIntervalSeconds)
-		VALUES(?, ?, ?, ?)`)
-	if err != nil {
+func (t *adminTX) GetTreesByDisplayNames(ctx context.Context, names []string, includeDeleted bool) (map[string]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
 		return nil, err
 	}
-	defer insertControlStmt.Close()
-	_, err = insertControlStmt.ExecContext(
-		ctx,
-		newTree.TreeId,
-		true, /* SigningEnabled */
-		true, /* SequencingEnabled */
-		defaultSequenceIntervalSeconds,
-	)
+	ret := make(map[string]*trillian.Tree)
+	if len(names) == 0 {
+		return ret, nil
+	}
+	query := selectNonDeletedTreesByDisplayNames
+	if includeDeleted {
+		query = selectTreesByDisplayNames
+	}
+	args := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		args = append(args, name)
+	}
+	trees, err := t.queryTrees(ctx, expandPlaceholderSQL(query, len(args), "?", "?"), args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &newTree, nil
+	matches := make(map[string][]*trillian.Tree)
+	for _, tree := range trees {
+		matches[tree.DisplayName] = append(matches[tree.DisplayName], tree)
+	}
+	for _, name := range names {
+		switch m := matches[name]; len(m) {
+		case 0:
+		case 1:
+			ret[name] = m[0]
+		default:
+			treeIDs := make([]int64, 0, len(m))
+			for _, tree := range m {
+				treeIDs = append(treeIDs, tree.TreeId)
+			}
+			return nil, errors.Errorf(errors.FailedPrecondition, "display name %q is ambiguous, matches trees %v", name, treeIDs)
+		}
+	}
+	return ret, nil
 }
 
-func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
-	tree, err := t.GetTree(ctx, treeID)
-	if err != nil {
+func (t *adminTX) ExistingTreeIDs(ctx context.Context, candidateIDs []int64, includeDeleted bool) (map[int64]bool, error) {
+	if err := t.checkOpen(); err != nil {
 		return nil, err
 	}
-
-	beforeUpdate := *tree
-	updateFunc(tree)
-	if err := storage.ValidateTreeForUpdate(ctx, &beforeUpdate, tree); err != nil {
+	ret := make(map[int64]bool)
+	if len(candidateIDs) == 0 {
+		return ret, nil
+	}
+	args := make([]interface{}, 0, len(candidateIDs))
+	for _, id := range candidateIDs {
+		ret[id] = false
+		args = append(args, id)
+	}
+	query := selectNonDeletedTreeIDsIn
+	if includeDeleted {
+		query = selectTreeIDsIn
+	}
+	rows, err := t.tx.QueryContext(ctx, expandPlaceholderSQL(query, len(args), "?", "?"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var treeID int64
+		if err := rows.Scan(&treeID); err != nil {
+			return nil, err
+		}
+		ret[treeID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (t *adminTX) CountTreesByLabel(ctx context.Context, includeDeleted bool) (map[string]int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	query := selectNonDeletedLabelCounts
+	if includeDeleted {
+		query = selectLabelCounts
+	}
+	rows, err := t.tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var label string
+		var count int64
+		if err := rows.Scan(&label, &count); err != nil {
+			return nil, err
+		}
+		counts[label] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (t *adminTX) ListTreeRevisions(ctx context.Context, includeDeleted bool) (map[int64]int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	query := selectNonDeletedTreeRevisions
+	if includeDeleted {
+		query = selectTreeRevisions
+	}
+	rows, err := t.tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	revisions := make(map[int64]int64)
+	for rows.Next() {
+		var treeID, revision int64
+		if err := rows.Scan(&treeID, &revision); err != nil {
+			return nil, err
+		}
+		revisions[treeID] = revision
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (t *adminTX) GetTreeByExternalRef(ctx context.Context, ref string, includeDeleted bool) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		return nil, errors.New(errors.InvalidArgument, "an external_ref is required")
+	}
+	query := selectNonDeletedTreesByExternalRef
+	if includeDeleted {
+		query = selectTreesByExternalRef
+	}
+	trees, err := t.queryTrees(ctx, query, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(trees) {
+	case 0:
+		return nil, errors.Errorf(errors.NotFound, "no tree with external_ref %q", ref)
+	case 1:
+		return trees[0], nil
+	default:
+		treeIDs := make([]int64, 0, len(trees))
+		for _, tree := range trees {
+			treeIDs = append(treeIDs, tree.TreeId)
+		}
+		return nil, errors.Errorf(errors.FailedPrecondition, "external_ref %q is ambiguous, matches trees %v", ref, treeIDs)
+	}
+}
+
+func (t *adminTX) ListRecentTrees(ctx context.Context, limit int, includeDeleted bool) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, errors.Errorf(errors.InvalidArgument, "invalid limit: %v", limit)
+	}
+	query := selectNonDeletedRecentTrees
+	if includeDeleted {
+		query = selectRecentTrees
+	}
+	return t.queryTrees(ctx, query, limit)
+}
+
+func (t *adminTX) ListUntouchedTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	query := selectNonDeletedUntouchedTrees
+	if includeDeleted {
+		query = selectUntouchedTrees
+	}
+	return t.queryTrees(ctx, query)
+}
+
+// StreamTreeConfigHashes reads all trees up front, in a single query, and only
+// then streams their hashes; the result is materialized in memory rather than
+// streamed from the database.
+func (t *adminTX) StreamTreeConfigHashes(ctx context.Context, includeDeleted bool) (<-chan storage.TreeHash, <-chan error) {
+	return storage.StreamTreeHashes(ctx, func() ([]storage.TreeHash, error) {
+		if err := t.checkOpen(); err != nil {
+			return nil, err
+		}
+		query := selectNonDeletedTrees
+		if includeDeleted {
+			query = selectTrees
+		}
+		// Hashes cover the stored config, so trees are read as stored.
+		trees, err := t.queryStoredTrees(ctx, query+" ORDER BY TreeId")
+		if err != nil {
+			return nil, err
+		}
+		hashes := make([]storage.TreeHash, 0, len(trees))
+		for _, tree := range trees {
+			hash, err := storage.TreeConfigHash(tree)
+			if err != nil {
+				return nil, err
+			}
+			hashes = append(hashes, storage.TreeHash{TreeID: tree.TreeId, ConfigHash: hash})
+		}
+		return hashes, nil
+	})
+}
+
+func (t *adminTX) ResolveTreeAlias(ctx context.Context, alias string) (int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return 0, err
+	}
+	var treeID int64
+	switch err := t.tx.QueryRowContext(ctx, selectTreeIDByAlias, alias).Scan(&treeID); {
+	case err == sql.ErrNoRows:
+		return 0, errors.Errorf(errors.NotFound, "alias %q not found", alias)
+	case err != nil:
+		return 0, err
+	}
+	return treeID, nil
+}
+
+func (t *adminTX) ListTreeCountSnapshots(ctx context.Context, from, to time.Time) ([]storage.CountSnapshot, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	rows, err := t.tx.QueryContext(ctx, selectTreeCountSnapshots, toMillisSinceEpoch(from), toMillisSinceEpoch(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var snapshots []storage.CountSnapshot
+	for rows.Next() {
+		var timeMillis int64
+		var snapshot storage.CountSnapshot
+		if err := rows.Scan(&timeMillis, &snapshot.Active, &snapshot.Deleted); err != nil {
+			return nil, err
+		}
+		snapshot.Time = fromMillisSinceEpoch(timeMillis)
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (t *adminTX) CreateTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if !t.acceptingWrites {
+		return nil, storage.ErrNotAcceptingWrites
+	}
+	tree = storage.DeriveLabels(tree)
+	if err := storage.ValidateTreeForCreation(ctx, t.opts, tree); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTreeKeyCurve(t.opts, tree); err != nil {
 		return nil, err
 	}
 	if err := validateStorageSettings(tree); err != nil {
 		return nil, err
 	}
+	if tree.ClonedFrom != 0 {
+		switch _, err := t.getTree(ctx, tree.ClonedFrom); {
+		case errors.ErrorCode(err) == errors.NotFound:
+			return nil, errors.Errorf(errors.InvalidArgument, "cloned_from tree %v not found", tree.ClonedFrom)
+		case err != nil:
+			return nil, err
+		}
+	}
 
 	// Use the time truncated-to-millis throughout, as that's what's stored.
-	nowMillis := toMillisSinceEpoch(time.Now())
+	nowMillis := toMillisSinceEpoch(t.now())
 	now := fromMillisSinceEpoch(nowMillis)
-	tree.UpdateTime, err = ptypes.TimestampProto(now)
+
+	id := tree.TreeId
+	reserved := false
+	var err error
+	if id != 0 {
+		if reserved, err = t.consumeReservation(ctx, id, now); err != nil {
+			return nil, err
+		}
+	}
+	if !reserved {
+		if id, err = t.newUnusedTreeID(ctx, now); err != nil {
+			return nil, err
+		}
+	}
+
+	newTree := *tree
+	newTree.TreeId = id
+	newTree.LastSequencedTime = nil // New trees were never sequenced.
+	newTree.SequencingPaused = false
+	newTree.Revision = 1
+	storage.CanonicalizeTree(&newTree)
+	newTree.CreateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build create time: %v", err)
+	}
+	newTree.UpdateTime, err = ptypes.TimestampProto(now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build update time: %v", err)
 	}
+
+	if err := t.insertTree(ctx, &newTree); err != nil {
+		return nil, err
+	}
+	storage.RedactTree(ctx, t.opts, &newTree)
+	return &newTree, nil
+}
+
+func (t *adminTX) CreateTreeWithAlias(ctx context.Context, tree *trillian.Tree, alias string) (*trillian.Tree, error) {
+	return storage.CreateTreeWithAlias(ctx, t, tree, alias, t.addTreeAlias)
+}
+
+// addTreeAlias points the free alias to treeID, relying on the TreeAliases
+// primary key to refuse aliases that are in use.
+func (t *adminTX) addTreeAlias(ctx context.Context, alias string, treeID int64) error {
+	_, err := t.tx.ExecContext(ctx, insertTreeAlias, alias, treeID)
+	if isDuplicateErr(err) {
+		return errors.Errorf(errors.AlreadyExists, "alias %q already in use", alias)
+	}
+	return err
+}
+
+func (t *adminTX) ReserveTreeID(ctx context.Context) (int64, error) {
+	if err := t.checkOpen(); err != nil {
+		return 0, err
+	}
+
+	now := t.now()
+	// Free up abandoned reservations.
+	if _, err := t.tx.ExecContext(ctx, "DELETE FROM TreeIdReservations WHERE ExpiryTimeMillis <= ?", toMillisSinceEpoch(now)); err != nil {
+		return 0, err
+	}
+
+	id, err := t.newUnusedTreeID(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	expiry := toMillisSinceEpoch(storage.TreeIDReservationExpiry(t.opts, now))
+	if _, err := t.tx.ExecContext(ctx, "INSERT INTO TreeIdReservations(TreeId, ExpiryTimeMillis) VALUES(?, ?)", id, expiry); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// newUnusedTreeID generates a tree ID that is neither assigned to a tree nor
+// reserved as of now.
+func (t *adminTX) newUnusedTreeID(ctx context.Context, now time.Time) (int64, error) {
+	for i := 0; i < maxTreeIDAttempts; i++ {
+		id, err := newTreeID()
+		if err != nil {
+			return 0, err
+		}
+		switch _, err := t.getTree(ctx, id); {
+		case err == nil:
+			continue
+		case errors.ErrorCode(err) != errors.NotFound:
+			return 0, err
+		}
+		switch reserved, err := t.isReserved(ctx, id, now); {
+		case err != nil:
+			return 0, err
+		case !reserved:
+			return id, nil
+		}
+	}
+	return 0, errors.Errorf(errors.Internal, "failed to generate an unused tree ID after %v attempts", maxTreeIDAttempts)
+}
+
+// isReserved returns whether treeID has a live reservation as of now.
+func (t *adminTX) isReserved(ctx context.Context, treeID int64, now time.Time) (bool, error) {
+	var expiryMillis int64
+	switch err := t.tx.QueryRowContext(ctx, selectReservationByID, treeID).Scan(&expiryMillis); {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return expiryMillis > toMillisSinceEpoch(now), nil
+}
+
+// consumeReservation removes the reservation of treeID and returns true if
+// treeID is reserved as of now. Otherwise it returns false, leaving storage
+// unchanged.
+func (t *adminTX) consumeReservation(ctx context.Context, treeID int64, now time.Time) (bool, error) {
+	switch reserved, err := t.isReserved(ctx, treeID, now); {
+	case err != nil:
+		return false, err
+	case !reserved:
+		return false, nil
+	}
+	if _, err := t.tx.ExecContext(ctx, "DELETE FROM TreeIdReservations WHERE TreeId = ?", treeID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *adminTX) ImportTree(ctx context.Context, tree *trillian.Tree) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if !t.acceptingWrites {
+		return nil, storage.ErrNotAcceptingWrites
+	}
+	if err := storage.ValidateTreeForImport(ctx, t.opts, tree); err != nil {
+		return nil, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, err
+	}
+
+	switch _, err := t.getTree(ctx, tree.TreeId); {
+	case err == nil:
+		return nil, errors.Errorf(errors.AlreadyExists, "tree %v already exists", tree.TreeId)
+	case errors.ErrorCode(err) != errors.NotFound:
+		return nil, err
+	}
+
+	newTree := *tree
+	storage.CanonicalizeTree(&newTree)
+	// Imported trees keep their revision, if they have one.
+	if newTree.Revision == 0 {
+		newTree.Revision = 1
+	}
+	if err := t.insertTree(ctx, &newTree); err != nil {
+		return nil, err
+	}
+	// Timestamps are truncated to millis by storage, so read the tree back.
+	return t.redactedTree(ctx, tree.TreeId)
+}
+
+// insertTree inserts tree and all its related records in storage.
+// tree must have been validated and have all storage-generated fields set.
+func (t *adminTX) insertTree(ctx context.Context, tree *trillian.Tree) error {
+	createTime, err := ptypes.Timestamp(tree.CreateTime)
+	if err != nil {
+		return fmt.Errorf("failed to parse create time: %v", err)
+	}
+	updateTime, err := ptypes.Timestamp(tree.UpdateTime)
+	if err != nil {
+		return fmt.Errorf("failed to parse update time: %v", err)
+	}
 	rootDuration, err := ptypes.Duration(tree.MaxRootDuration)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse MaxRootDuration: %v", err)
+		return fmt.Errorf("could not parse MaxRootDuration: %v", err)
+	}
+	// Deleted and DeleteTimeMillis are NULL for non-deleted trees.
+	var deleted, deleteTimeMillis interface{}
+	if tree.Deleted {
+		deleteTime, err := ptypes.Timestamp(tree.DeleteTime)
+		if err != nil {
+			return fmt.Errorf("failed to parse delete time: %v", err)
+		}
+		deleted = true
+		deleteTimeMillis = toMillisSinceEpoch(deleteTime)
+	}
+	// LastSequencedTimeMillis is NULL for never sequenced trees.
+	var lastSequencedTimeMillis interface{}
+	if tree.LastSequencedTime != nil {
+		lastSequencedTime, err := ptypes.Timestamp(tree.LastSequencedTime)
+		if err != nil {
+			return fmt.Errorf("failed to parse last sequenced time: %v", err)
+		}
+		lastSequencedTimeMillis = toMillisSinceEpoch(lastSequencedTime)
+	}
+	// ClonedFrom is NULL for trees that aren't clones.
+	var clonedFrom interface{}
+	if tree.ClonedFrom != 0 {
+		clonedFrom = tree.ClonedFrom
+	}
+
+	insertTreeStmt, err := t.tx.PrepareContext(
+		ctx,
+		`INSERT INTO Trees(
+			TreeId,
+			TreeState,
+			TreeType,
+			HashStrategy,
+			HashAlgorithm,
+			SignatureAlgorithm,
+			DisplayName,
+			Description,
+			CreateTimeMillis,
+			UpdateTimeMillis,
+			PrivateKey,
+			PublicKey,
+			MaxRootDurationMillis,
+			Deleted,
+			DeleteTimeMillis,
+			WritesDisabled,
+			LastSequencedTimeMillis,
+			SignatureParams,
+			SequencingPaused,
+			ExternalRef,
+			PreferredReadConsistency,
+			ClonedFrom,
+			Revision)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
 	}
+	defer insertTreeStmt.Close()
 
 	privateKey, err := proto.Marshal(tree.PrivateKey)
 	if err != nil {
-		return nil, fmt.Errorf("could not marshal PrivateKey: %v", err)
+		return fmt.Errorf("could not marshal PrivateKey: %v", err)
+	}
+	// SignatureParams is NULL if unset, so unset and default params can be
+	// told apart.
+	var signatureParams interface{}
+	if tree.SignatureParams != nil {
+		params, err := proto.Marshal(tree.SignatureParams)
+		if err != nil {
+			return fmt.Errorf("could not marshal SignatureParams: %v", err)
+		}
+		signatureParams = append([]byte{}, params...)
 	}
 
-	stmt, err := t.tx.PrepareContext(
+	_, err = insertTreeStmt.ExecContext(
 		ctx,
-		`UPDATE Trees
-		SET TreeState = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?
-		WHERE TreeId = ?`)
+		tree.TreeId,
+		tree.TreeState.String(),
+		tree.TreeType.String(),
+		tree.HashStrategy.String(),
+		tree.HashAlgorithm.String(),
+		tree.SignatureAlgorithm.String(),
+		tree.DisplayName,
+		tree.Description,
+		toMillisSinceEpoch(createTime),
+		toMillisSinceEpoch(updateTime),
+		privateKey,
+		tree.PublicKey.GetDer(),
+		rootDuration/time.Millisecond,
+		deleted,
+		deleteTimeMillis,
+		tree.WritesDisabled,
+		lastSequencedTimeMillis,
+		signatureParams,
+		tree.SequencingPaused,
+		tree.ExternalRef,
+		readConsistencyValue(tree.PreferredReadConsistency),
+		clonedFrom,
+		tree.Revision,
+	)
 	if err != nil {
+		return err
+	}
+
+	// MySQL silently truncates data when running in non-strict mode.
+	// We shouldn't be using non-strict modes, but let's guard against it
+	// anyway.
+	if _, err := t.getTree(ctx, tree.TreeId); err != nil {
+		// GetTree will fail for truncated enums (they get recorded as
+		// empty strings, which will not match any known value).
+		return fmt.Errorf("enum truncated: %v", err)
+	}
+	// TODO(codingllama): There's a strong disconnect between trillian.Tree and TreeControl. Are we OK with that?
+	insertControlStmt, err := t.tx.PrepareContext(
+		ctx,
+		`INSERT INTO TreeControl(
+			TreeId,
+			SigningEnabled,
+			SequencingEnabled,
+			SequenceIntervalSeconds)
+		VALUES(?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insertControlStmt.Close()
+	_, err = insertControlStmt.ExecContext(
+		ctx,
+		tree.TreeId,
+		true, /* SigningEnabled */
+		true, /* SequencingEnabled */
+		defaultSequenceIntervalSeconds,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := t.writeLabels(ctx, tree.TreeId, tree.Labels); err != nil {
+		return err
+	}
+	if err := t.writeAppData(ctx, tree.TreeId, tree.AppData); err != nil {
+		return err
+	}
+	return t.writeConfigChecksum(ctx, tree.TreeId)
+}
+
+func (t *adminTX) UpdateTree(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
+	tree, changed, err := t.prepareUpdate(ctx, treeID, updateFunc)
+	if err != nil {
+		return nil, err
+	}
+	if !changed && !t.opts.AdvanceUpdateTimeOnNoop {
+		storage.RedactTree(ctx, t.opts, tree)
+		return tree, nil
+	}
+	if err := t.writeUpdate(ctx, tree); err != nil {
+		return nil, err
+	}
+	storage.RedactTree(ctx, t.opts, tree)
+	return tree, nil
+}
+
+func (t *adminTX) UpdateTrees(ctx context.Context, updates map[int64]func(*trillian.Tree)) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(updates))
+	for id := range updates {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// Validate all updates before writing any of them.
+	var errs errors.MultiError
+	trees := make([]*trillian.Tree, 0, len(ids))
+	changed := make([]bool, 0, len(ids))
+	for _, id := range ids {
+		tree, treeChanged, err := t.prepareUpdate(ctx, id, updates[id])
+		if err != nil {
+			errs = append(errs, errors.Errorf(errors.ErrorCode(err), "tree %v: %v", id, err))
+			continue
+		}
+		trees = append(trees, tree)
+		changed = append(changed, treeChanged)
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	for i, tree := range trees {
+		if !changed[i] && !t.opts.AdvanceUpdateTimeOnNoop {
+			continue
+		}
+		if err := t.writeUpdate(ctx, tree); err != nil {
+			return nil, err
+		}
+	}
+	for _, tree := range trees {
+		storage.RedactTree(ctx, t.opts, tree)
+	}
+	return trees, nil
+}
+
+func (t *adminTX) TouchTrees(ctx context.Context, treeIDs []int64) ([]*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	ids := sortedUniqueIDs(treeIDs)
+
+	// Read all trees before writing any of them.
+	trees := make([]*trillian.Tree, 0, len(ids))
+	for _, id := range ids {
+		if err := storage.ValidateTreeID(id); err != nil {
+			return nil, err
+		}
+		tree, err := t.getTree(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree)
+	}
+
+	// Use the time truncated-to-millis throughout, as that's what's stored.
+	nowMillis := toMillisSinceEpoch(t.now())
+	updateTime, err := ptypes.TimestampProto(fromMillisSinceEpoch(nowMillis))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update time: %v", err)
+	}
+	for _, tree := range trees {
+		// Bump the stored revision rather than writing the one read above,
+		// so concurrent writes are never undone, and read back the result.
+		if _, err := t.tx.ExecContext(ctx, "UPDATE Trees SET UpdateTimeMillis = ?, Revision = Revision + 1 WHERE TreeId = ?", nowMillis, tree.TreeId); err != nil {
+			return nil, err
+		}
+		if err := t.tx.QueryRowContext(ctx, "SELECT Revision FROM Trees WHERE TreeId = ?", tree.TreeId).Scan(&tree.Revision); err != nil {
+			return nil, err
+		}
+		tree.UpdateTime = updateTime
+		storage.RedactTree(ctx, t.opts, tree)
+	}
+	return trees, nil
+}
+
+// sortedUniqueIDs returns the distinct IDs of ids, in ascending order.
+func sortedUniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool)
+	ret := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			ret = append(ret, id)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
+
+func (t *adminTX) ReplaceTree(ctx context.Context, desired *trillian.Tree, expectedRevision int64) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTreeID(desired.TreeId); err != nil {
+		return nil, err
+	}
+	storedTree, err := t.getTree(ctx, desired.TreeId)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.CheckTreeRevision(storedTree, expectedRevision); err != nil {
+		return nil, err
+	}
+	// The update is conditional on the revision read above, so a concurrent
+	// write after the check makes it fail.
+	return t.UpdateTree(ctx, desired.TreeId, func(tree *trillian.Tree) {
+		revision := tree.Revision
+		*tree = *proto.Clone(desired).(*trillian.Tree)
+		tree.Revision = revision
+	})
+}
+
+// prepareUpdate reads treeID and applies updateFunc to it, returning the
+// updated and validated tree, and whether updateFunc modified it. Storage is
+// not modified.
+func (t *adminTX) prepareUpdate(ctx context.Context, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, bool, error) {
+	if err := storage.ValidateTreeID(treeID); err != nil {
+		return nil, false, err
+	}
+	tree, err := t.getTree(ctx, treeID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// updateFunc may modify nested messages and maps in place, so keep a deep
+	// copy to compare against.
+	beforeUpdate := proto.Clone(tree).(*trillian.Tree)
+	updateFunc(tree)
+	storage.CanonicalizeTree(tree)
+	if err := storage.ValidateTreeForUpdate(ctx, t.opts, beforeUpdate, tree); err != nil {
+		return nil, false, err
+	}
+	if err := storage.ValidateTreeStateTransition(t.opts, beforeUpdate, tree); err != nil {
+		return nil, false, err
+	}
+	if err := validateStorageSettings(tree); err != nil {
+		return nil, false, err
+	}
+	return tree, !proto.Equal(beforeUpdate, tree), nil
+}
 
-	if _, err = stmt.ExecContext(
+// writeUpdate writes the mutable fields of tree to storage, setting its
+// UpdateTime to the current time.
+func (t *adminTX) writeUpdate(ctx context.Context, tree *trillian.Tree) error {
+	// Use the time truncated-to-millis throughout, as that's what's stored.
+	nowMillis := toMillisSinceEpoch(t.now())
+	now := fromMillisSinceEpoch(nowMillis)
+	var err error
+	tree.UpdateTime, err = ptypes.TimestampProto(now)
+	if err != nil {
+		return fmt.Errorf("failed to build update time: %v", err)
+	}
+	rootDuration, err := ptypes.Duration(tree.MaxRootDuration)
+	if err != nil {
+		return fmt.Errorf("could not parse MaxRootDuration: %v", err)
+	}
+
+	privateKey, err := proto.Marshal(tree.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("could not marshal PrivateKey: %v", err)
+	}
+
+	// tree.Revision is the revision read by prepareUpdate, so the update fails
+	// if the tree was written since.
+	if err := t.updateIfRevision(
 		ctx,
+		tree.TreeId,
+		tree.Revision,
+		`UPDATE Trees
+		SET TreeState = ?, DisplayName = ?, Description = ?, UpdateTimeMillis = ?, MaxRootDurationMillis = ?, PrivateKey = ?, WritesDisabled = ?, ExternalRef = ?, PreferredReadConsistency = ?, Revision = Revision + 1`,
 		tree.TreeState.String(),
 		tree.DisplayName,
 		tree.Description,
 		nowMillis,
 		rootDuration/time.Millisecond,
 		privateKey,
-		tree.TreeId); err != nil {
-		return nil, err
+		tree.WritesDisabled,
+		tree.ExternalRef,
+		readConsistencyValue(tree.PreferredReadConsistency)); err != nil {
+		return err
+	}
+	tree.Revision++
+	if err := t.writeLabels(ctx, tree.TreeId, tree.Labels); err != nil {
+		return err
+	}
+	if err := t.writeAppData(ctx, tree.TreeId, tree.AppData); err != nil {
+		return err
 	}
+	return t.writeConfigChecksum(ctx, tree.TreeId)
+}
 
-	return tree, nil
+// updateIfRevision runs update, an UPDATE of the Trees table that increments
+// Revision, on the row of treeID only if its revision is expectedRevision.
+// Doing so in a single statement makes the check atomic with the write.
+// A FailedPrecondition error is returned if the revision doesn't match, or a
+// NotFound error if the tree doesn't exist.
+func (t *adminTX) updateIfRevision(ctx context.Context, treeID, expectedRevision int64, update string, args ...interface{}) error {
+	args = append(args, treeID, expectedRevision)
+	res, err := t.tx.ExecContext(ctx, update+" WHERE TreeId = ? AND Revision = ?", args...)
+	if err != nil {
+		return err
+	}
+	// Matched rows always change, as Revision is incremented, so they're
+	// counted even by MySQL.
+	switch n, err := res.RowsAffected(); {
+	case err != nil:
+		return err
+	case n > 0:
+		return nil
+	}
+	var id int64
+	switch err := t.tx.QueryRowContext(ctx, selectTreeIDs+" WHERE TreeId = ?", treeID).Scan(&id); {
+	case err == sql.ErrNoRows:
+		return errors.Errorf(errors.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return err
+	}
+	return errors.Errorf(errors.FailedPrecondition, "tree %v: stale revision %v, the tree was modified concurrently", treeID, expectedRevision)
+}
+
+func (t *adminTX) SetTreeLabels(ctx context.Context, treeID int64, labels []string) (*trillian.Tree, error) {
+	return t.UpdateTree(ctx, treeID, func(tree *trillian.Tree) {
+		tree.Labels = labels
+	})
+}
+
+func (t *adminTX) TransferLabels(ctx context.Context, fromTreeID, toTreeID int64, labels []string) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	return storage.TransferLabels(ctx, t, fromTreeID, toTreeID, labels)
+}
+
+func (t *adminTX) SetTreeAlias(ctx context.Context, alias string, treeID int64) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	if alias == "" {
+		return errors.New(errors.InvalidArgument, "empty tree alias")
+	}
+	if _, err := t.getTree(ctx, treeID); err != nil {
+		return err
+	}
+	// Delete and re-insert within the transaction, so repointing is atomic.
+	if _, err := t.tx.ExecContext(ctx, "DELETE FROM TreeAliases WHERE Alias = ?", alias); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(ctx, insertTreeAlias, alias, treeID)
+	return err
+}
+
+func (t *adminTX) WriteTreeCountSnapshot(ctx context.Context, snapshot *storage.CountSnapshot) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	millis := toMillisSinceEpoch(snapshot.Time)
+	_, err := t.tx.ExecContext(ctx, insertTreeCountSnapshot, millis, snapshot.Active, snapshot.Deleted)
+	if isDuplicateErr(err) {
+		// A snapshot was already taken this millisecond, the newer one wins.
+		_, err = t.tx.ExecContext(ctx, updateTreeCountSnapshot, snapshot.Active, snapshot.Deleted, millis)
+	}
+	return err
+}
+
+func (t *adminTX) RecordSequencingProgress(ctx context.Context, treeID int64, when *timestamp.Timestamp) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	whenTime, err := ptypes.Timestamp(when)
+	if err != nil {
+		return errors.Errorf(errors.InvalidArgument, "invalid sequencing time: %v", err)
+	}
+	// RowsAffected doesn't count rows left unchanged by MySQL, so check for
+	// the tree explicitly.
+	var id int64
+	switch err := t.tx.QueryRowContext(ctx, selectTreeIDs+" WHERE TreeId = ?", treeID).Scan(&id); {
+	case err == sql.ErrNoRows:
+		return errors.Errorf(errors.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return err
+	}
+	_, err = t.tx.ExecContext(
+		ctx,
+		"UPDATE Trees SET LastSequencedTimeMillis = ? WHERE TreeId = ?",
+		toMillisSinceEpoch(whenTime), treeID)
+	return err
+}
+
+func (t *adminTX) PauseSequencing(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.setSequencingPaused(ctx, treeID, true)
+}
+
+func (t *adminTX) ResumeSequencing(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	return t.setSequencingPaused(ctx, treeID, false)
+}
+
+func (t *adminTX) setSequencingPaused(ctx context.Context, treeID int64, paused bool) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := storage.ValidateTreeID(treeID); err != nil {
+		return nil, err
+	}
+	// RowsAffected doesn't count rows left unchanged by MySQL, so check for
+	// the tree explicitly.
+	var id int64
+	switch err := t.tx.QueryRowContext(ctx, selectTreeIDs+" WHERE TreeId = ?", treeID).Scan(&id); {
+	case err == sql.ErrNoRows:
+		return nil, errors.Errorf(errors.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return nil, err
+	}
+	if _, err := t.tx.ExecContext(ctx, "UPDATE Trees SET SequencingPaused = ?, Revision = Revision + 1 WHERE TreeId = ?", paused, treeID); err != nil {
+		return nil, err
+	}
+	if err := t.writeConfigChecksum(ctx, treeID); err != nil {
+		return nil, err
+	}
+	return t.redactedTree(ctx, treeID)
 }
 
 func (t *adminTX) SoftDeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
-	return t.updateDeleted(ctx, treeID, true /* deleted */, toMillisSinceEpoch(time.Now()) /* deleteTimeMillis */)
+	return t.updateDeleted(ctx, treeID, true /* deleted */, toMillisSinceEpoch(t.now()) /* deleteTimeMillis */, anyRevision)
+}
+
+func (t *adminTX) SoftDeleteTreeIfUnchanged(ctx context.Context, treeID int64, expectedRevision int64) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	storedTree, err := t.getTree(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.CheckTreeRevision(storedTree, expectedRevision); err != nil {
+		return nil, err
+	}
+	return t.updateDeleted(ctx, treeID, true /* deleted */, toMillisSinceEpoch(t.now()) /* deleteTimeMillis */, expectedRevision)
 }
 
 func (t *adminTX) UndeleteTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
-	return t.updateDeleted(ctx, treeID, false /* deleted */, nil /* deleteTimeMillis */)
+	return t.updateDeleted(ctx, treeID, false /* deleted */, nil /* deleteTimeMillis */, anyRevision)
 }
 
+// anyRevision makes updateDeleted apply regardless of the tree's revision.
+// Stored revisions start at 1, so it never matches one.
+const anyRevision = 0
+
 // updateDeleted updates the Deleted and DeleteTimeMillis fields of the specified tree.
 // deleteTimeMillis must be either an int64 (in millis since epoch) or nil.
-func (t *adminTX) updateDeleted(ctx context.Context, treeID int64, deleted bool, deleteTimeMillis interface{}) (*trillian.Tree, error) {
+// Unless expectedRevision is anyRevision, the tree is only updated if its
+// revision is expectedRevision, as per updateIfRevision.
+func (t *adminTX) updateDeleted(ctx context.Context, treeID int64, deleted bool, deleteTimeMillis interface{}, expectedRevision int64) (*trillian.Tree, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
 	if err := validateDeleted(ctx, t.tx, treeID, !deleted); err != nil {
 		return nil, err
 	}
-	if _, err := t.tx.ExecContext(
-		ctx,
-		"UPDATE Trees SET Deleted = ?, DeleteTimeMillis = ? WHERE TreeId = ?",
-		deleted, deleteTimeMillis, treeID); err != nil {
+	const update = "UPDATE Trees SET Deleted = ?, DeleteTimeMillis = ?, Revision = Revision + 1"
+	if expectedRevision != anyRevision {
+		if err := t.updateIfRevision(ctx, treeID, expectedRevision, update, deleted, deleteTimeMillis); err != nil {
+			return nil, err
+		}
+	} else if _, err := t.tx.ExecContext(ctx, update+" WHERE TreeId = ?", deleted, deleteTimeMillis, treeID); err != nil {
 		return nil, err
 	}
-	return t.GetTree(ctx, treeID)
+	return t.redactedTree(ctx, treeID)
 }
 
 func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
 	if err := validateDeleted(ctx, t.tx, treeID, true /* wantDeleted */); err != nil {
 		return err
 	}
@@ -533,11 +1845,21 @@ func (t *adminTX) HardDeleteTree(ctx context.Context, treeID int64) error {
 	if _, err := t.tx.ExecContext(ctx, "DELETE FROM TreeControl WHERE TreeId = ?", treeID); err != nil {
 		return err
 	}
+	// Foreign keys may not be enforced (e.g. on SQLite), so clear labels, app data and aliases
+	// explicitly too
+	for _, table := range []string{"TreeLabels", "TreeAppData", "TreeAliases"} {
+		if _, err := t.tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE TreeId = ?", treeID); err != nil {
+			return err
+		}
+	}
 	_, err := t.tx.ExecContext(ctx, "DELETE FROM Trees WHERE TreeId = ?", treeID)
 	return err
 }
 
 func validateDeleted(ctx context.Context, tx *sql.Tx, treeID int64, wantDeleted bool) error {
+	if err := storage.ValidateTreeID(treeID); err != nil {
+		return err
+	}
 	var nullDeleted sql.NullBool
 	switch err := tx.QueryRowContext(ctx, "SELECT Deleted FROM Trees WHERE TreeId = ?", treeID).Scan(&nullDeleted); {
 	case err == sql.ErrNoRows:
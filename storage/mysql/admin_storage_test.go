@@ -19,21 +19,44 @@ import (
 	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys"
 	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/errors"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/testonly"
+
+	_ "github.com/google/trillian/crypto/keys/der/proto"
 )
 
 const selectTreeControlByID = "SELECT SigningEnabled, SequencingEnabled, SequenceIntervalSeconds FROM TreeControl WHERE TreeId = ?"
 
 func TestMysqlAdminStorage(t *testing.T) {
-	tester := &testonly.AdminStorageTester{NewAdminStorage: func() storage.AdminStorage {
-		cleanTestDB(DB)
-		return NewAdminStorage(DB)
-	}}
+	tester := &testonly.AdminStorageTester{
+		NewAdminStorage: func() storage.AdminStorage {
+			cleanTestDB(DB)
+			return NewAdminStorage(DB)
+		},
+		NewAdminStorageWithOptions: func(opts storage.AdminStorageOptions) storage.AdminStorage {
+			cleanTestDB(DB)
+			return NewAdminStorageWithOptions(DB, opts)
+		},
+		SetDeletedUnchecked: func(ctx context.Context, treeID int64, deleted bool, deleteTime *time.Time) error {
+			var deleteTimeMillis interface{}
+			if deleteTime != nil {
+				deleteTimeMillis = toMillisSinceEpoch(*deleteTime)
+			}
+			_, err := DB.ExecContext(ctx, "UPDATE Trees SET Deleted = ?, DeleteTimeMillis = ? WHERE TreeId = ?", deleted, deleteTimeMillis, treeID)
+			return err
+		},
+		SetDescriptionUnchecked: func(ctx context.Context, treeID int64, description string) error {
+			_, err := DB.ExecContext(ctx, "UPDATE Trees SET Description = ? WHERE TreeId = ?", description, treeID)
+			return err
+		},
+	}
 	tester.RunAllTests(t)
 }
 
@@ -60,6 +83,72 @@ func TestAdminTX_CreateTree_InitializesStorageStructures(t *testing.T) {
 	}
 }
 
+func TestAdminTX_CreateTree_SkipsReservedIDs(t *testing.T) {
+	cleanTestDB(DB)
+	s := NewAdminStorage(DB)
+	ctx := context.Background()
+
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	reservedID, err := tx.ReserveTreeID(ctx)
+	if err != nil {
+		t.Fatalf("ReserveTreeID() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+
+	// Make the reserved ID the first one generated.
+	ids := []int64{reservedID, reservedID + 1}
+	defer func(f func() (int64, error)) { newTreeID = f }(newTreeID)
+	newTreeID = func() (int64, error) {
+		id := ids[0]
+		ids = ids[1:]
+		return id, nil
+	}
+
+	tree, err := createTreeInternal(ctx, s, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("createTree() failed: %v", err)
+	}
+	if got, want := tree.TreeId, reservedID+1; got != want {
+		t.Errorf("createTree() returned TreeId = %v, want = %v", got, want)
+	}
+}
+
+func TestAdminTX_AddTreeAlias_RefusesTakenAlias(t *testing.T) {
+	cleanTestDB(DB)
+	s := NewAdminStorage(DB)
+	ctx := context.Background()
+
+	const alias = "example.com/log"
+	tree1, err := createTreeInternal(ctx, s, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("createTreeInternal() returned err = %v", err)
+	}
+	tree2, err := createTreeInternal(ctx, s, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("createTreeInternal() returned err = %v", err)
+	}
+	// Take the alias as a concurrent CreateTreeWithAlias would, after the
+	// alias was checked but before it's inserted.
+	if _, err := DB.ExecContext(ctx, insertTreeAlias, alias, tree1.TreeId); err != nil {
+		t.Fatalf("ExecContext() returned err = %v", err)
+	}
+
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	if err := tx.(*adminTX).addTreeAlias(ctx, alias, tree2.TreeId); errors.ErrorCode(err) != errors.AlreadyExists {
+		t.Errorf("addTreeAlias() returned err = %v, wantCode = %s", err, errors.AlreadyExists)
+	}
+}
+
 func TestAdminTX_TreeWithNulls(t *testing.T) {
 	cleanTestDB(DB)
 	s := NewAdminStorage(DB)
@@ -215,6 +304,60 @@ func TestAdminTX_HardDeleteTree(t *testing.T) {
 	}
 }
 
+func TestFindTreesMissingPublicKey(t *testing.T) {
+	cleanTestDB(DB)
+	s := NewAdminStorage(DB)
+	ctx := context.Background()
+
+	validTree, err := createTreeInternal(ctx, s, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("createTreeInternal() returned err = %v", err)
+	}
+	noKeyTree, err := createTreeInternal(ctx, s, testonly.MapTree)
+	if err != nil {
+		t.Fatalf("createTreeInternal() returned err = %v", err)
+	}
+	// Validation doesn't allow trees without public keys, so empty it directly.
+	if _, err := DB.ExecContext(ctx, "UPDATE Trees SET PublicKey = ? WHERE TreeId = ?", []byte{}, noKeyTree.TreeId); err != nil {
+		t.Fatalf("ExecContext() returned err = %v", err)
+	}
+
+	ids, err := storage.FindTreesMissingPublicKey(ctx, s)
+	if err != nil {
+		t.Fatalf("FindTreesMissingPublicKey() returned err = %v", err)
+	}
+	if len(ids) != 1 || ids[0] != noKeyTree.TreeId {
+		t.Errorf("FindTreesMissingPublicKey() = %v, want = [%v] (valid tree = %v)", ids, noKeyTree.TreeId, validTree.TreeId)
+	}
+}
+
+func TestAuditKeyConsistency(t *testing.T) {
+	cleanTestDB(DB)
+	s := NewAdminStorage(DB)
+	ctx := context.Background()
+
+	consistentTree, err := createTreeInternal(ctx, s, testonly.LogTree)
+	if err != nil {
+		t.Fatalf("createTreeInternal() returned err = %v", err)
+	}
+	corruptedTree, err := createTreeInternal(ctx, s, testonly.MapTree)
+	if err != nil {
+		t.Fatalf("createTreeInternal() returned err = %v", err)
+	}
+	// Validation doesn't allow mismatched keys, so corrupt it directly.
+	if _, err := DB.ExecContext(ctx, "UPDATE Trees SET PublicKey = ? WHERE TreeId = ?", testonly.LogTree.PublicKey.GetDer(), corruptedTree.TreeId); err != nil {
+		t.Fatalf("ExecContext() returned err = %v", err)
+	}
+
+	mismatched, err := storage.AuditKeyConsistency(ctx, s, keys.NewSigner)
+	if err != nil {
+		t.Fatalf("AuditKeyConsistency() returned err = %v", err)
+	}
+	if len(mismatched) != 1 || errors.ErrorCode(mismatched[corruptedTree.TreeId]) != errors.DataLoss {
+		t.Errorf("AuditKeyConsistency() = %v, want a single DataLoss error for tree %v (consistent tree = %v)", mismatched, corruptedTree.TreeId, consistentTree.TreeId)
+	}
+}
+
 func TestCheckDatabaseAccessible_Fails(t *testing.T) {
 	// Pass in a closed database to provoke a failure.
 	db := openTestDBOrDie()
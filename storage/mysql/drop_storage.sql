@@ -8,6 +8,11 @@ DROP TABLE IF EXISTS LeafData;
 DROP TABLE IF EXISTS MapLeaf;
 DROP TABLE IF EXISTS MapHead;
 DROP TABLE IF EXISTS TreeControl;
+DROP TABLE IF EXISTS TreeLabels;
+DROP TABLE IF EXISTS TreeAppData;
+DROP TABLE IF EXISTS TreeAliases;
+DROP TABLE IF EXISTS TreeIdReservations;
+DROP TABLE IF EXISTS TreeCountSnapshots;
 DROP TABLE IF EXISTS MapHead;
 DROP TABLE IF EXISTS MapLeaf;
 DROP TABLE IF EXISTS Trees;
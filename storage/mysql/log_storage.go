@@ -18,8 +18,8 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"sync"
@@ -29,6 +29,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/storage"
@@ -53,6 +54,11 @@ const (
 			FROM LeafData l,SequencedLeafData s
 			WHERE l.LeafIdentityHash = s.LeafIdentityHash
 			AND s.SequenceNumber IN (` + placeholderSQL + `) AND l.TreeId = ? AND s.TreeId = l.TreeId`
+	selectLeavesByRangeSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData
+			FROM LeafData l,SequencedLeafData s
+			WHERE l.LeafIdentityHash = s.LeafIdentityHash
+			AND s.SequenceNumber >= ? AND s.SequenceNumber < ? AND l.TreeId = ? AND s.TreeId = l.TreeId
+			ORDER BY s.SequenceNumber`
 	selectLeavesByMerkleHashSQL = `SELECT s.MerkleLeafHash,l.LeafIdentityHash,l.LeafValue,s.SequenceNumber,l.ExtraData
 			FROM LeafData l,SequencedLeafData s
 			WHERE l.LeafIdentityHash = s.LeafIdentityHash
@@ -65,6 +71,10 @@ const (
 	selectLeavesByLeafIdentityHashSQL = `SELECT '` + dummyMerkleLeafHash + `',l.LeafIdentityHash,l.LeafValue,-1,l.ExtraData
 			FROM LeafData l
 			WHERE l.LeafIdentityHash IN (` + placeholderSQL + `) AND l.TreeId = ?`
+	selectSequencedIdentityHashesSQL = `SELECT LeafIdentityHash FROM SequencedLeafData
+			WHERE LeafIdentityHash IN (` + placeholderSQL + `) AND TreeId = ?`
+	selectUnsequencedIdentityHashesSQL = `SELECT LeafIdentityHash FROM Unsequenced
+			WHERE LeafIdentityHash IN (` + placeholderSQL + `) AND TreeId = ?`
 
 	// Same as above except with leaves ordered by sequence so we only incur this cost when necessary
 	orderBySequenceNumberSQL                     = " ORDER BY s.SequenceNumber"
@@ -213,10 +223,13 @@ func (m *mySQLLogStorage) beginInternal(ctx context.Context, treeID int64, reado
 		ctx,
 		m.admin,
 		treeID,
-		trees.GetOpts{TreeType: trillian.TreeType_LOG, Readonly: readonly})
+		trees.GetOpts{Readonly: readonly})
 	if err != nil {
 		return nil, err
 	}
+	if err := storage.CheckLogTreeType(tree); err != nil {
+		return nil, err
+	}
 	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
 	if err != nil {
 		return nil, err
@@ -231,6 +244,8 @@ func (m *mySQLLogStorage) beginInternal(ctx context.Context, treeID int64, reado
 	ltx := &logTreeTX{
 		treeTX: ttx,
 		ls:     m,
+		config: tree,
+		hasher: hasher,
 	}
 
 	ltx.root, err = ltx.fetchLatestRoot(ctx)
@@ -257,8 +272,10 @@ func (m *mySQLLogStorage) SnapshotForTree(ctx context.Context, treeID int64) (st
 
 type logTreeTX struct {
 	treeTX
-	ls   *mySQLLogStorage
-	root trillian.SignedLogRoot
+	ls     *mySQLLogStorage
+	config *trillian.Tree
+	root   trillian.SignedLogRoot
+	hasher hashers.LogHasher
 }
 
 func (t *logTreeTX) ReadRevision() int64 {
@@ -309,7 +326,7 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 		}
 
 		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
-			return nil, errors.New("dequeued a leaf with incorrect hash size")
+			return nil, fmt.Errorf("dequeued a leaf with incorrect hash size")
 		}
 
 		leaves = append(leaves, leaf)
@@ -343,6 +360,9 @@ func (t *logTreeTX) DequeueLeaves(ctx context.Context, limit int, cutoffTime tim
 }
 
 func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf, queueTimestamp time.Time) ([]*trillian.LogLeaf, error) {
+	if err := storage.CheckLeavesQueueable(t.config); err != nil {
+		return nil, err
+	}
 	// Don't accept batches if any of the leaves are invalid.
 	for _, leaf := range leaves {
 		if len(leaf.LeafIdentityHash) != t.hashSizeBytes {
@@ -447,6 +467,81 @@ func (t *logTreeTX) QueueLeaves(ctx context.Context, leaves []*trillian.LogLeaf,
 	return existingLeaves, nil
 }
 
+// RequeueLeaves re-creates the Unsequenced entries of the specified leaves,
+// which DequeueLeaves removes. Merkle leaf hashes aren't kept for unsequenced
+// leaves in LeafData, so they're recomputed from the leaf values.
+func (t *logTreeTX) RequeueLeaves(ctx context.Context, leafIdentityHashes [][]byte) (int, error) {
+	for _, hash := range leafIdentityHashes {
+		if len(hash) != t.hashSizeBytes {
+			return 0, errors.Errorf(errors.InvalidArgument, "requeued leaf must have a leaf ID hash of length %d", t.hashSizeBytes)
+		}
+	}
+	if len(leafIdentityHashes) == 0 {
+		return 0, nil
+	}
+	leaves, err := t.getLeafDataByIdentityHash(ctx, leafIdentityHashes)
+	if err != nil {
+		return 0, err
+	}
+
+	// Leaves that were integrated or are still queued aren't requeued.
+	skip := make(map[string]bool)
+	for _, query := range []string{selectSequencedIdentityHashesSQL, selectUnsequencedIdentityHashesSQL} {
+		if err := t.readIdentityHashes(ctx, query, leafIdentityHashes, skip); err != nil {
+			return 0, err
+		}
+	}
+
+	queueTimestamp := time.Now()
+	requeued := 0
+	for _, leaf := range leaves {
+		if skip[string(leaf.LeafIdentityHash)] {
+			continue
+		}
+
+		merkleLeafHash, err := t.hasher.HashLeaf(leaf.LeafValue)
+		if err != nil {
+			return 0, err
+		}
+		args := []interface{}{
+			t.treeID,
+			leaf.LeafIdentityHash,
+			merkleLeafHash,
+		}
+		args = append(args, queueArgs(t.treeID, leaf.LeafIdentityHash, queueTimestamp)...)
+		if _, err := t.tx.ExecContext(ctx, insertUnsequencedEntrySQL, args...); err != nil {
+			glog.Warningf("Error inserting into Unsequenced: %s", err)
+			return 0, fmt.Errorf("Unsequenced: %v", err)
+		}
+		requeued++
+	}
+	return requeued, nil
+}
+
+// readIdentityHashes runs query, a statement selecting the LeafIdentityHash of
+// rows matching a placeholderSQL of leafHashes and the tree ID, and adds the
+// returned hashes to found.
+func (t *logTreeTX) readIdentityHashes(ctx context.Context, query string, leafHashes [][]byte, found map[string]bool) error {
+	args := make([]interface{}, 0, len(leafHashes)+1)
+	for _, hash := range leafHashes {
+		args = append(args, hash)
+	}
+	args = append(args, t.treeID)
+	rows, err := t.tx.QueryContext(ctx, expandPlaceholderSQL(query, len(leafHashes), "?", "?"), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hash []byte
+		if err := rows.Scan(&hash); err != nil {
+			return err
+		}
+		found[string(hash)] = true
+	}
+	return rows.Err()
+}
+
 func (t *logTreeTX) GetSequencedLeafCount(ctx context.Context) (int64, error) {
 	var sequencedLeafCount int64
 
@@ -498,6 +593,46 @@ func (t *logTreeTX) GetLeavesByIndex(ctx context.Context, leaves []int64) ([]*tr
 	return ret, nil
 }
 
+func (t *logTreeTX) GetLeavesByRange(ctx context.Context, start, count int64) ([]*trillian.LogLeaf, error) {
+	switch {
+	case start < 0:
+		return nil, errors.Errorf(errors.OutOfRange, "invalid start index: %v", start)
+	case count <= 0:
+		return nil, errors.Errorf(errors.InvalidArgument, "invalid count: %v", count)
+	}
+	end := start + count
+	if end < start {
+		// Overflow, the range extends to the end of the tree.
+		end = math.MaxInt64
+	}
+
+	rows, err := t.tx.QueryContext(ctx, selectLeavesByRangeSQL, start, end, t.treeID)
+	if err != nil {
+		glog.Warningf("Failed to get leaves by range: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []*trillian.LogLeaf
+	for rows.Next() {
+		leaf := &trillian.LogLeaf{}
+		if err := rows.Scan(
+			&leaf.MerkleLeafHash,
+			&leaf.LeafIdentityHash,
+			&leaf.LeafValue,
+			&leaf.LeafIndex,
+			&leaf.ExtraData); err != nil {
+			glog.Warningf("Failed to scan merkle leaves: %s", err)
+			return nil, err
+		}
+		if want := start + int64(len(ret)); leaf.LeafIndex != want {
+			return nil, fmt.Errorf("got leaf index %v, want %v: range not contiguous", leaf.LeafIndex, want)
+		}
+		ret = append(ret, leaf)
+	}
+	return ret, rows.Err()
+}
+
 func (t *logTreeTX) GetLeavesByHash(ctx context.Context, leafHashes [][]byte, orderBySequence bool) ([]*trillian.LogLeaf, error) {
 	tmpl, err := t.ls.getLeavesByMerkleHashStmt(ctx, len(leafHashes), orderBySequence)
 	if err != nil {
@@ -524,12 +659,18 @@ func (t *logTreeTX) LatestSignedLogRoot(ctx context.Context) (trillian.SignedLog
 
 // fetchLatestRoot reads the latest SignedLogRoot from the DB and returns it.
 func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (trillian.SignedLogRoot, error) {
+	return fetchLatestRoot(ctx, t.tx, t.treeID)
+}
+
+// fetchLatestRoot reads the latest SignedLogRoot of treeID from the DB and
+// returns it. If the tree has no roots an empty root is returned.
+func fetchLatestRoot(ctx context.Context, tx *sql.Tx, treeID int64) (trillian.SignedLogRoot, error) {
 	var timestamp, treeSize, treeRevision int64
 	var rootHash, rootSignatureBytes []byte
 	var rootSignature spb.DigitallySigned
 
-	err := t.tx.QueryRowContext(
-		ctx, selectLatestSignedLogRootSQL, t.treeID).Scan(
+	err := tx.QueryRowContext(
+		ctx, selectLatestSignedLogRootSQL, treeID).Scan(
 		&timestamp, &treeSize, &rootHash, &treeRevision, &rootSignatureBytes)
 
 	// It's possible there are no roots for this tree yet
@@ -549,7 +690,7 @@ func (t *logTreeTX) fetchLatestRoot(ctx context.Context) (trillian.SignedLogRoot
 		TimestampNanos: timestamp,
 		TreeRevision:   treeRevision,
 		Signature:      &rootSignature,
-		LogId:          t.treeID,
+		LogId:          treeID,
 		TreeSize:       treeSize,
 	}, nil
 }
@@ -614,6 +755,37 @@ func (t *logTreeTX) getLeavesByHashInternal(ctx context.Context, leafHashes [][]
 	return ret, nil
 }
 
+func (t *readOnlyLogTX) GetLatestSignedLogRoots(ctx context.Context, treeIDs []int64) (map[int64]*trillian.SignedLogRoot, error) {
+	roots := make(map[int64]*trillian.SignedLogRoot)
+	for _, treeID := range treeIDs {
+		root, err := fetchLatestRoot(ctx, t.tx, treeID)
+		if err != nil {
+			return nil, err
+		}
+		if root.TimestampNanos == 0 {
+			// No roots yet
+			continue
+		}
+		roots[treeID] = &root
+	}
+	return roots, nil
+}
+
+// logDataSizeQueries sum the lengths of the variable-sized columns of each
+// table holding log data. Fixed-size columns, indexes and per-row overheads
+// are comparatively small, so they're not accounted for.
+var logDataSizeQueries = []string{
+	"SELECT COALESCE(SUM(LENGTH(LeafIdentityHash) + LENGTH(LeafValue) + COALESCE(LENGTH(ExtraData), 0)), 0) FROM LeafData WHERE TreeId = ?",
+	"SELECT COALESCE(SUM(LENGTH(LeafIdentityHash) + LENGTH(MerkleLeafHash)), 0) FROM SequencedLeafData WHERE TreeId = ?",
+	"SELECT COALESCE(SUM(LENGTH(LeafIdentityHash) + LENGTH(MerkleLeafHash)), 0) FROM Unsequenced WHERE TreeId = ?",
+	subtreeDataSizeQuery,
+	"SELECT COALESCE(SUM(LENGTH(RootHash) + LENGTH(RootSignature)), 0) FROM TreeHead WHERE TreeId = ?",
+}
+
+func (t *readOnlyLogTX) EstimateTreeStorageBytes(ctx context.Context, treeID int64) (int64, error) {
+	return estimateTreeStorageBytes(ctx, t.tx, treeID, logDataSizeQueries)
+}
+
 func (t *readOnlyLogTX) GetUnsequencedCounts(ctx context.Context) (storage.CountByLogID, error) {
 	stx, err := t.tx.PrepareContext(ctx, selectUnsequencedLeafCountSQL)
 	if err != nil {
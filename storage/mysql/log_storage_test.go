@@ -17,6 +17,9 @@ package mysql
 import (
 	"bytes"
 	"context"
+	gocrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
 	"database/sql"
 	"fmt"
@@ -25,7 +28,13 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
+	"github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/errors"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/testonly"
 	"github.com/kylelemons/godebug/pretty"
@@ -33,9 +42,10 @@ import (
 	spb "github.com/google/trillian/crypto/sigpb"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/google/trillian/merkle/rfc6962"
 )
 
-var allTables = []string{"Unsequenced", "TreeHead", "SequencedLeafData", "LeafData", "Subtree", "TreeControl", "Trees", "MapLeaf", "MapHead"}
+var allTables = []string{"Unsequenced", "TreeHead", "SequencedLeafData", "LeafData", "Subtree", "TreeControl", "TreeLabels", "TreeAppData", "TreeAliases", "TreeIdReservations", "TreeCountSnapshots", "Trees", "MapLeaf", "MapHead"}
 
 // Must be 32 bytes to match sha256 length if it was a real hash
 var dummyHash = []byte("hashxxxxhashxxxxhashxxxxhashxxxx")
@@ -315,6 +325,16 @@ func TestQueueDuplicateLeaf(t *testing.T) {
 	}
 }
 
+func TestMysqlLogStorage(t *testing.T) {
+	tester := &testonly.LogStorageTester{
+		NewStorage: func() (storage.AdminStorage, storage.LogStorage) {
+			cleanTestDB(DB)
+			return NewAdminStorage(DB), NewLogStorage(DB, nil)
+		},
+	}
+	tester.RunAllTests(t)
+}
+
 func TestQueueLeaves(t *testing.T) {
 	ctx := context.Background()
 
@@ -417,6 +437,164 @@ func TestDequeueLeaves(t *testing.T) {
 	}
 }
 
+func TestRequeueLeaves(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	logID := createLogForTests(DB)
+	s := NewLogStorage(DB, nil)
+
+	leaves := createTestLeaves(leavesToInsert, 0)
+	{
+		tx := beginLogTx(s, logID, t)
+		defer tx.Close()
+		if _, err := tx.QueueLeaves(ctx, leaves, fakeDequeueCutoffTime); err != nil {
+			t.Fatalf("Failed to queue leaves: %v", err)
+		}
+		commit(tx, t)
+	}
+
+	// Dequeue all leaves, but only integrate the first one, leaving the rest
+	// stuck.
+	integrated := leaves[0]
+	{
+		tx := beginLogTx(s, logID, t)
+		defer tx.Close()
+		dequeued, err := tx.DequeueLeaves(ctx, 99, fakeDequeueCutoffTime)
+		if err != nil {
+			t.Fatalf("Failed to dequeue leaves: %v", err)
+		}
+		if len(dequeued) != leavesToInsert {
+			t.Fatalf("Dequeued %d leaves but expected to get %d", len(dequeued), leavesToInsert)
+		}
+		integrated.LeafIndex = 0
+		if err := tx.UpdateSequencedLeaves(ctx, []*trillian.LogLeaf{integrated}); err != nil {
+			t.Fatalf("UpdateSequencedLeaves() returned err = %v", err)
+		}
+		commit(tx, t)
+	}
+
+	// Requeue the integrated leaf and all but one of the stuck ones.
+	requeue := leaves[:len(leaves)-1]
+	var hashes [][]byte
+	for _, leaf := range requeue {
+		hashes = append(hashes, leaf.LeafIdentityHash)
+	}
+	{
+		tx := beginLogTx(s, logID, t)
+		defer tx.Close()
+		n, err := tx.RequeueLeaves(ctx, hashes)
+		if err != nil {
+			t.Fatalf("RequeueLeaves() returned err = %v", err)
+		}
+		if got, want := n, len(requeue)-1; got != want {
+			t.Errorf("RequeueLeaves() = %v, want = %v", got, want)
+		}
+		commit(tx, t)
+	}
+
+	// Leaves that are already queued aren't requeued again, and malformed
+	// hashes are rejected.
+	{
+		tx := beginLogTx(s, logID, t)
+		defer tx.Close()
+		n, err := tx.RequeueLeaves(ctx, hashes)
+		if err != nil {
+			t.Fatalf("RequeueLeaves() returned err = %v", err)
+		}
+		if n != 0 {
+			t.Errorf("RequeueLeaves() of queued leaves = %v, want = 0", n)
+		}
+		if _, err := tx.RequeueLeaves(ctx, [][]byte{[]byte("short")}); errors.ErrorCode(err) != errors.InvalidArgument {
+			t.Errorf("RequeueLeaves(short hash) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+		}
+		commit(tx, t)
+	}
+
+	{
+		tx := beginLogTx(s, logID, t)
+		defer tx.Close()
+		dequeued, err := tx.DequeueLeaves(ctx, 99, time.Now())
+		if err != nil {
+			t.Fatalf("Failed to dequeue leaves: %v", err)
+		}
+		if got, want := len(dequeued), len(requeue)-1; got != want {
+			t.Fatalf("Dequeued %d leaves but expected to get %d", got, want)
+		}
+		for _, leaf := range dequeued {
+			if bytes.Equal(leaf.LeafIdentityHash, integrated.LeafIdentityHash) {
+				t.Errorf("Dequeued integrated leaf %x", leaf.LeafIdentityHash)
+			}
+			if !leafInBatch(leaf, requeue) {
+				t.Errorf("Dequeued leaf %x that wasn't requeued", leaf.LeafIdentityHash)
+			}
+		}
+		count, err := tx.GetSequencedLeafCount(ctx)
+		if err != nil {
+			t.Fatalf("GetSequencedLeafCount() returned err = %v", err)
+		}
+		if count != 1 {
+			t.Errorf("GetSequencedLeafCount() = %v, want = 1", count)
+		}
+		commit(tx, t)
+	}
+}
+
+func TestEstimateTreeStorageBytes(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	logID := createLogForTests(DB)
+	s := NewLogStorage(DB, nil)
+
+	estimate := func(treeID int64) (int64, error) {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Close()
+		size, err := tx.EstimateTreeStorageBytes(ctx, treeID)
+		if err != nil {
+			return 0, err
+		}
+		return size, tx.Commit()
+	}
+	queue := func(n, start int64) {
+		tx := beginLogTx(s, logID, t)
+		defer tx.Close()
+		if _, err := tx.QueueLeaves(ctx, createTestLeaves(n, start), fakeQueueTime); err != nil {
+			t.Fatalf("Failed to queue leaves: %v", err)
+		}
+		commit(tx, t)
+	}
+
+	empty, err := estimate(logID)
+	if err != nil {
+		t.Fatalf("EstimateTreeStorageBytes() returned err = %v", err)
+	}
+	queue(10, 0)
+	small, err := estimate(logID)
+	if err != nil {
+		t.Fatalf("EstimateTreeStorageBytes() returned err = %v", err)
+	}
+	if small <= empty {
+		t.Errorf("EstimateTreeStorageBytes() = %v after queueing leaves, want > %v", small, empty)
+	}
+	queue(100, 10)
+	large, err := estimate(logID)
+	if err != nil {
+		t.Fatalf("EstimateTreeStorageBytes() returned err = %v", err)
+	}
+	// 10x the leaves should be roughly 10x the size, give or take.
+	if got, want := large-small, 5*(small-empty); got < want {
+		t.Errorf("EstimateTreeStorageBytes() grew by %v bytes after queueing 10x the leaves, want >= %v", got, want)
+	}
+
+	if _, err := estimate(logID + 1); errors.ErrorCode(err) != errors.NotFound {
+		t.Errorf("EstimateTreeStorageBytes(unknown tree) returned err = %v, wantCode = %s", err, errors.NotFound)
+	}
+}
+
 func TestDequeueLeavesTwoBatches(t *testing.T) {
 	ctx := context.Background()
 
@@ -746,6 +924,69 @@ func TestGetLeavesByIndex(t *testing.T) {
 	commit(tx, t)
 }
 
+func TestGetLeavesByRange(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	logID := createLogForTests(DB)
+	s := NewLogStorage(DB, nil)
+
+	// Create fake leaves 0..leafCount-1 as if they had been sequenced
+	const leafCount = 10
+	for i := int64(0); i < leafCount; i++ {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("hash %d", i)))
+		rawHash := sha256.Sum256([]byte(fmt.Sprintf("raw hash %d", i)))
+		data := []byte(fmt.Sprintf("data %d", i))
+		createFakeLeaf(ctx, DB, logID, rawHash[:], hash[:], data, someExtraData, i, t)
+	}
+
+	tests := []struct {
+		desc         string
+		start, count int64
+		wantStart    int64
+		wantLen      int
+		wantErr      bool
+		wantCode     errors.Code
+	}{
+		{desc: "all", start: 0, count: leafCount, wantLen: leafCount},
+		{desc: "middle", start: 3, count: 4, wantStart: 3, wantLen: 4},
+		{desc: "partialAtEnd", start: 7, count: 5, wantStart: 7, wantLen: 3},
+		{desc: "pastEnd", start: leafCount, count: 5},
+		{desc: "negativeStart", start: -1, count: 5, wantErr: true, wantCode: errors.OutOfRange},
+		{desc: "zeroCount", start: 0, count: 0, wantErr: true, wantCode: errors.InvalidArgument},
+	}
+	for _, test := range tests {
+		tx := beginLogTx(s, logID, t)
+		leaves, err := tx.GetLeavesByRange(ctx, test.start, test.count)
+		if hasErr := err != nil; hasErr != test.wantErr {
+			t.Errorf("%v: GetLeavesByRange() = (_, %v), wantErr = %v", test.desc, err, test.wantErr)
+			tx.Close()
+			continue
+		} else if hasErr {
+			if got := errors.ErrorCode(err); got != test.wantCode {
+				t.Errorf("%v: GetLeavesByRange() returned code %v, want %v", test.desc, got, test.wantCode)
+			}
+			tx.Close()
+			continue
+		}
+		commit(tx, t)
+
+		if got := len(leaves); got != test.wantLen {
+			t.Errorf("%v: GetLeavesByRange() returned %v leaves, want %v", test.desc, got, test.wantLen)
+			continue
+		}
+		for i, leaf := range leaves {
+			want := test.wantStart + int64(i)
+			if leaf.LeafIndex != want {
+				t.Errorf("%v: leaves[%v].LeafIndex = %v, want %v", test.desc, i, leaf.LeafIndex, want)
+			}
+			if got, wantData := leaf.LeafValue, []byte(fmt.Sprintf("data %d", want)); !bytes.Equal(got, wantData) {
+				t.Errorf("%v: leaves[%v].LeafValue = %s, want %s", test.desc, i, got, wantData)
+			}
+		}
+	}
+}
+
 func TestLatestSignedRootNoneWritten(t *testing.T) {
 	ctx := context.Background()
 
@@ -803,6 +1044,241 @@ func TestLatestSignedLogRoot(t *testing.T) {
 	}
 }
 
+func TestGetTreeAndLatestRoot(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	logID := createLogForTests(DB)
+	as := NewAdminStorage(DB)
+	s := NewLogStorage(DB, nil)
+
+	// No roots yet
+	tree, root, err := storage.GetTreeAndLatestRoot(ctx, as, s, logID)
+	if err != nil {
+		t.Fatalf("GetTreeAndLatestRoot() = (_, _, %v), want = (_, _, nil)", err)
+	}
+	if tree.TreeId != logID {
+		t.Errorf("GetTreeAndLatestRoot() returned TreeId = %v, want = %v", tree.TreeId, logID)
+	}
+	if root != nil {
+		t.Errorf("GetTreeAndLatestRoot() returned root = %v, want = nil", root)
+	}
+
+	tx := beginLogTx(s, logID, t)
+	defer tx.Close()
+	if err := tx.StoreSignedLogRoot(ctx, trillian.SignedLogRoot{
+		LogId:          logID,
+		TimestampNanos: 98765,
+		TreeSize:       16,
+		TreeRevision:   5,
+		RootHash:       []byte(dummyHash),
+		Signature:      &spb.DigitallySigned{Signature: []byte("notempty")},
+	}); err != nil {
+		t.Fatalf("Failed to store signed root: %v", err)
+	}
+	commit(tx, t)
+
+	tree, root, err = storage.GetTreeAndLatestRoot(ctx, as, s, logID)
+	if err != nil {
+		t.Fatalf("GetTreeAndLatestRoot() = (_, _, %v), want = (_, _, nil)", err)
+	}
+
+	// Compare against separate reads
+	atx, err := as.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer atx.Close()
+	wantTree, err := atx.GetTree(ctx, logID)
+	if err != nil {
+		t.Fatalf("GetTree() = (_, %v), want = (_, nil)", err)
+	}
+	commit(atx, t)
+	ltx := beginLogTx(s, logID, t)
+	defer ltx.Close()
+	wantRoot, err := ltx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot() = (_, %v), want = (_, nil)", err)
+	}
+	commit(ltx, t)
+
+	if !proto.Equal(tree, wantTree) {
+		t.Errorf("GetTreeAndLatestRoot() tree diff (-got +want):\n%v", pretty.Compare(tree, wantTree))
+	}
+	if root == nil || !proto.Equal(root, &wantRoot) {
+		t.Errorf("GetTreeAndLatestRoot() root = %v, want = %v", root, wantRoot)
+	}
+}
+
+func TestGetLatestSignedLogRoots(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	logID1 := createLogForTests(DB)
+	logID2 := createLogForTests(DB)
+	noRootsLogID := createLogForTests(DB)
+	s := NewLogStorage(DB, nil)
+
+	newRoot := func(logID, timestamp, treeSize int64) trillian.SignedLogRoot {
+		return trillian.SignedLogRoot{
+			LogId:          logID,
+			TimestampNanos: timestamp,
+			TreeSize:       treeSize,
+			TreeRevision:   treeSize,
+			RootHash:       []byte(dummyHash),
+			Signature:      &spb.DigitallySigned{Signature: []byte("notempty")},
+		}
+	}
+	roots := []trillian.SignedLogRoot{
+		newRoot(logID1, 1000, 1),
+		newRoot(logID1, 2000, 5),
+		newRoot(logID2, 1500, 3),
+	}
+	for _, root := range roots {
+		tx := beginLogTx(s, root.LogId, t)
+		if err := tx.StoreSignedLogRoot(ctx, root); err != nil {
+			tx.Close()
+			t.Fatalf("Failed to store signed root: %v", err)
+		}
+		commit(tx, t)
+	}
+
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	got, err := tx.GetLatestSignedLogRoots(ctx, []int64{logID1, logID2, noRootsLogID})
+	if err != nil {
+		t.Fatalf("GetLatestSignedLogRoots() = (_, %v), want = (_, nil)", err)
+	}
+	commit(tx, t)
+
+	want := map[int64]*trillian.SignedLogRoot{
+		logID1: &roots[1],
+		logID2: &roots[2],
+	}
+	if len(got) != len(want) {
+		t.Errorf("GetLatestSignedLogRoots() returned %v roots, want = %v", len(got), len(want))
+	}
+	for logID, wantRoot := range want {
+		if root, ok := got[logID]; !ok || !proto.Equal(root, wantRoot) {
+			t.Errorf("GetLatestSignedLogRoots()[%v] = %v, want = %v", logID, root, wantRoot)
+		}
+	}
+}
+
+func TestCreateLogTreeWithInitialRoot(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	adminStorage := NewAdminStorage(DB)
+	logStorage := NewLogStorage(DB, nil)
+
+	var privateKey keyspb.PrivateKey
+	if err := ptypes.UnmarshalAny(testonly.LogTree.PrivateKey, &privateKey); err != nil {
+		t.Fatalf("UnmarshalAny() = %v, want = nil", err)
+	}
+	signer, err := der.FromProto(&privateKey)
+	if err != nil {
+		t.Fatalf("FromProto() = (_, %v), want = (_, nil)", err)
+	}
+
+	tree, err := storage.CreateLogTreeWithInitialRoot(ctx, adminStorage, logStorage, testonly.LogTree, signer)
+	if err != nil {
+		t.Fatalf("CreateLogTreeWithInitialRoot() = (_, %v), want = (_, nil)", err)
+	}
+	root := latestSignedLogRoot(ctx, t, logStorage, tree.TreeId)
+
+	hasher, err := hashers.NewLogHasher(tree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewLogHasher() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := root.TreeSize, int64(0); got != want {
+		t.Errorf("root.TreeSize = %v, want = %v", got, want)
+	}
+	if got, want := root.RootHash, hasher.EmptyRoot(); !bytes.Equal(got, want) {
+		t.Errorf("root.RootHash = %x, want = %x", got, want)
+	}
+	if got, want := root.LogId, tree.TreeId; got != want {
+		t.Errorf("root.LogId = %v, want = %v", got, want)
+	}
+	hash, err := crypto.HashLogRoot(root)
+	if err != nil {
+		t.Fatalf("HashLogRoot() = (_, %v), want = (_, nil)", err)
+	}
+	if err := crypto.Verify(signer.Public(), hash, root.Signature); err != nil {
+		t.Errorf("Verify(root) = %v, want = nil", err)
+	}
+
+	// A signer that doesn't match the tree's public key is rejected.
+	otherTree := *testonly.LogTree
+	otherTree.PublicKey = testonly.MapTree.PublicKey
+	if _, err := storage.CreateLogTreeWithInitialRoot(ctx, adminStorage, logStorage, &otherTree, signer); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("CreateLogTreeWithInitialRoot(mismatched signer) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+}
+
+func TestCreateLogTreeWithInitialRootPSS(t *testing.T) {
+	ctx := context.Background()
+
+	cleanTestDB(DB)
+	adminStorage := NewAdminStorage(DB)
+	logStorage := NewLogStorage(DB, nil)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() = (_, %v), want = (_, nil)", err)
+	}
+	keyDER, err := der.MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey() = (_, %v), want = (_, nil)", err)
+	}
+	publicKey, err := der.ToPublicProto(key.Public())
+	if err != nil {
+		t.Fatalf("ToPublicProto() = (_, %v), want = (_, nil)", err)
+	}
+	privateKey, err := ptypes.MarshalAny(&keyspb.PrivateKey{Der: keyDER})
+	if err != nil {
+		t.Fatalf("MarshalAny() = (_, %v), want = (_, nil)", err)
+	}
+	pssTree := proto.Clone(testonly.LogTree).(*trillian.Tree)
+	pssTree.SignatureAlgorithm = spb.DigitallySigned_RSA
+	pssTree.SignatureParams = &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PSS}
+	pssTree.PrivateKey = privateKey
+	pssTree.PublicKey = publicKey
+
+	tree, err := storage.CreateLogTreeWithInitialRoot(ctx, adminStorage, logStorage, pssTree, key)
+	if err != nil {
+		t.Fatalf("CreateLogTreeWithInitialRoot() = (_, %v), want = (_, nil)", err)
+	}
+	root := latestSignedLogRoot(ctx, t, logStorage, tree.TreeId)
+
+	hash, err := crypto.HashLogRoot(root)
+	if err != nil {
+		t.Fatalf("HashLogRoot() = (_, %v), want = (_, nil)", err)
+	}
+	opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: gocrypto.SHA256}
+	if err := crypto.VerifyWithOpts(key.Public(), hash, root.Signature, opts); err != nil {
+		t.Errorf("VerifyWithOpts(root, PSS) = %v, want = nil", err)
+	}
+	if err := crypto.Verify(key.Public(), hash, root.Signature); err == nil {
+		t.Error("Verify(root, PKCS1v15) = nil, want = non-nil")
+	}
+}
+
+// latestSignedLogRoot reads the latest root of treeID in its own transaction.
+func latestSignedLogRoot(ctx context.Context, t *testing.T, logStorage storage.LogStorage, treeID int64) trillian.SignedLogRoot {
+	tx := beginLogTx(logStorage, treeID, t)
+	defer tx.Close()
+	root, err := tx.LatestSignedLogRoot(ctx)
+	if err != nil {
+		t.Fatalf("LatestSignedLogRoot() = (_, %v), want = (_, nil)", err)
+	}
+	commit(tx, t)
+	return root
+}
+
 func TestDuplicateSignedLogRoot(t *testing.T) {
 	ctx := context.Background()
 
@@ -20,6 +20,8 @@ import (
 	"fmt"
 
 	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+	"github.com/google/trillian/merkle"
 	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
@@ -89,6 +91,17 @@ func (m *mySQLMapStorage) Snapshot(ctx context.Context) (storage.ReadOnlyMapTX,
 	return &readOnlyMapTX{tx}, nil
 }
 
+// mapDataSizeQueries are like logDataSizeQueries, but for map data.
+var mapDataSizeQueries = []string{
+	"SELECT COALESCE(SUM(LENGTH(KeyHash) + LENGTH(LeafValue)), 0) FROM MapLeaf WHERE TreeId = ?",
+	subtreeDataSizeQuery,
+	"SELECT COALESCE(SUM(LENGTH(RootHash) + LENGTH(RootSignature) + COALESCE(LENGTH(MapperData), 0)), 0) FROM MapHead WHERE TreeId = ?",
+}
+
+func (t *readOnlyMapTX) EstimateTreeStorageBytes(ctx context.Context, treeID int64) (int64, error) {
+	return estimateTreeStorageBytes(ctx, t.Tx, treeID, mapDataSizeQueries)
+}
+
 func (t *readOnlyMapTX) Close() error {
 	if err := t.Rollback(); err != nil && err != sql.ErrTxDone {
 		glog.Warningf("Rollback error on Close(): %v", err)
@@ -120,6 +133,7 @@ func (m *mySQLMapStorage) begin(ctx context.Context, treeID int64, readonly bool
 	mtx := &mapTreeTX{
 		treeTX: ttx,
 		ms:     m,
+		hasher: hasher,
 	}
 
 	mtx.root, err = mtx.LatestSignedMapRoot(ctx)
@@ -144,8 +158,9 @@ func (m *mySQLMapStorage) SnapshotForTree(ctx context.Context, treeID int64) (st
 
 type mapTreeTX struct {
 	treeTX
-	ms   *mySQLMapStorage
-	root trillian.SignedMapRoot
+	ms     *mySQLMapStorage
+	hasher hashers.MapHasher
+	root   trillian.SignedMapRoot
 }
 
 func (m *mapTreeTX) ReadRevision() int64 {
@@ -233,6 +248,51 @@ func (m *mapTreeTX) Get(ctx context.Context, revision int64, indexes [][]byte) (
 	return ret, nil
 }
 
+func (m *mapTreeTX) GetMapLeavesWithProof(ctx context.Context, revision int64, indexes [][]byte) ([]*trillian.MapLeafInclusion, error) {
+	for _, index := range indexes {
+		if got, want := len(index), m.hasher.Size(); got != want {
+			return nil, errors.Errorf(errors.InvalidArgument, "index len(%x): %v, want %v", index, got, want)
+		}
+	}
+
+	leaves, err := m.Get(ctx, revision, indexes)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]*trillian.MapLeaf)
+	for i := range leaves {
+		found[string(leaves[i].Index)] = &leaves[i]
+	}
+
+	smtReader := merkle.NewSparseMerkleTreeReader(revision, m.hasher, m)
+	inclusions := make([]*trillian.MapLeafInclusion, 0, len(indexes))
+	for _, index := range indexes {
+		leaf, ok := found[string(index)]
+		if !ok {
+			// Empty leaf for proof of non-existence.
+			leafHash, err := m.hasher.HashLeaf(m.treeID, index, nil)
+			if err != nil {
+				return nil, fmt.Errorf("HashLeaf(nil): %v", err)
+			}
+			leaf = &trillian.MapLeaf{
+				Index:    index,
+				LeafHash: leafHash,
+			}
+		}
+
+		// Fetch the proof regardless of whether the leaf exists.
+		proof, err := smtReader.InclusionProof(ctx, revision, index)
+		if err != nil {
+			return nil, fmt.Errorf("could not get inclusion proof for leaf %x: %v", index, err)
+		}
+		inclusions = append(inclusions, &trillian.MapLeafInclusion{
+			Leaf:      leaf,
+			Inclusion: proof,
+		})
+	}
+	return inclusions, nil
+}
+
 func (m *mapTreeTX) GetSignedMapRoot(ctx context.Context, revision int64) (trillian.SignedMapRoot, error) {
 	var timestamp, mapRevision int64
 	var rootHash, rootSignatureBytes []byte
@@ -15,6 +15,7 @@
 package mysql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"testing"
@@ -23,13 +24,19 @@ import (
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/errors"
 	"github.com/google/trillian/examples/ct/ctmapper/ctmapperpb"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/hashers"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/testdb"
 	"github.com/google/trillian/testonly"
 	"github.com/kylelemons/godebug/pretty"
 
 	spb "github.com/google/trillian/crypto/sigpb"
+	storageto "github.com/google/trillian/storage/testonly"
+
+	_ "github.com/google/trillian/merkle/maphasher"
 )
 
 func TestMySQLMapStorage_CheckDatabaseAccessible(t *testing.T) {
@@ -288,6 +295,54 @@ func TestMapSetGetRoundTrip(t *testing.T) {
 	}
 }
 
+func TestMapEstimateTreeStorageBytes(t *testing.T) {
+	if provider := testdb.Default(); !provider.IsMySQL() {
+		t.Skipf("Inhibited due to known issue (#896) on SQL driver: %q", provider.Driver)
+	}
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	mapID := createInitializedMapForTests(ctx, t, DB)
+	s := NewMapStorage(DB)
+
+	estimate := func(treeID int64) (int64, error) {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Close()
+		size, err := tx.EstimateTreeStorageBytes(ctx, treeID)
+		if err != nil {
+			return 0, err
+		}
+		return size, tx.Commit()
+	}
+
+	empty, err := estimate(mapID)
+	if err != nil {
+		t.Fatalf("EstimateTreeStorageBytes() returned err = %v", err)
+	}
+	{
+		tx := beginMapTx(ctx, s, mapID, t)
+		defer tx.Close()
+		if err := tx.Set(ctx, keyHash, mapLeaf); err != nil {
+			t.Fatalf("Failed to set %v to %v: %v", keyHash, mapLeaf, err)
+		}
+		commit(tx, t)
+	}
+	size, err := estimate(mapID)
+	if err != nil {
+		t.Fatalf("EstimateTreeStorageBytes() returned err = %v", err)
+	}
+	if size <= empty {
+		t.Errorf("EstimateTreeStorageBytes() = %v after setting a leaf, want > %v", size, empty)
+	}
+
+	if _, err := estimate(mapID + 1); errors.ErrorCode(err) != errors.NotFound {
+		t.Errorf("EstimateTreeStorageBytes(unknown tree) returned err = %v, wantCode = %s", err, errors.NotFound)
+	}
+}
+
 func TestMapSetSameKeyInSameRevisionFails(t *testing.T) {
 	if provider := testdb.Default(); !provider.IsMySQL() {
 		t.Skipf("Inhibited due to known issue (#896) on SQL driver: %q", provider.Driver)
@@ -414,6 +469,107 @@ func TestMapSetGetMultipleRevisions(t *testing.T) {
 	}
 }
 
+func TestGetMapLeavesWithProof(t *testing.T) {
+	if provider := testdb.Default(); !provider.IsMySQL() {
+		t.Skipf("Inhibited due to known issue (#896) on SQL driver: %q", provider.Driver)
+	}
+
+	cleanTestDB(DB)
+	ctx := context.Background()
+	mapID := createInitializedMapForTests(ctx, t, DB)
+	s := NewMapStorage(DB)
+	hasher, err := hashers.NewMapHasher(storageto.MapTree.HashStrategy)
+	if err != nil {
+		t.Fatalf("NewMapHasher() = (_, %v), want = (_, nil)", err)
+	}
+
+	index := func(i byte) []byte {
+		index := make([]byte, hasher.Size())
+		index[0] = i
+		return index
+	}
+	present := [][]byte{index(1), index(2), index(3)}
+	absent := [][]byte{index(4), index(200)}
+
+	// Write the leaves and the sparse Merkle tree nodes, as per a map server.
+	var rev int64
+	{
+		tx := beginMapTx(ctx, s, mapID, t)
+		defer tx.Close()
+		rev = tx.WriteRevision()
+
+		smtWriter, err := merkle.NewSparseMerkleTreeWriter(ctx, mapID, rev, hasher, func() (storage.TreeTX, error) {
+			return s.BeginForTree(ctx, mapID)
+		})
+		if err != nil {
+			t.Fatalf("NewSparseMerkleTreeWriter() = (_, %v), want = (_, nil)", err)
+		}
+		for i, index := range present {
+			value := []byte{byte(i)}
+			leafHash, err := hasher.HashLeaf(mapID, index, value)
+			if err != nil {
+				t.Fatalf("HashLeaf() = (_, %v), want = (_, nil)", err)
+			}
+			leaf := trillian.MapLeaf{Index: index, LeafHash: leafHash, LeafValue: value}
+			if err := tx.Set(ctx, index, leaf); err != nil {
+				t.Fatalf("Set(%x) = %v, want = nil", index, err)
+			}
+			if err := smtWriter.SetLeaves(ctx, []merkle.HashKeyValue{{HashedKey: index, HashedValue: leafHash}}); err != nil {
+				t.Fatalf("SetLeaves(%x) = %v, want = nil", index, err)
+			}
+		}
+		rootHash, err := smtWriter.CalculateRoot()
+		if err != nil {
+			t.Fatalf("CalculateRoot() = (_, %v), want = (_, nil)", err)
+		}
+		root := trillian.SignedMapRoot{
+			RootHash:    rootHash,
+			Signature:   &sigpb.DigitallySigned{Signature: []byte("sig")},
+			MapId:       mapID,
+			MapRevision: rev,
+		}
+		if err := tx.StoreSignedMapRoot(ctx, root); err != nil {
+			t.Fatalf("StoreSignedMapRoot() = %v, want = nil", err)
+		}
+		commit(tx, t)
+	}
+
+	tx, err := s.SnapshotForTree(ctx, mapID)
+	if err != nil {
+		t.Fatalf("SnapshotForTree() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	root, err := tx.GetSignedMapRoot(ctx, rev)
+	if err != nil {
+		t.Fatalf("GetSignedMapRoot() = (_, %v), want = (_, nil)", err)
+	}
+	indexes := append(append([][]byte{}, present...), absent...)
+	inclusions, err := tx.GetMapLeavesWithProof(ctx, rev, indexes)
+	if err != nil {
+		t.Fatalf("GetMapLeavesWithProof() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := len(inclusions), len(indexes); got != want {
+		t.Fatalf("GetMapLeavesWithProof() returned %v inclusions, want %v", got, want)
+	}
+	for i, inclusion := range inclusions {
+		leaf := inclusion.Leaf
+		if !bytes.Equal(leaf.Index, indexes[i]) {
+			t.Errorf("inclusions[%v].Leaf.Index = %x, want %x", i, leaf.Index, indexes[i])
+		}
+		if wantValue := i < len(present); (len(leaf.LeafValue) != 0) != wantValue {
+			t.Errorf("inclusions[%v].Leaf.LeafValue = %x, want present = %v", i, leaf.LeafValue, wantValue)
+		}
+		if err := merkle.VerifyMapInclusionProof(mapID, leaf.Index, leaf.LeafValue, root.RootHash, inclusion.Inclusion, hasher); err != nil {
+			t.Errorf("VerifyMapInclusionProof(%x) = %v, want = nil", leaf.Index, err)
+		}
+	}
+
+	if _, err := tx.GetMapLeavesWithProof(ctx, rev, [][]byte{[]byte("short")}); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("GetMapLeavesWithProof(short index) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+	commit(tx, t)
+}
+
 func TestGetSignedMapRootNotExist(t *testing.T) {
 	if provider := testdb.Default(); !provider.IsMySQL() {
 		t.Skipf("Inhibited due to known issue (#896) on SQL driver: %q", provider.Driver)
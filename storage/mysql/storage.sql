@@ -8,8 +8,8 @@
 -- render the data in the tree unusable or inconsistent.
 CREATE TABLE IF NOT EXISTS Trees(
   TreeId                BIGINT NOT NULL,
-  TreeState             ENUM('ACTIVE', 'FROZEN') NOT NULL,
-  TreeType              ENUM('LOG', 'MAP') NOT NULL,
+  TreeState             ENUM('ACTIVE', 'FROZEN', 'DRAINING', 'MAINTENANCE') NOT NULL,
+  TreeType              ENUM('LOG', 'MAP', 'PREORDERED_LOG') NOT NULL,
   HashStrategy          ENUM('RFC6962_SHA256', 'TEST_MAP_HASHER', 'OBJECT_RFC6962_SHA256', 'CONIKS_SHA512_256') NOT NULL,
   HashAlgorithm         ENUM('SHA256') NOT NULL,
   SignatureAlgorithm    ENUM('ECDSA', 'RSA') NOT NULL,
@@ -22,6 +22,22 @@ CREATE TABLE IF NOT EXISTS Trees(
   PublicKey             MEDIUMBLOB NOT NULL,
   Deleted               BOOLEAN,
   DeleteTimeMillis      BIGINT,
+  WritesDisabled        BOOLEAN NOT NULL DEFAULT FALSE,
+  -- NULL if the tree was never sequenced.
+  LastSequencedTimeMillis BIGINT,
+  -- Marshaled trillian.SignatureParams, NULL if unset.
+  SignatureParams       MEDIUMBLOB,
+  SequencingPaused      BOOLEAN NOT NULL DEFAULT FALSE,
+  ExternalRef           VARCHAR(256),
+  -- NULL if unspecified.
+  PreferredReadConsistency ENUM('STRONG', 'EVENTUAL'),
+  -- TreeConfigHash of the stored tree, NULL if unknown.
+  ConfigChecksum        VARBINARY(32),
+  -- NULL if the tree isn't a clone.
+  ClonedFrom            BIGINT,
+  -- Incremented on every write to the tree, see the trillian.Tree revision
+  -- field.
+  Revision              BIGINT NOT NULL DEFAULT 1,
   PRIMARY KEY(TreeId)
 );
 
@@ -36,6 +52,49 @@ CREATE TABLE IF NOT EXISTS TreeControl(
   FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
 );
 
+-- Labels attached to trees, see the trillian.Tree labels field.
+-- LabelIndex preserves the (canonical, sorted) order of labels.
+CREATE TABLE IF NOT EXISTS TreeLabels(
+  TreeId                  BIGINT NOT NULL,
+  Label                   VARCHAR(50) NOT NULL,
+  LabelIndex              INTEGER NOT NULL,
+  PRIMARY KEY(TreeId, Label),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS TreeAppData(
+  TreeId                  BIGINT NOT NULL,
+  AppDataKey              VARCHAR(255) NOT NULL,
+  AppDataValue            MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(TreeId, AppDataKey),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- Human-readable names for trees. Each alias points to a single tree, and may be
+-- repointed to a different tree.
+CREATE TABLE IF NOT EXISTS TreeAliases(
+  Alias                   VARCHAR(255) NOT NULL,
+  TreeId                  BIGINT NOT NULL,
+  PRIMARY KEY(Alias),
+  FOREIGN KEY(TreeId) REFERENCES Trees(TreeId) ON DELETE CASCADE
+);
+
+-- Tree IDs allocated ahead of tree creation. Expired reservations may be
+-- removed at any time.
+CREATE TABLE IF NOT EXISTS TreeIdReservations(
+  TreeId                  BIGINT NOT NULL,
+  ExpiryTimeMillis        BIGINT NOT NULL,
+  PRIMARY KEY(TreeId)
+);
+
+-- Periodic snapshots of the number of trees in storage, for capacity tracking.
+CREATE TABLE IF NOT EXISTS TreeCountSnapshots(
+  SnapshotTimeMillis      BIGINT NOT NULL,
+  ActiveTrees             BIGINT NOT NULL,
+  DeletedTrees            BIGINT NOT NULL,
+  PRIMARY KEY(SnapshotTimeMillis)
+);
+
 CREATE TABLE IF NOT EXISTS Subtree(
   TreeId               BIGINT NOT NULL,
   SubtreeId            VARBINARY(255) NOT NULL,
@@ -128,6 +187,11 @@ CREATE TABLE IF NOT EXISTS Unsequenced(
   PRIMARY KEY (TreeId, Bucket, QueueTimestampNanos, LeafIdentityHash)
 );
 
+-- Looks up queued leaves by identity, e.g. so requeued leaves aren't queued
+-- twice.
+CREATE INDEX UnsequencedLeafIdentityHashIdx
+  ON Unsequenced(TreeId, LeafIdentityHash);
+
 
 -- ---------------------------------------------
 -- Map specific stuff here
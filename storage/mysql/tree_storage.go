@@ -25,6 +25,7 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian/errors"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/storage/cache"
 	"github.com/google/trillian/storage/storagepb"
@@ -52,6 +53,10 @@ const (
  AND Subtree.SubtreeRevision = x.MaxRevision 
  AND Subtree.TreeId = ?`
 	placeholderSQL = "<placeholder>"
+
+	// subtreeDataSizeQuery sums the lengths of the Subtree columns of a tree,
+	// see estimateTreeStorageBytes.
+	subtreeDataSizeQuery = "SELECT COALESCE(SUM(LENGTH(SubtreeId) + LENGTH(Nodes)), 0) FROM Subtree WHERE TreeId = ?"
 )
 
 // mySQLTreeStorage is shared between the mySQLLog- and (forthcoming) mySQLMap-
@@ -431,3 +436,26 @@ func checkDatabaseAccessible(ctx context.Context, db *sql.DB) error {
 	_, err = stmt.ExecContext(ctx)
 	return err
 }
+
+// estimateTreeStorageBytes sums the results of queries, each taking treeID and
+// returning a size in bytes, as per ReadOnlyLogTX.EstimateTreeStorageBytes.
+// Returns a NotFound error if treeID doesn't exist.
+func estimateTreeStorageBytes(ctx context.Context, tx *sql.Tx, treeID int64, queries []string) (int64, error) {
+	var id int64
+	switch err := tx.QueryRowContext(ctx, "SELECT TreeId FROM Trees WHERE TreeId = ?", treeID).Scan(&id); {
+	case err == sql.ErrNoRows:
+		return 0, errors.Errorf(errors.NotFound, "tree %v not found", treeID)
+	case err != nil:
+		return 0, err
+	}
+
+	var total int64
+	for _, query := range queries {
+		var size int64
+		if err := tx.QueryRowContext(ctx, query, treeID).Scan(&size); err != nil {
+			return 0, fmt.Errorf("error estimating size of tree %v: %v", treeID, err)
+		}
+		total += size
+	}
+	return total, nil
+}
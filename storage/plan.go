@@ -0,0 +1,125 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// ProvisioningPlan is a set of tree changes, to be validated as a whole by
+// ValidatePlan before being applied.
+type ProvisioningPlan struct {
+	// Creates are the trees to be created, as per AdminWriter.CreateTree.
+	Creates []*trillian.Tree
+
+	// Updates maps tree IDs to the updateFunc to be applied to each tree, as
+	// per AdminWriter.UpdateTrees.
+	Updates map[int64]func(*trillian.Tree)
+
+	// Deletes are the IDs of trees to be hard deleted, as per
+	// AdminWriter.HardDeleteTree.
+	Deletes []int64
+}
+
+// ValidatePlan validates all changes in plan against a snapshot of
+// adminStorage, without modifying it. opts should be the options adminStorage
+// was created with, so changes are validated against the same limits.
+// Creates must be valid as per ValidateTreeForCreation, and trees with a
+// preset ID must not collide with existing trees or other creates. Updates
+// must be valid as per ValidateTreeForUpdate, when applied to the current
+// state of their trees. Deletes must target existing, soft-deleted trees that
+// aren't updated by the plan.
+// Returns an errors.MultiError with one error per problem found, or nil if
+// the plan is valid.
+func ValidatePlan(ctx context.Context, adminStorage AdminStorage, opts AdminStorageOptions, plan ProvisioningPlan) error {
+	// Updates are validated against complete trees, private keys included.
+	ctx = WithPrivateKeyAccess(ctx)
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+
+	var errs errors.MultiError
+	problem := func(err error, format string, args ...interface{}) {
+		args = append(args, err)
+		errs = append(errs, errors.Errorf(errors.ErrorCode(err), format+": %v", args...))
+	}
+
+	createIDs := make(map[int64]bool)
+	for i, tree := range plan.Creates {
+		if err := ValidateTreeForCreation(ctx, opts, tree); err != nil {
+			problem(err, "create %v", i)
+			continue
+		}
+		if tree.TreeId == 0 {
+			continue
+		}
+		if createIDs[tree.TreeId] {
+			problem(errors.Errorf(errors.InvalidArgument, "tree %v created more than once", tree.TreeId), "create %v", i)
+			continue
+		}
+		createIDs[tree.TreeId] = true
+		switch _, err := tx.GetTree(ctx, tree.TreeId); {
+		case err == nil:
+			problem(errors.Errorf(errors.AlreadyExists, "tree %v already exists", tree.TreeId), "create %v", i)
+		case errors.ErrorCode(err) != errors.NotFound:
+			return err
+		}
+	}
+
+	// Check updates in ID order, so errors are deterministic.
+	updateIDs := make([]int64, 0, len(plan.Updates))
+	for treeID := range plan.Updates {
+		updateIDs = append(updateIDs, treeID)
+	}
+	sort.Slice(updateIDs, func(i, j int) bool { return updateIDs[i] < updateIDs[j] })
+	for _, treeID := range updateIDs {
+		storedTree, err := tx.GetTree(ctx, treeID)
+		if err != nil {
+			problem(err, "update of tree %v", treeID)
+			continue
+		}
+		tree := proto.Clone(storedTree).(*trillian.Tree)
+		plan.Updates[treeID](tree)
+		if err := ValidateTreeForUpdate(ctx, opts, storedTree, tree); err != nil {
+			problem(err, "update of tree %v", treeID)
+		}
+	}
+
+	for _, treeID := range plan.Deletes {
+		if _, ok := plan.Updates[treeID]; ok {
+			problem(errors.New(errors.InvalidArgument, "tree is also updated by the plan"), "delete of tree %v", treeID)
+			continue
+		}
+		tree, err := tx.GetTree(ctx, treeID)
+		switch {
+		case err != nil:
+			problem(err, "delete of tree %v", treeID)
+		case !tree.Deleted:
+			problem(errors.Errorf(errors.FailedPrecondition, "tree %v is not soft deleted", treeID), "delete of tree %v", treeID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+)
+
+// privateKeyAccessKey is the context key for the private key access marker.
+type privateKeyAccessKey struct{}
+
+// WithPrivateKeyAccess returns a context that grants access to tree private
+// keys, as per AdminStorageOptions.RequirePrivateKeyAccess. It should only be
+// used by trusted callers, such as signing paths.
+func WithPrivateKeyAccess(ctx context.Context) context.Context {
+	return context.WithValue(ctx, privateKeyAccessKey{}, true)
+}
+
+// HasPrivateKeyAccess returns whether ctx was derived from
+// WithPrivateKeyAccess.
+func HasPrivateKeyAccess(ctx context.Context) bool {
+	access, _ := ctx.Value(privateKeyAccessKey{}).(bool)
+	return access
+}
+
+// RedactTree clears the private key of tree if opts require private key
+// access and ctx doesn't grant it.
+// It's meant to be called by AdminStorage implementations before returning
+// trees from any AdminReader or AdminWriter method. tree must not be shared
+// with storage, as it's modified.
+func RedactTree(ctx context.Context, opts AdminStorageOptions, tree *trillian.Tree) {
+	if opts.RequirePrivateKeyAccess && !HasPrivateKeyAccess(ctx) {
+		tree.PrivateKey = nil
+	}
+}
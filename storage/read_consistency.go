@@ -0,0 +1,48 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+)
+
+// readConsistencyKey is the context key for caller-requested read consistency.
+type readConsistencyKey struct{}
+
+// WithReadConsistency returns a context requesting reads with consistency rc,
+// overriding the PreferredReadConsistency of the trees read.
+// An unspecified rc doesn't override tree preferences.
+func WithReadConsistency(ctx context.Context, rc trillian.ReadConsistency) context.Context {
+	return context.WithValue(ctx, readConsistencyKey{}, rc)
+}
+
+// ReadConsistencyFor returns the consistency that reads from tree under ctx
+// should use. It's the consistency requested via WithReadConsistency, if any,
+// otherwise the tree's PreferredReadConsistency. Returns STRONG if neither is
+// specified.
+// It's meant to be called by read paths that may serve stale data. None do
+// yet: all storage reads are strongly consistent, so the result is currently
+// an unused hint.
+func ReadConsistencyFor(ctx context.Context, tree *trillian.Tree) trillian.ReadConsistency {
+	if rc, ok := ctx.Value(readConsistencyKey{}).(trillian.ReadConsistency); ok && rc != trillian.ReadConsistency_UNSPECIFIED_READ_CONSISTENCY {
+		return rc
+	}
+	if rc := tree.GetPreferredReadConsistency(); rc != trillian.ReadConsistency_UNSPECIFIED_READ_CONSISTENCY {
+		return rc
+	}
+	return trillian.ReadConsistency_STRONG
+}
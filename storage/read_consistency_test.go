@@ -0,0 +1,52 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/trillian"
+)
+
+func TestReadConsistencyFor(t *testing.T) {
+	unspecified := trillian.ReadConsistency_UNSPECIFIED_READ_CONSISTENCY
+	strong := trillian.ReadConsistency_STRONG
+	eventual := trillian.ReadConsistency_EVENTUAL
+
+	tests := []struct {
+		desc      string
+		requested *trillian.ReadConsistency
+		preferred trillian.ReadConsistency
+		want      trillian.ReadConsistency
+	}{
+		{desc: "default", preferred: unspecified, want: strong},
+		{desc: "treePreference", preferred: eventual, want: eventual},
+		{desc: "requestedOverridesTree", requested: &strong, preferred: eventual, want: strong},
+		{desc: "requestedWithoutPreference", requested: &eventual, preferred: unspecified, want: eventual},
+		{desc: "unspecifiedRequest", requested: &unspecified, preferred: eventual, want: eventual},
+	}
+	for _, test := range tests {
+		ctx := context.Background()
+		if test.requested != nil {
+			ctx = WithReadConsistency(ctx, *test.requested)
+		}
+		tree := newTree()
+		tree.PreferredReadConsistency = test.preferred
+		if got := ReadConsistencyFor(ctx, tree); got != test.want {
+			t.Errorf("%v: ReadConsistencyFor() = %v, want = %v", test.desc, got, test.want)
+		}
+	}
+}
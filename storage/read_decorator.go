@@ -0,0 +1,72 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+)
+
+// ReadDecorator attaches computed, non-persisted data to a tree read from
+// storage, such as derived AppData entries.
+// Decorators must not modify persisted fields of the tree.
+type ReadDecorator func(context.Context, *trillian.Tree)
+
+var (
+	decoratorsMu sync.RWMutex
+	decorators   = make(map[string]ReadDecorator)
+)
+
+// RegisterReadDecorator registers a ReadDecorator under name, to be invoked
+// on every tree returned by AdminReader.GetTree and AdminReader.ListTrees.
+// If a decorator with the same name has already been registered, it will be
+// replaced.
+func RegisterReadDecorator(name string, decorator ReadDecorator) {
+	decoratorsMu.Lock()
+	defer decoratorsMu.Unlock()
+	if _, alreadyExists := decorators[name]; alreadyExists {
+		glog.Warningf("Overriding ReadDecorator %q", name)
+	}
+	decorators[name] = decorator
+}
+
+// UnregisterReadDecorator removes a previously-registered ReadDecorator.
+// See RegisterReadDecorator().
+func UnregisterReadDecorator(name string) {
+	decoratorsMu.Lock()
+	defer decoratorsMu.Unlock()
+	delete(decorators, name)
+}
+
+// DecorateTree invokes all registered ReadDecorators on tree, in name order.
+// It's meant to be called by AdminStorage implementations before returning
+// trees to callers. tree must not be shared with storage, as decorators
+// modify it.
+func DecorateTree(ctx context.Context, tree *trillian.Tree) {
+	decoratorsMu.RLock()
+	defer decoratorsMu.RUnlock()
+	names := make([]string, 0, len(decorators))
+	for name := range decorators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		decorators[name](ctx, tree)
+	}
+}
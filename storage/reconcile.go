@@ -0,0 +1,114 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+)
+
+// PlanReconciliation returns the plan that makes the trees in dst match the
+// trees in src, matched by tree ID and compared by config, as per
+// TreeConfigDiff. Soft-deleted trees are treated as absent.
+// Trees only in src are created in dst under the same ID, trees in both
+// whose configs differ are updated to src's config, and trees only in dst
+// are deleted. Changes are in tree ID order.
+// The plan should be checked by ValidatePlan before being applied, which
+// reports changes dst can't accept, e.g. updates of readonly fields or
+// deletes of trees that aren't soft deleted yet.
+func PlanReconciliation(ctx context.Context, src, dst AdminStorage) (*ProvisioningPlan, error) {
+	srcTrees, err := listTreesByID(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	dstTrees, err := listTreesByID(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ProvisioningPlan{Updates: make(map[int64]func(*trillian.Tree))}
+	for _, srcTree := range sortedTrees(srcTrees) {
+		dstTree, ok := dstTrees[srcTree.TreeId]
+		switch {
+		case !ok:
+			create := proto.Clone(srcTree).(*trillian.Tree)
+			create.CreateTime = nil
+			create.UpdateTime = nil
+			create.LastSequencedTime = nil
+			create.Revision = 0
+			plan.Creates = append(plan.Creates, create)
+		case len(TreeConfigDiff(dstTree, srcTree)) > 0:
+			plan.Updates[srcTree.TreeId] = reconcileTree(srcTree)
+		}
+	}
+	for _, dstTree := range sortedTrees(dstTrees) {
+		if _, ok := srcTrees[dstTree.TreeId]; !ok {
+			plan.Deletes = append(plan.Deletes, dstTree.TreeId)
+		}
+	}
+	return plan, nil
+}
+
+// listTreesByID returns the non-deleted trees of adminStorage, keyed by ID.
+func listTreesByID(ctx context.Context, adminStorage AdminStorage) (map[int64]*trillian.Tree, error) {
+	// Private keys are compared and copied like the rest of the config.
+	ctx = WithPrivateKeyAccess(ctx)
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, false /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	ret := make(map[int64]*trillian.Tree)
+	for _, tree := range trees {
+		ret[tree.TreeId] = tree
+	}
+	return ret, nil
+}
+
+// sortedTrees returns the trees of treesByID in ID order.
+func sortedTrees(treesByID map[int64]*trillian.Tree) []*trillian.Tree {
+	trees := make([]*trillian.Tree, 0, len(treesByID))
+	for _, tree := range treesByID {
+		trees = append(trees, tree)
+	}
+	sort.Slice(trees, func(i, j int) bool { return trees[i].TreeId < trees[j].TreeId })
+	return trees
+}
+
+// reconcileTree returns an updateFunc that sets a tree's config to src's,
+// keeping its storage-managed fields.
+func reconcileTree(src *trillian.Tree) func(*trillian.Tree) {
+	return func(tree *trillian.Tree) {
+		updated := proto.Clone(src).(*trillian.Tree)
+		updated.TreeId = tree.TreeId
+		updated.CreateTime = tree.CreateTime
+		updated.UpdateTime = tree.UpdateTime
+		updated.Deleted = tree.Deleted
+		updated.DeleteTime = tree.DeleteTime
+		updated.LastSequencedTime = tree.LastSequencedTime
+		updated.Revision = tree.Revision
+		*tree = *updated
+	}
+}
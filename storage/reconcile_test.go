@@ -0,0 +1,150 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestPlanReconciliation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	newStorage := func(trees ...*trillian.Tree) AdminStorage {
+		tx := NewMockReadOnlyAdminTX(ctrl)
+		tx.EXPECT().ListTrees(keyAccessCtx{}, false).Return(trees, nil)
+		tx.EXPECT().Commit().Return(nil)
+		tx.EXPECT().Close().Return(nil)
+		s := NewMockAdminStorage(ctrl)
+		s.EXPECT().Snapshot(keyAccessCtx{}).Return(tx, nil)
+		return s
+	}
+	makeTree := func(id int64, displayName string) *trillian.Tree {
+		tree := newTree()
+		tree.TreeId = id
+		tree.DisplayName = displayName
+		return tree
+	}
+
+	shared := makeTree(1, "shared")
+	changed := makeTree(2, "src")
+	changedInDst := proto.Clone(changed).(*trillian.Tree)
+	changedInDst.DisplayName = "dst"
+	changedInDst.Description = "stale"
+	srcOnly := makeTree(3, "srcOnly")
+	dstOnly := makeTree(4, "dstOnly")
+	srcTrees := []*trillian.Tree{shared, changed, srcOnly}
+	dstTrees := []*trillian.Tree{dstOnly, shared, changedInDst}
+
+	plan, err := PlanReconciliation(ctx, newStorage(srcTrees...), newStorage(dstTrees...))
+	if err != nil {
+		t.Fatalf("PlanReconciliation() = (_, %v), want = (_, nil)", err)
+	}
+	var createIDs, updateIDs []int64
+	for _, tree := range plan.Creates {
+		createIDs = append(createIDs, tree.TreeId)
+	}
+	for treeID := range plan.Updates {
+		updateIDs = append(updateIDs, treeID)
+	}
+	sort.Slice(updateIDs, func(i, j int) bool { return updateIDs[i] < updateIDs[j] })
+	if diff := pretty.Compare(createIDs, []int64{srcOnly.TreeId}); diff != "" {
+		t.Errorf("PlanReconciliation() Creates diff (-got +want):\n%v", diff)
+	}
+	if diff := pretty.Compare(updateIDs, []int64{changed.TreeId}); diff != "" {
+		t.Errorf("PlanReconciliation() Updates diff (-got +want):\n%v", diff)
+	}
+	if diff := pretty.Compare(plan.Deletes, []int64{dstOnly.TreeId}); diff != "" {
+		t.Errorf("PlanReconciliation() Deletes diff (-got +want):\n%v", diff)
+	}
+
+	// Applying the plan to dst's trees must make them match src's.
+	applied := make(map[int64]*trillian.Tree)
+	for _, tree := range dstTrees {
+		applied[tree.TreeId] = proto.Clone(tree).(*trillian.Tree)
+	}
+	for _, tree := range plan.Creates {
+		applied[tree.TreeId] = tree
+	}
+	for treeID, updateFunc := range plan.Updates {
+		updateFunc(applied[treeID])
+	}
+	for _, treeID := range plan.Deletes {
+		delete(applied, treeID)
+	}
+	if got, want := len(applied), len(srcTrees); got != want {
+		t.Errorf("applied plan has %v trees, want = %v", got, want)
+	}
+	for _, srcTree := range srcTrees {
+		tree, ok := applied[srcTree.TreeId]
+		if !ok {
+			t.Errorf("applied plan is missing tree %v", srcTree.TreeId)
+			continue
+		}
+		if diff := TreeConfigDiff(tree, srcTree); len(diff) != 0 {
+			t.Errorf("applied plan has tree %v with config diff %+v, want none", srcTree.TreeId, diff)
+		}
+	}
+}
+
+func TestPlanReconciliation_IdenticalStores(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	tree1 := newTree()
+	tree1.TreeId = 1
+	tree2 := newTree()
+	tree2.TreeId = 2
+	newStorage := func() AdminStorage {
+		// Trees are copies, so the plan can't rely on pointer equality.
+		tx := NewMockReadOnlyAdminTX(ctrl)
+		tx.EXPECT().ListTrees(keyAccessCtx{}, false).Return([]*trillian.Tree{proto.Clone(tree1).(*trillian.Tree), proto.Clone(tree2).(*trillian.Tree)}, nil)
+		tx.EXPECT().Commit().Return(nil)
+		tx.EXPECT().Close().Return(nil)
+		s := NewMockAdminStorage(ctrl)
+		s.EXPECT().Snapshot(keyAccessCtx{}).Return(tx, nil)
+		return s
+	}
+
+	plan, err := PlanReconciliation(ctx, newStorage(), newStorage())
+	if err != nil {
+		t.Fatalf("PlanReconciliation() = (_, %v), want = (_, nil)", err)
+	}
+	if len(plan.Creates) != 0 || len(plan.Updates) != 0 || len(plan.Deletes) != 0 {
+		t.Errorf("PlanReconciliation() = %+v, want empty plan", plan)
+	}
+}
+
+// keyAccessCtx matches contexts marked with WithPrivateKeyAccess, so reads
+// done on behalf of a reconciliation plan see the trees' private keys.
+type keyAccessCtx struct{}
+
+func (keyAccessCtx) Matches(x interface{}) bool {
+	ctx, ok := x.(context.Context)
+	return ok && HasPrivateKeyAccess(ctx)
+}
+
+func (keyAccessCtx) String() string {
+	return "is a context with private key access"
+}
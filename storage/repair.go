@@ -0,0 +1,47 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian/errors"
+)
+
+// DeletedConsistencyRepairer is implemented by AdminStorages able to repair
+// trees whose Deleted flag and DeleteTime disagree, see
+// RepairDeletedConsistency.
+type DeletedConsistencyRepairer interface {
+	// RepairDeletedConsistency repairs all inconsistent trees in a single
+	// transaction, as per RepairDeletedConsistency, and returns their
+	// number.
+	RepairDeletedConsistency(ctx context.Context) (int, error)
+}
+
+// RepairDeletedConsistency fixes trees in adminStorage whose Deleted flag and
+// DeleteTime disagree, as may be left behind by partial failures.
+// The Deleted flag is authoritative: soft-deleted trees without a DeleteTime
+// get their UpdateTime as DeleteTime, and non-deleted trees have their
+// DeleteTime cleared. Other fields, including UpdateTime, are left unchanged.
+// All trees are repaired in a single transaction. Returns the number of
+// repaired trees, or an Unimplemented error if adminStorage isn't a
+// DeletedConsistencyRepairer.
+func RepairDeletedConsistency(ctx context.Context, adminStorage AdminStorage) (int, error) {
+	repairer, ok := adminStorage.(DeletedConsistencyRepairer)
+	if !ok {
+		return 0, errors.New(errors.Unimplemented, "storage doesn't support repairing deleted consistency")
+	}
+	return repairer.RepairDeletedConsistency(ctx)
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// VerifyStoredTreeRoundTrip checks that the specified tree survives a
+// serialization round trip unchanged, and that consecutive reads return the
+// same tree. It's meant to detect lossy storage serialization, e.g. after
+// proto schema changes.
+// Returns a DataLoss error describing the mismatch, if any.
+func VerifyStoredTreeRoundTrip(ctx context.Context, adminStorage AdminStorage, treeID int64) error {
+	// Private keys are stored too, so they're verified as well.
+	ctx = WithPrivateKeyAccess(ctx)
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	tree, err := tx.GetTree(ctx, treeID)
+	if err != nil {
+		return err
+	}
+	fresh, err := tx.GetTree(ctx, treeID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	treeBytes, err := proto.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("tree %v: error marshaling tree: %v", treeID, err)
+	}
+	var roundTripped trillian.Tree
+	if err := proto.Unmarshal(treeBytes, &roundTripped); err != nil {
+		return errors.Errorf(errors.DataLoss, "tree %v: error unmarshaling tree: %v", treeID, err)
+	}
+
+	switch {
+	case !proto.Equal(tree, fresh):
+		return errors.Errorf(errors.DataLoss, "tree %v: consecutive reads differ: %v != %v", treeID, tree, fresh)
+	case !proto.Equal(&roundTripped, fresh):
+		return errors.Errorf(errors.DataLoss, "tree %v: round-tripped tree differs from stored tree: %v != %v", treeID, &roundTripped, fresh)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+)
+
+// StalenessSignal is the event FindStaleLogTreesBySignal measures tree
+// staleness from.
+type StalenessSignal int
+
+const (
+	// LatestRootSignal measures staleness from the latest signed root.
+	LatestRootSignal StalenessSignal = iota
+
+	// SequencingSignal measures staleness from Tree.LastSequencedTime, i.e.,
+	// from the last time the sequencer integrated leaves. Roots may be signed
+	// without integration progress, so it detects logs whose sequencing is
+	// stuck despite roots being up to date.
+	SequencingSignal
+)
+
+// FindStaleLogTrees returns the IDs of all active LOG trees whose latest
+// signed root is older than their MaxRootDuration, as measured from now.
+// Trees with a zero MaxRootDuration are never considered stale. Trees that
+// don't have a signed root yet are considered stale if they were created
+// longer than MaxRootDuration ago.
+func FindStaleLogTrees(ctx context.Context, adminS AdminStorage, logS LogStorage, now time.Time) ([]int64, error) {
+	return FindStaleLogTreesBySignal(ctx, adminS, logS, now, LatestRootSignal)
+}
+
+// FindStaleLogTreesBySignal is like FindStaleLogTrees, but measures staleness
+// from signal. logS is only read for LatestRootSignal, and may be nil
+// otherwise.
+// Trees that never had the signal event (e.g. were never sequenced) are
+// considered stale if they were created longer than MaxRootDuration ago.
+func FindStaleLogTreesBySignal(ctx context.Context, adminS AdminStorage, logS LogStorage, now time.Time, signal StalenessSignal) ([]int64, error) {
+	tx, err := adminS.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, false /* includeDeleted */)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	var stale []int64
+	for _, tree := range trees {
+		if tree.TreeType != trillian.TreeType_LOG || tree.TreeState != trillian.TreeState_ACTIVE {
+			continue
+		}
+		maxRootDuration, err := ptypes.Duration(tree.MaxRootDuration)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing max_root_duration of tree %v: %v", tree.TreeId, err)
+		}
+		if maxRootDuration <= 0 {
+			continue
+		}
+
+		lastTime, err := lastSignalTime(ctx, logS, tree, signal)
+		if err != nil {
+			return nil, err
+		}
+		if lastTime.IsZero() {
+			// No signal event yet, count from the tree creation instead.
+			lastTime, err = ptypes.Timestamp(tree.CreateTime)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing create_time of tree %v: %v", tree.TreeId, err)
+			}
+		}
+
+		if now.Sub(lastTime) > maxRootDuration {
+			stale = append(stale, tree.TreeId)
+		}
+	}
+	return stale, nil
+}
+
+// lastSignalTime returns the time of the latest signal event of tree, or the
+// zero time if there was none.
+func lastSignalTime(ctx context.Context, logS LogStorage, tree *trillian.Tree, signal StalenessSignal) (time.Time, error) {
+	switch signal {
+	case LatestRootSignal:
+		root, err := latestSignedLogRoot(ctx, logS, tree.TreeId)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error reading latest root of tree %v: %v", tree.TreeId, err)
+		}
+		if root.TimestampNanos == 0 {
+			return time.Time{}, nil
+		}
+		return time.Unix(0, root.TimestampNanos), nil
+	case SequencingSignal:
+		if tree.LastSequencedTime == nil {
+			return time.Time{}, nil
+		}
+		t, err := ptypes.Timestamp(tree.LastSequencedTime)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error parsing last_sequenced_time of tree %v: %v", tree.TreeId, err)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown staleness signal: %v", signal)
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestFindStaleLogTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Unix(1000000, 0)
+
+	newLog := func(id int64, maxRootDuration time.Duration) *trillian.Tree {
+		tree := newTree()
+		tree.TreeId = id
+		tree.MaxRootDuration = ptypes.DurationProto(maxRootDuration)
+		tree.CreateTime, _ = ptypes.TimestampProto(now.Add(-24 * time.Hour))
+		tree.UpdateTime = tree.CreateTime
+		return tree
+	}
+
+	staleLog := newLog(1, time.Hour)
+	freshLog := newLog(2, time.Hour)
+	zeroDurationLog := newLog(3, 0)
+	noRootLog := newLog(4, time.Hour)
+	frozenLog := newLog(5, time.Hour)
+	frozenLog.TreeState = trillian.TreeState_FROZEN
+	mapTree := newLog(6, time.Hour)
+	mapTree.TreeType = trillian.TreeType_MAP
+
+	roots := map[int64]trillian.SignedLogRoot{
+		staleLog.TreeId:        {TimestampNanos: now.Add(-2 * time.Hour).UnixNano()},
+		freshLog.TreeId:        {TimestampNanos: now.Add(-10 * time.Minute).UnixNano()},
+		zeroDurationLog.TreeId: {TimestampNanos: now.Add(-48 * time.Hour).UnixNano()},
+		noRootLog.TreeId:       {},
+	}
+
+	adminTX := NewMockReadOnlyAdminTX(ctrl)
+	adminTX.EXPECT().ListTrees(ctx, false).Return([]*trillian.Tree{staleLog, freshLog, zeroDurationLog, noRootLog, frozenLog, mapTree}, nil)
+	adminTX.EXPECT().Commit().Return(nil)
+	adminTX.EXPECT().Close().Return(nil)
+	adminS := NewMockAdminStorage(ctrl)
+	adminS.EXPECT().Snapshot(ctx).Return(adminTX, nil)
+
+	logS := NewMockLogStorage(ctrl)
+	for id, root := range roots {
+		if id == zeroDurationLog.TreeId {
+			continue // Never read
+		}
+		logTX := NewMockReadOnlyLogTreeTX(ctrl)
+		logTX.EXPECT().LatestSignedLogRoot(ctx).Return(root, nil)
+		logTX.EXPECT().Commit().Return(nil)
+		logTX.EXPECT().Close().Return(nil)
+		logS.EXPECT().SnapshotForTree(ctx, id).Return(logTX, nil)
+	}
+
+	got, err := FindStaleLogTrees(ctx, adminS, logS, now)
+	if err != nil {
+		t.Fatalf("FindStaleLogTrees() = (_, %v), want = (_, nil)", err)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	want := []int64{staleLog.TreeId, noRootLog.TreeId}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("FindStaleLogTrees() diff (-got +want):\n%v", diff)
+	}
+}
@@ -15,27 +15,39 @@
 package testonly
 
 import (
+	"bytes"
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
-	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keys/pem"
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/errors"
 	"github.com/google/trillian/storage"
 	"github.com/google/trillian/testonly"
+	"github.com/google/trillian/util"
 	"github.com/kylelemons/godebug/pretty"
 
+	tcrypto "github.com/google/trillian/crypto"
 	ktestonly "github.com/google/trillian/crypto/keys/testonly"
 	spb "github.com/google/trillian/crypto/sigpb"
 
@@ -71,6 +83,44 @@ func mustMarshalAny(pb proto.Message) *any.Any {
 	return value
 }
 
+// ecdsaLogTree returns a copy of LogTree with a freshly-generated ECDSA key on
+// the specified curve.
+func ecdsaLogTree(t *testing.T, curve elliptic.Curve) *trillian.Tree {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() = (_, %v), want = (_, nil)", err)
+	}
+	return logTreeWithKey(t, key)
+}
+
+// rsaLogTree returns a copy of LogTree with a freshly-generated RSA key of the
+// specified size.
+func rsaLogTree(t *testing.T, bits int) *trillian.Tree {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() = (_, %v), want = (_, nil)", err)
+	}
+	tree := logTreeWithKey(t, key)
+	tree.SignatureAlgorithm = spb.DigitallySigned_RSA
+	return tree
+}
+
+// logTreeWithKey returns a copy of LogTree signing with key.
+func logTreeWithKey(t *testing.T, key crypto.Signer) *trillian.Tree {
+	keyDER, err := der.MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatalf("der.MarshalPrivateKey() = (_, %v), want = (_, nil)", err)
+	}
+	publicKey, err := der.ToPublicProto(key.Public())
+	if err != nil {
+		t.Fatalf("der.ToPublicProto() = (_, %v), want = (_, nil)", err)
+	}
+	tree := proto.Clone(LogTree).(*trillian.Tree)
+	tree.PrivateKey = mustMarshalAny(&keyspb.PrivateKey{Der: keyDER})
+	tree.PublicKey = publicKey
+	return tree
+}
+
 var (
 	// LogTree is a valid, LOG-type trillian.Tree for tests.
 	LogTree = &trillian.Tree{
@@ -114,20 +164,122 @@ type AdminStorageTester struct {
 	// NewAdminStorage returns an AdminStorage instance pointing to a clean
 	// test database.
 	NewAdminStorage func() storage.AdminStorage
+
+	// NewAdminStorageWithOptions returns an AdminStorage instance configured
+	// by opts, pointing to a clean test database.
+	// Tests of optional behavior are skipped if nil.
+	NewAdminStorageWithOptions func(opts storage.AdminStorageOptions) storage.AdminStorage
+
+	// SetDeletedUnchecked writes the Deleted flag and DeleteTime of a tree
+	// straight to the underlying database, bypassing AdminStorage, so
+	// inconsistent trees may be simulated. A nil deleteTime is stored as
+	// unset.
+	// Tests of repair behavior are skipped if nil.
+	SetDeletedUnchecked func(ctx context.Context, treeID int64, deleted bool, deleteTime *time.Time) error
+
+	// SetDescriptionUnchecked writes the Description of a tree straight to
+	// the underlying database, bypassing AdminStorage, so config corruption
+	// may be simulated.
+	// Tests of corruption detection are skipped if nil.
+	SetDescriptionUnchecked func(ctx context.Context, treeID int64, description string) error
+
+	// UnsupportedTests maps the names of tests exercising features the
+	// storage doesn't implement to the reason they're skipped.
+	UnsupportedTests map[string]string
 }
 
 // RunAllTests runs all AdminStorage tests.
 func (tester *AdminStorageTester) RunAllTests(t *testing.T) {
-	t.Run("TestCreateTree", tester.TestCreateTree)
-	t.Run("TestUpdateTree", tester.TestUpdateTree)
-	t.Run("TestListTrees", tester.TestListTrees)
-	t.Run("TestSoftDeleteTree", tester.TestSoftDeleteTree)
-	t.Run("TestSoftDeleteTreeErrors", tester.TestSoftDeleteTreeErrors)
-	t.Run("TestHardDeleteTree", tester.TestHardDeleteTree)
-	t.Run("TestHardDeleteTreeErrors", tester.TestHardDeleteTreeErrors)
-	t.Run("TestUndeleteTree", tester.TestUndeleteTree)
-	t.Run("TestUndeleteTreeErrors", tester.TestUndeleteTreeErrors)
-	t.Run("TestAdminTXClose", tester.TestAdminTXClose)
+	tester.run(t, "TestCreateTree", tester.TestCreateTree)
+	tester.run(t, "TestAllowedECCurves", tester.TestAllowedECCurves)
+	tester.run(t, "TestECDSAHashCompatibility", tester.TestECDSAHashCompatibility)
+	tester.run(t, "TestSignatureParams", tester.TestSignatureParams)
+	tester.run(t, "TestSetAcceptingWrites", tester.TestSetAcceptingWrites)
+	tester.run(t, "TestReserveTreeID", tester.TestReserveTreeID)
+	tester.run(t, "TestUpdateTree", tester.TestUpdateTree)
+	tester.run(t, "TestUpdateTreeNoop", tester.TestUpdateTreeNoop)
+	tester.run(t, "TestFreezeIsTerminal", tester.TestFreezeIsTerminal)
+	tester.run(t, "TestUpdateTrees", tester.TestUpdateTrees)
+	tester.run(t, "TestTouchTrees", tester.TestTouchTrees)
+	tester.run(t, "TestConcurrentTouchTrees", tester.TestConcurrentTouchTrees)
+	tester.run(t, "TestReplaceTree", tester.TestReplaceTree)
+	tester.run(t, "TestConcurrentReplaceTree", tester.TestConcurrentReplaceTree)
+	tester.run(t, "TestConcurrentSoftDeleteTreeIfUnchanged", tester.TestConcurrentSoftDeleteTreeIfUnchanged)
+	tester.run(t, "TestSoftDeleteTreeIfUnchanged", tester.TestSoftDeleteTreeIfUnchanged)
+	tester.run(t, "TestTimestampMonotonicity", tester.TestTimestampMonotonicity)
+	tester.run(t, "TestFieldPolicy", tester.TestFieldPolicy)
+	tester.run(t, "TestMigrateKeyHandler", tester.TestMigrateKeyHandler)
+	tester.run(t, "TestSignatureAlgorithmImmutable", tester.TestSignatureAlgorithmImmutable)
+	tester.run(t, "TestWritesDisabled", tester.TestWritesDisabled)
+	tester.run(t, "TestRecordSequencingProgress", tester.TestRecordSequencingProgress)
+	tester.run(t, "TestPrivateKeyAccess", tester.TestPrivateKeyAccess)
+	tester.run(t, "TestPrivateKeyAccessWrites", tester.TestPrivateKeyAccessWrites)
+	tester.run(t, "TestSetTreeLabels", tester.TestSetTreeLabels)
+	tester.run(t, "TestTransferLabels", tester.TestTransferLabels)
+	tester.run(t, "TestReservedLabels", tester.TestReservedLabels)
+	tester.run(t, "TestCanonicalTreeOrder", tester.TestCanonicalTreeOrder)
+	tester.run(t, "TestTreeAlias", tester.TestTreeAlias)
+	tester.run(t, "TestCreateTreeWithAlias", tester.TestCreateTreeWithAlias)
+	tester.run(t, "TestConcurrentCreateTreeWithAlias", tester.TestConcurrentCreateTreeWithAlias)
+	tester.run(t, "TestImportTrees", tester.TestImportTrees)
+	tester.run(t, "TestImportTreeTimestamps", tester.TestImportTreeTimestamps)
+	tester.run(t, "TestImportTreesCanceled", tester.TestImportTreesCanceled)
+	tester.run(t, "TestValidatePlan", tester.TestValidatePlan)
+	tester.run(t, "TestAuditStoredTrees", tester.TestAuditStoredTrees)
+	tester.run(t, "TestExportPublicKeyBundle", tester.TestExportPublicKeyBundle)
+	tester.run(t, "TestReadDecorator", tester.TestReadDecorator)
+	tester.run(t, "TestLabelDeriver", tester.TestLabelDeriver)
+	tester.run(t, "TestGetTreesByDisplayNames", tester.TestGetTreesByDisplayNames)
+	tester.run(t, "TestListRecentTrees", tester.TestListRecentTrees)
+	tester.run(t, "TestGetTreeByExternalRef", tester.TestGetTreeByExternalRef)
+	tester.run(t, "TestRepairDeletedConsistency", tester.TestRepairDeletedConsistency)
+	tester.run(t, "TestExistingTreeIDs", tester.TestExistingTreeIDs)
+	tester.run(t, "TestPreferredReadConsistency", tester.TestPreferredReadConsistency)
+	tester.run(t, "TestMaxDescriptionBytes", tester.TestMaxDescriptionBytes)
+	tester.run(t, "TestMaxDescriptionBytesRoundTrip", tester.TestMaxDescriptionBytesRoundTrip)
+	tester.run(t, "TestWithTreeMaintenance", tester.TestWithTreeMaintenance)
+	tester.run(t, "TestWithTreeMaintenanceCanceled", tester.TestWithTreeMaintenanceCanceled)
+	tester.run(t, "TestCountTreesByLabel", tester.TestCountTreesByLabel)
+	tester.run(t, "TestListTreeRevisions", tester.TestListTreeRevisions)
+	tester.run(t, "TestStreamTreeConfigHashes", tester.TestStreamTreeConfigHashes)
+	tester.run(t, "TestVerifyConfigChecksumOnRead", tester.TestVerifyConfigChecksumOnRead)
+	tester.run(t, "TestGetTreeLineage", tester.TestGetTreeLineage)
+	tester.run(t, "TestListUntouchedTrees", tester.TestListUntouchedTrees)
+	tester.run(t, "TestAppDataLimits", tester.TestAppDataLimits)
+	tester.run(t, "TestAppDataLimitOptions", tester.TestAppDataLimitOptions)
+	tester.run(t, "TestTreeCountSnapshots", tester.TestTreeCountSnapshots)
+	tester.run(t, "TestDistinctHashStrategies", tester.TestDistinctHashStrategies)
+	tester.run(t, "TestStoreConfigDigest", tester.TestStoreConfigDigest)
+	tester.run(t, "TestExportTreesSince", tester.TestExportTreesSince)
+	tester.run(t, "TestTreeChurn", tester.TestTreeChurn)
+	tester.run(t, "TestVerifyStoredTreeRoundTrip", tester.TestVerifyStoredTreeRoundTrip)
+	tester.run(t, "TestGetTreeKeyInfo", tester.TestGetTreeKeyInfo)
+	tester.run(t, "TestListTrees", tester.TestListTrees)
+	tester.run(t, "TestListTreesPaginated", tester.TestListTreesPaginated)
+	tester.run(t, "TestListSequenceableTreeIDs", tester.TestListSequenceableTreeIDs)
+	tester.run(t, "TestPauseSequencing", tester.TestPauseSequencing)
+	tester.run(t, "TestSoftDeleteTree", tester.TestSoftDeleteTree)
+	tester.run(t, "TestSoftDeleteTreeErrors", tester.TestSoftDeleteTreeErrors)
+	tester.run(t, "TestHardDeleteTree", tester.TestHardDeleteTree)
+	tester.run(t, "TestHardDeleteTreeErrors", tester.TestHardDeleteTreeErrors)
+	tester.run(t, "TestListHardDeletableTrees", tester.TestListHardDeletableTrees)
+	tester.run(t, "TestListHardDeletableTreesSkipsLiveTrees", tester.TestListHardDeletableTreesSkipsLiveTrees)
+	tester.run(t, "TestUndeleteTree", tester.TestUndeleteTree)
+	tester.run(t, "TestUndeleteTreeErrors", tester.TestUndeleteTreeErrors)
+	tester.run(t, "TestConcurrentDeleteUndelete", tester.TestConcurrentDeleteUndelete)
+	tester.run(t, "TestInvalidTreeIDs", tester.TestInvalidTreeIDs)
+	tester.run(t, "TestAdminTXClose", tester.TestAdminTXClose)
+	tester.run(t, "TestOperationsAfterCommit", tester.TestOperationsAfterCommit)
+}
+
+// run runs test f as a subtest of t, unless it's an unsupported test.
+func (tester *AdminStorageTester) run(t *testing.T, name string, f func(*testing.T)) {
+	t.Run(name, func(t *testing.T) {
+		if reason, ok := tester.UnsupportedTests[name]; ok {
+			t.Skip(reason)
+		}
+		f(t)
+	})
 }
 
 // TestCreateTree tests AdminStorage Tree creation.
@@ -137,6 +289,9 @@ func (tester *AdminStorageTester) TestCreateTree(t *testing.T) {
 	invalidTree := *LogTree
 	invalidTree.TreeType = trillian.TreeType_UNKNOWN_TREE_TYPE
 
+	emptyPrivateKeyTree := *LogTree
+	emptyPrivateKeyTree.PrivateKey = &any.Any{TypeUrl: LogTree.PrivateKey.TypeUrl}
+
 	validTree1 := *LogTree
 	validTree2 := *MapTree
 
@@ -154,6 +309,11 @@ func (tester *AdminStorageTester) TestCreateTree(t *testing.T) {
 			tree:    &invalidTree,
 			wantErr: true,
 		},
+		{
+			desc:    "emptyPrivateKeyTree",
+			tree:    &emptyPrivateKeyTree,
+			wantErr: true,
+		},
 		{
 			desc: "validTree1",
 			tree: &validTree1,
@@ -205,6 +365,7 @@ func (tester *AdminStorageTester) TestCreateTree(t *testing.T) {
 			wantTree.TreeId = newTree.TreeId
 			wantTree.CreateTime = createTime
 			wantTree.UpdateTime = updateTime
+			wantTree.Revision = 1
 			// Ignore storage_settings changes (OK to vary between implementations)
 			wantTree.StorageSettings = newTree.StorageSettings
 			if !proto.Equal(newTree, &wantTree) {
@@ -224,6 +385,207 @@ func (tester *AdminStorageTester) TestCreateTree(t *testing.T) {
 	}
 }
 
+// TestAllowedECCurves tests that tree creation rejects ECDSA keys on curves
+// not in AdminStorageOptions.AllowedECCurves.
+func (tester *AdminStorageTester) TestAllowedECCurves(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+	// P-384 with SHA-256 passes all other validation, so it can only be
+	// rejected by the curve check.
+	p384Log := ecdsaLogTree(t, elliptic.P384())
+
+	tests := []struct {
+		desc    string
+		curves  []elliptic.Curve
+		tree    *trillian.Tree
+		wantErr bool
+	}{
+		{desc: "p256", curves: []elliptic.Curve{elliptic.P256()}, tree: LogTree},
+		{desc: "p384", curves: []elliptic.Curve{elliptic.P256()}, tree: p384Log, wantErr: true},
+		{desc: "p384Allowed", curves: []elliptic.Curve{elliptic.P256(), elliptic.P384()}, tree: p384Log},
+		{desc: "anyCurve", tree: p384Log},
+	}
+	for _, test := range tests {
+		s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{AllowedECCurves: test.curves})
+		_, err := createTree(ctx, s, test.tree)
+		switch hasErr := err != nil; {
+		case hasErr != test.wantErr:
+			t.Errorf("%v: createTree() = (_, %v), wantErr = %v", test.desc, err, test.wantErr)
+		case hasErr && errors.ErrorCode(err) != errors.InvalidArgument:
+			t.Errorf("%v: createTree() returned err = %v, wantCode = %s", test.desc, err, errors.InvalidArgument)
+		}
+	}
+}
+
+// TestECDSAHashCompatibility tests that tree creation rejects ECDSA keys on
+// curves whose order is smaller than the tree's hash digest, e.g. P-224 with
+// SHA-256, and accepts larger curves, e.g. P-384 with SHA-256.
+func (tester *AdminStorageTester) TestECDSAHashCompatibility(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tests := []struct {
+		desc    string
+		tree    *trillian.Tree
+		wantErr bool
+	}{
+		{desc: "sha256P224", tree: ecdsaLogTree(t, elliptic.P224()), wantErr: true},
+		{desc: "sha256P256", tree: LogTree},
+		{desc: "sha256P384", tree: ecdsaLogTree(t, elliptic.P384())},
+		{desc: "sha256P521", tree: ecdsaLogTree(t, elliptic.P521())},
+	}
+	for _, test := range tests {
+		_, err := createTree(ctx, s, test.tree)
+		switch hasErr := err != nil; {
+		case hasErr != test.wantErr:
+			t.Errorf("%v: createTree() = (_, %v), wantErr = %v", test.desc, err, test.wantErr)
+		case hasErr && errors.ErrorCode(err) != errors.InvalidArgument:
+			t.Errorf("%v: createTree() returned err = %v, wantCode = %s", test.desc, err, errors.InvalidArgument)
+		}
+	}
+}
+
+// TestSignatureParams tests that RSA trees persist their SignatureParams, and
+// that params not applicable to the signature algorithm are rejected.
+func (tester *AdminStorageTester) TestSignatureParams(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	pss := &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PSS}
+	rsaLog := rsaLogTree(t, keys.MinRsaKeySizeInBits)
+	rsaLog.SignatureParams = pss
+	created, err := createTree(ctx, s, rsaLog)
+	if err != nil {
+		t.Fatalf("createTree(RSA-PSS) returned err = %v", err)
+	}
+	if !proto.Equal(created.SignatureParams, pss) {
+		t.Errorf("createTree(RSA-PSS) returned SignatureParams = %v, want = %v", created.SignatureParams, pss)
+	}
+	if err := assertStoredTree(ctx, s, created); err != nil {
+		t.Errorf("createTree(RSA-PSS) not persisted: %v", err)
+	}
+
+	if _, _, err := updateTree(ctx, s, created.TreeId, func(tree *trillian.Tree) {
+		tree.SignatureParams = nil
+	}); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("updateTree(PSS->default) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+
+	ecdsaLog := proto.Clone(LogTree).(*trillian.Tree)
+	ecdsaLog.SignatureParams = pss
+	if _, err := createTree(ctx, s, ecdsaLog); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("createTree(ECDSA-PSS) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+}
+
+// TestSetAcceptingWrites tests that tree creations are rejected on
+// transactions begun after storage.SetAcceptingWrites(false).
+func (tester *AdminStorageTester) TestSetAcceptingWrites(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	defer storage.SetAcceptingWrites(true)
+
+	// Transactions begun before disabling writes may still create trees.
+	inFlightTX, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	defer inFlightTX.Close()
+
+	storage.SetAcceptingWrites(false)
+	if _, err := createTree(ctx, s, LogTree); errors.ErrorCode(err) != errors.Unavailable {
+		t.Errorf("CreateTree() returned err = %v, wantCode = %s", err, errors.Unavailable)
+	}
+
+	tree, err := inFlightTX.CreateTree(ctx, LogTree)
+	if err != nil {
+		t.Fatalf("in-flight CreateTree() = (_, %v), want = (_, nil)", err)
+	}
+	if err := inFlightTX.Commit(); err != nil {
+		t.Fatalf("in-flight Commit() = %v, want = nil", err)
+	}
+	if err := assertStoredTree(ctx, s, tree); err != nil {
+		t.Errorf("in-flight CreateTree() not persisted: %v", err)
+	}
+
+	storage.SetAcceptingWrites(true)
+	if _, err := createTree(ctx, s, LogTree); err != nil {
+		t.Errorf("CreateTree() after re-enabling writes = (_, %v), want = (_, nil)", err)
+	}
+}
+
+// TestReserveTreeID tests tree creation using reserved IDs.
+func (tester *AdminStorageTester) TestReserveTreeID(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	reservedIDs := make(map[int64]bool)
+	for i := 0; i < 5; i++ {
+		id, err := reserveTreeID(ctx, s)
+		if err != nil {
+			t.Fatalf("ReserveTreeID() = (_, %v), want = (_, nil)", err)
+		}
+		reservedIDs[id] = true
+	}
+
+	// Auto-created trees never use live reservations.
+	for i := 0; i < 5; i++ {
+		tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+		if reservedIDs[tree.TreeId] {
+			t.Errorf("CreateTree() used reserved ID %v", tree.TreeId)
+		}
+	}
+
+	// Reserved IDs can be used exactly once.
+	var id int64
+	for id = range reservedIDs {
+		break
+	}
+	withID := proto.Clone(LogTree).(*trillian.Tree)
+	withID.TreeId = id
+	tree, err := createTree(ctx, s, withID)
+	if err != nil {
+		t.Fatalf("CreateTree(reserved) = (_, %v), want = (_, nil)", err)
+	}
+	if tree.TreeId != id {
+		t.Errorf("CreateTree(reserved) returned TreeId = %v, want = %v", tree.TreeId, id)
+	}
+	if err := assertStoredTree(ctx, s, tree); err != nil {
+		t.Errorf("CreateTree(reserved): %v", err)
+	}
+	// Consumed, unreserved and expired IDs are ignored, as they were before
+	// reservations existed.
+	assertNewID := func(s storage.AdminStorage, desc string, id int64) {
+		withID.TreeId = id
+		tree, err := createTree(ctx, s, withID)
+		if err != nil {
+			t.Errorf("CreateTree(%v) = (_, %v), want = (_, nil)", desc, err)
+			return
+		}
+		if tree.TreeId == id {
+			t.Errorf("CreateTree(%v) returned TreeId = %v, want a new ID", desc, tree.TreeId)
+		}
+	}
+	assertNewID(s, "consumed", id)
+	unreservedID, err := storage.NewTreeID()
+	if err != nil {
+		t.Fatalf("NewTreeID() = (_, %v), want = (_, nil)", err)
+	}
+	assertNewID(s, "unreserved", unreservedID)
+
+	if tester.NewAdminStorageWithOptions == nil {
+		return
+	}
+	expiringS := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{TreeIDReservationTTL: -time.Hour})
+	expiredID, err := reserveTreeID(ctx, expiringS)
+	if err != nil {
+		t.Fatalf("ReserveTreeID() = (_, %v), want = (_, nil)", err)
+	}
+	assertNewID(expiringS, "expired", expiredID)
+}
+
 // TestUpdateTree tests AdminStorage Tree updates.
 func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 	ctx := context.Background()
@@ -264,7 +626,8 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 		tree.DisplayName = validMap.DisplayName
 	}
 
-	newPrivateKey := &empty.Empty{}
+	// Any key proto works here, as long as its encoding isn't empty.
+	newPrivateKey := &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P256}
 	privateKeyChangedButKeyMaterialSameTree := *LogTree
 	privateKeyChangedButKeyMaterialSameTree.PrivateKey = testonly.MustMarshalAny(t, newPrivateKey)
 	keys.RegisterHandler(newPrivateKey, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
@@ -359,7 +722,7 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 		if createdTree.TreeId != updatedTree.TreeId {
 			t.Errorf("%v: TreeId = %v, want = %v", test.desc, updatedTree.TreeId, createdTree.TreeId)
 		}
-		if !reflect.DeepEqual(createdTree.CreateTime, updatedTree.CreateTime) {
+		if !proto.Equal(createdTree.CreateTime, updatedTree.CreateTime) {
 			t.Errorf("%v: CreateTime = %v, want = %v", test.desc, updatedTree.CreateTime, createdTree.CreateTime)
 		}
 		createUpdateTime, err := ptypes.Timestamp(createdTree.UpdateTime)
@@ -378,6 +741,8 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 		wantTree.TreeId = updatedTree.TreeId
 		wantTree.CreateTime = updatedTree.CreateTime
 		wantTree.UpdateTime = updatedTree.UpdateTime
+		// Each update is a single write.
+		wantTree.Revision = createdTree.Revision + 1
 		// Ignore storage_settings changes (OK to vary between implementations)
 		wantTree.StorageSettings = updatedTree.StorageSettings
 		if !proto.Equal(updatedTree, &wantTree) {
@@ -385,49 +750,3475 @@ func (tester *AdminStorageTester) TestUpdateTree(t *testing.T) {
 			t.Errorf("%v: updatedTree doesn't match wantTree:\n%s", test.desc, diff)
 		}
 
-		if err := assertStoredTree(ctx, s, updatedTree); err != nil {
-			t.Errorf("%v: %v", test.desc, err)
-		}
+		if err := assertStoredTree(ctx, s, updatedTree); err != nil {
+			t.Errorf("%v: %v", test.desc, err)
+		}
+
+		if err := assertStoredTree(ctx, s, unrelatedTree); err != nil {
+			t.Errorf("%v: %v", test.desc, err)
+		}
+	}
+}
+
+// TestUpdateTreeNoop tests that no-op updates only advance UpdateTime if
+// AdminStorageOptions.AdvanceUpdateTimeOnNoop is set, and that real updates
+// always advance it.
+func (tester *AdminStorageTester) TestUpdateTreeNoop(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+
+	updateTime := func(desc string, tree *trillian.Tree) time.Time {
+		ts, err := ptypes.Timestamp(tree.UpdateTime)
+		if err != nil {
+			t.Fatalf("%v: UpdateTime malformed: %v", desc, err)
+		}
+		return ts
+	}
+	// Timestamps may be stored with millisecond precision, so make sure
+	// time passes between operations.
+	tick := func() { time.Sleep(2 * time.Millisecond) }
+
+	for _, advance := range []bool{false, true} {
+		desc := fmt.Sprintf("AdvanceUpdateTimeOnNoop=%v", advance)
+		s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{AdvanceUpdateTimeOnNoop: advance})
+		tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+		tick()
+		noopTree, _, err := updateTree(ctx, s, tree.TreeId, func(*trillian.Tree) {})
+		if err != nil {
+			t.Fatalf("%v: updateTree(noop) = (_, _, %v), want = (_, _, nil)", desc, err)
+		}
+		switch before, after := updateTime(desc, tree), updateTime(desc, noopTree); {
+		case advance && !after.After(before):
+			t.Errorf("%v: post-noop UpdateTime = %v, want > %v", desc, after, before)
+		case !advance && !after.Equal(before):
+			t.Errorf("%v: post-noop UpdateTime = %v, want = %v", desc, after, before)
+		}
+		if err := assertStoredTree(ctx, s, noopTree); err != nil {
+			t.Errorf("%v: %v", desc, err)
+		}
+
+		tick()
+		changedTree, _, err := updateTree(ctx, s, tree.TreeId, func(tree *trillian.Tree) {
+			tree.Description = "Changed"
+		})
+		if err != nil {
+			t.Fatalf("%v: updateTree(change) = (_, _, %v), want = (_, _, nil)", desc, err)
+		}
+		if before, after := updateTime(desc, noopTree), updateTime(desc, changedTree); !after.After(before) {
+			t.Errorf("%v: post-change UpdateTime = %v, want > %v", desc, after, before)
+		}
+		if err := assertStoredTree(ctx, s, changedTree); err != nil {
+			t.Errorf("%v: %v", desc, err)
+		}
+
+		// Changes made in place to nested messages and maps aren't no-ops.
+		withAppData := proto.Clone(LogTree).(*trillian.Tree)
+		withAppData.AppData = map[string]string{"k": "v"}
+		nestedTree := makeTreeOrFail(ctx, s, spec{Tree: withAppData}, t.Fatalf)
+		editedTree, _, err := updateTree(ctx, s, nestedTree.TreeId, func(tree *trillian.Tree) {
+			tree.AppData["k"] = "edited"
+			tree.MaxRootDuration.Seconds = 5
+		})
+		if err != nil {
+			t.Fatalf("%v: updateTree(in place) = (_, _, %v), want = (_, _, nil)", desc, err)
+		}
+		if got, want := editedTree.AppData["k"], "edited"; got != want {
+			t.Errorf("%v: post-update AppData[k] = %q, want = %q", desc, got, want)
+		}
+		if got, want := editedTree.MaxRootDuration.GetSeconds(), int64(5); got != want {
+			t.Errorf("%v: post-update MaxRootDuration.Seconds = %v, want = %v", desc, got, want)
+		}
+		if err := assertStoredTree(ctx, s, editedTree); err != nil {
+			t.Errorf("%v: %v", desc, err)
+		}
+	}
+}
+
+// TestFreezeIsTerminal tests that FROZEN trees may only be unfrozen if
+// AdminStorageOptions.FreezeIsTerminal isn't set.
+func (tester *AdminStorageTester) TestFreezeIsTerminal(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+
+	for _, terminal := range []bool{false, true} {
+		desc := fmt.Sprintf("FreezeIsTerminal=%v", terminal)
+		s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{FreezeIsTerminal: terminal})
+		tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+		// Freezing is always allowed.
+		frozenTree, _, err := updateTree(ctx, s, tree.TreeId, func(tree *trillian.Tree) {
+			tree.TreeState = trillian.TreeState_FROZEN
+		})
+		if err != nil {
+			t.Fatalf("%v: updateTree(freeze) = (_, _, %v), want = (_, _, nil)", desc, err)
+		}
+		// Updates that leave the tree FROZEN are allowed too.
+		if _, _, err := updateTree(ctx, s, tree.TreeId, func(tree *trillian.Tree) {
+			tree.Description = "Frozen"
+		}); err != nil {
+			t.Errorf("%v: updateTree(description) = (_, _, %v), want = (_, _, nil)", desc, err)
+		}
+
+		_, _, err = updateTree(ctx, s, tree.TreeId, func(tree *trillian.Tree) {
+			tree.TreeState = trillian.TreeState_ACTIVE
+		})
+		switch {
+		case terminal && errors.ErrorCode(err) != errors.FailedPrecondition:
+			t.Errorf("%v: updateTree(unfreeze) returned err = %v, wantCode = %s", desc, err, errors.FailedPrecondition)
+		case !terminal && err != nil:
+			t.Errorf("%v: updateTree(unfreeze) = (_, _, %v), want = (_, _, nil)", desc, err)
+		}
+
+		stored, err := getTree(ctx, s, frozenTree.TreeId)
+		if err != nil {
+			t.Fatalf("%v: getTree() = (_, %v), want = (_, nil)", desc, err)
+		}
+		want := trillian.TreeState_ACTIVE
+		if terminal {
+			want = trillian.TreeState_FROZEN
+		}
+		if got := stored.TreeState; got != want {
+			t.Errorf("%v: TreeState = %s, want = %s", desc, got, want)
+		}
+	}
+}
+
+// TestSignatureAlgorithmImmutable tests that signature_algorithm can't be
+// changed by updates, while key handler migrations keeping the algorithm
+// succeed.
+func (tester *AdminStorageTester) TestSignatureAlgorithmImmutable(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	log := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	if _, _, err := updateTree(ctx, s, log.TreeId, func(tree *trillian.Tree) {
+		tree.SignatureAlgorithm = spb.DigitallySigned_RSA
+	}); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("updateTree(ECDSA->RSA) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+	if err := assertStoredTree(ctx, s, log); err != nil {
+		t.Errorf("tree modified by failed updateTree(): %v", err)
+	}
+
+	// Custom handler resolving to LogTree's key, as per TestMigrateKeyHandler.
+	handlerKey := &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P256}
+	keys.RegisterHandler(handlerKey, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		return pem.UnmarshalPrivateKey(privateKeyPEM, privateKeyPass)
+	})
+	defer keys.UnregisterHandler(handlerKey)
+	migrated, err := storage.MigrateKeyHandler(ctx, s, log.TreeId, testonly.MustMarshalAny(t, handlerKey))
+	if err != nil {
+		t.Fatalf("MigrateKeyHandler() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := migrated.SignatureAlgorithm, spb.DigitallySigned_ECDSA; got != want {
+		t.Errorf("post-MigrateKeyHandler() SignatureAlgorithm = %s, want = %s", got, want)
+	}
+}
+
+// TestReplaceTree tests that ReplaceTree applies the desired tree if the
+// expected revision is current, and fails on stale revisions.
+func (tester *AdminStorageTester) TestReplaceTree(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	log := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	staleRevision := storage.TreeRevision(log)
+
+	desired := proto.Clone(log).(*trillian.Tree)
+	desired.TreeState = trillian.TreeState_FROZEN
+	desired.DisplayName = "Replaced Tree"
+	desired.Description = "Replaced description"
+	desired.Labels = []string{"replaced"}
+	replaced, err := replaceTree(ctx, s, desired, staleRevision)
+	if err != nil {
+		t.Fatalf("ReplaceTree() = (_, %v), want = (_, nil)", err)
+	}
+	if replaced.TreeState != desired.TreeState || replaced.DisplayName != desired.DisplayName || replaced.Description != desired.Description || !reflect.DeepEqual(replaced.Labels, desired.Labels) {
+		t.Errorf("ReplaceTree() = %v, want editable fields of %v", replaced, desired)
+	}
+	if got, want := storage.TreeRevision(replaced), staleRevision+1; got != want {
+		t.Errorf("ReplaceTree() returned revision %v, want = %v", got, want)
+	}
+	if err := assertStoredTree(ctx, s, replaced); err != nil {
+		t.Errorf("ReplaceTree() not persisted: %v", err)
+	}
+
+	// staleRevision was consumed by the replacement above.
+	stale := proto.Clone(replaced).(*trillian.Tree)
+	stale.DisplayName = "Stale Tree"
+	if _, err := replaceTree(ctx, s, stale, staleRevision); errors.ErrorCode(err) != errors.FailedPrecondition {
+		t.Errorf("ReplaceTree(stale revision) returned err = %v, wantCode = %s", err, errors.FailedPrecondition)
+	}
+	if err := assertStoredTree(ctx, s, replaced); err != nil {
+		t.Errorf("tree modified by failed ReplaceTree(): %v", err)
+	}
+}
+
+// TestConcurrentReplaceTree tests that of two transactions replacing a tree
+// with the same expected revision, only one succeeds.
+func (tester *AdminStorageTester) TestConcurrentReplaceTree(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	revision := storage.TreeRevision(tree)
+
+	names := []string{"Writer 0", "Writer 1"}
+	errs := raceWrites(ctx, s, tree.TreeId, func(ctx context.Context, tx storage.AdminTX, i int) error {
+		desired := proto.Clone(tree).(*trillian.Tree)
+		desired.DisplayName = names[i]
+		_, err := tx.ReplaceTree(ctx, desired, revision)
+		return err
+	})
+	winner := -1
+	for i, err := range errs {
+		if err == nil {
+			winner = i
+		}
+	}
+	// Storages may either refuse the stale write or fail it on conflict, but
+	// mustn't apply both.
+	if errs[0] == nil && errs[1] == nil {
+		t.Fatalf("ReplaceTree() succeeded in both transactions, want one to fail")
+	}
+	if winner == -1 {
+		t.Fatalf("ReplaceTree() failed in both transactions: %v", errs)
+	}
+
+	stored, err := getTree(ctx, s, tree.TreeId)
+	if err != nil {
+		t.Fatalf("getTree() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := stored.DisplayName, names[winner]; got != want {
+		t.Errorf("stored DisplayName = %q, want = %q", got, want)
+	}
+	if got, want := storage.TreeRevision(stored), revision+1; got != want {
+		t.Errorf("stored revision = %v, want = %v", got, want)
+	}
+}
+
+// TestConcurrentSoftDeleteTreeIfUnchanged tests that a tree isn't deleted by
+// SoftDeleteTreeIfUnchanged if it's replaced by a concurrent transaction, and
+// vice versa.
+func (tester *AdminStorageTester) TestConcurrentSoftDeleteTreeIfUnchanged(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	revision := storage.TreeRevision(tree)
+
+	const replacer, deleter = 0, 1
+	errs := raceWrites(ctx, s, tree.TreeId, func(ctx context.Context, tx storage.AdminTX, i int) error {
+		if i == deleter {
+			_, err := tx.SoftDeleteTreeIfUnchanged(ctx, tree.TreeId, revision)
+			return err
+		}
+		desired := proto.Clone(tree).(*trillian.Tree)
+		desired.DisplayName = "Replaced Tree"
+		_, err := tx.ReplaceTree(ctx, desired, revision)
+		return err
+	})
+	if (errs[replacer] == nil) == (errs[deleter] == nil) {
+		t.Fatalf("raceWrites() = %v, want exactly one write to succeed", errs)
+	}
+
+	stored, err := getTree(ctx, s, tree.TreeId)
+	if err != nil {
+		t.Fatalf("getTree() = (_, %v), want = (_, nil)", err)
+	}
+	replaced := errs[replacer] == nil
+	if got, want := stored.Deleted, !replaced; got != want {
+		t.Errorf("stored Deleted = %v, want = %v", got, want)
+	}
+	if got, want := stored.DisplayName == "Replaced Tree", replaced; got != want {
+		t.Errorf("stored DisplayName = %q, want replaced = %v", stored.DisplayName, want)
+	}
+	if got, want := storage.TreeRevision(stored), revision+1; got != want {
+		t.Errorf("stored revision = %v, want = %v", got, want)
+	}
+}
+
+// raceWrites runs write in two separate transactions at once, numbered 0 and
+// 1. Both transactions read treeID before either writes, as callers racing on
+// the same revision would. It returns the errors of each write and commit.
+func raceWrites(ctx context.Context, s storage.AdminStorage, treeID int64, write func(ctx context.Context, tx storage.AdminTX, i int) error) [2]error {
+	var errs [2]error
+	var read, done sync.WaitGroup
+	read.Add(len(errs))
+	done.Add(len(errs))
+	for i := range errs {
+		go func(i int) {
+			defer done.Done()
+			tx, err := s.Begin(ctx)
+			if err == nil {
+				defer tx.Close()
+				_, err = tx.GetTree(ctx, treeID)
+			}
+			read.Done()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			read.Wait()
+			if err := write(ctx, tx, i); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = tx.Commit()
+		}(i)
+	}
+	done.Wait()
+	return errs
+}
+
+// TestSoftDeleteTreeIfUnchanged tests that SoftDeleteTreeIfUnchanged only
+// deletes trees whose revision is the expected one.
+func (tester *AdminStorageTester) TestSoftDeleteTreeIfUnchanged(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	log := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	staleRevision := storage.TreeRevision(log)
+
+	updated, _, err := updateTree(ctx, s, log.TreeId, func(tree *trillian.Tree) {
+		tree.Description = "Changed by another operator"
+	})
+	if err != nil {
+		t.Fatalf("updateTree() = (_, _, %v), want = (_, _, nil)", err)
+	}
+
+	if _, err := softDeleteTreeIfUnchanged(ctx, s, log.TreeId, staleRevision); errors.ErrorCode(err) != errors.FailedPrecondition {
+		t.Errorf("SoftDeleteTreeIfUnchanged(stale revision) returned err = %v, wantCode = %s", err, errors.FailedPrecondition)
+	}
+	if err := assertStoredTree(ctx, s, updated); err != nil {
+		t.Errorf("tree modified by failed SoftDeleteTreeIfUnchanged(): %v", err)
+	}
+
+	deleted, err := softDeleteTreeIfUnchanged(ctx, s, log.TreeId, storage.TreeRevision(updated))
+	if err != nil {
+		t.Fatalf("SoftDeleteTreeIfUnchanged() = (_, %v), want = (_, nil)", err)
+	}
+	if !deleted.Deleted {
+		t.Errorf("SoftDeleteTreeIfUnchanged() returned Deleted = false, want = true")
+	}
+	if err := assertStoredTree(ctx, s, deleted); err != nil {
+		t.Errorf("SoftDeleteTreeIfUnchanged() not persisted: %v", err)
+	}
+	if got, want := storage.TreeRevision(deleted), storage.TreeRevision(updated)+1; got != want {
+		t.Errorf("SoftDeleteTreeIfUnchanged() returned revision %v, want = %v", got, want)
+	}
+
+	// Undeletion changes the revision too, so revisions read before it are
+	// stale afterwards.
+	undeleted, err := undeleteTree(ctx, s, log.TreeId)
+	if err != nil {
+		t.Fatalf("UndeleteTree() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := storage.TreeRevision(undeleted), storage.TreeRevision(deleted)+1; got != want {
+		t.Errorf("UndeleteTree() returned revision %v, want = %v", got, want)
+	}
+	if _, err := softDeleteTreeIfUnchanged(ctx, s, log.TreeId, storage.TreeRevision(deleted)); errors.ErrorCode(err) != errors.FailedPrecondition {
+		t.Errorf("SoftDeleteTreeIfUnchanged(revision before undelete) returned err = %v, wantCode = %s", err, errors.FailedPrecondition)
+	}
+
+	if _, err := softDeleteTreeIfUnchanged(ctx, s, 12345, 0); errors.ErrorCode(err) != errors.NotFound {
+		t.Errorf("SoftDeleteTreeIfUnchanged(12345) returned err = %v, wantCode = %s", err, errors.NotFound)
+	}
+}
+
+// TestTimestampMonotonicity tests that CreateTime <= UpdateTime <= DeleteTime
+// holds for a tree throughout its lifecycle, and that undeletion clears
+// DeleteTime. Timestamps are taken from AdminStorageOptions.TimeSource.
+func (tester *AdminStorageTester) TestTimestampMonotonicity(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+	createTime := time.Unix(1500000000, 0)
+	updateTime := createTime.Add(time.Second)
+	deleteTime := updateTime.Add(time.Second)
+	fakeTime := util.NewFakeTimeSource(createTime)
+	s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{TimeSource: fakeTime})
+
+	checkTime := func(desc, field string, ts *timestamp.Timestamp, want time.Time) {
+		got, err := ptypes.Timestamp(ts)
+		if err != nil {
+			t.Fatalf("%v: invalid %v: %v", desc, field, err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("%v: %v = %v, want = %v", desc, field, got, want)
+		}
+	}
+
+	created := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	checkTime("after create", "CreateTime", created.CreateTime, createTime)
+	checkTime("after create", "UpdateTime", created.UpdateTime, createTime)
+
+	fakeTime.Set(updateTime)
+	updated, _, err := updateTree(ctx, s, created.TreeId, func(tree *trillian.Tree) {
+		tree.Description = "Updated"
+	})
+	if err != nil {
+		t.Fatalf("updateTree() = (_, _, %v), want = (_, _, nil)", err)
+	}
+	checkTime("after update", "CreateTime", updated.CreateTime, createTime)
+	checkTime("after update", "UpdateTime", updated.UpdateTime, updateTime)
+
+	fakeTime.Set(deleteTime)
+	deleted, err := softDeleteTree(ctx, s, created.TreeId)
+	if err != nil {
+		t.Fatalf("SoftDeleteTree() = (_, %v), want = (_, nil)", err)
+	}
+	checkTime("after soft delete", "CreateTime", deleted.CreateTime, createTime)
+	checkTime("after soft delete", "UpdateTime", deleted.UpdateTime, updateTime)
+	checkTime("after soft delete", "DeleteTime", deleted.DeleteTime, deleteTime)
+
+	fakeTime.Set(deleteTime.Add(time.Second))
+	undeleted, err := undeleteTree(ctx, s, created.TreeId)
+	if err != nil {
+		t.Fatalf("UndeleteTree() = (_, %v), want = (_, nil)", err)
+	}
+	checkTime("after undelete", "CreateTime", undeleted.CreateTime, createTime)
+	checkTime("after undelete", "UpdateTime", undeleted.UpdateTime, updateTime)
+	if undeleted.DeleteTime != nil {
+		t.Errorf("after undelete: DeleteTime = %v, want = nil", undeleted.DeleteTime)
+	}
+}
+
+// forbiddenFields is a storage.FieldPolicy that denies edits to its fields.
+type forbiddenFields []string
+
+func (f forbiddenFields) CanEdit(ctx context.Context, field string) bool {
+	for _, forbidden := range f {
+		if field == forbidden {
+			return false
+		}
+	}
+	return true
+}
+
+// TestFieldPolicy tests that storage.WithFieldPolicy rejects updates to
+// forbidden fields, while allowing other updates.
+func (tester *AdminStorageTester) TestFieldPolicy(t *testing.T) {
+	ctx := context.Background()
+	s := storage.WithFieldPolicy(tester.NewAdminStorage(), forbiddenFields{"max_root_duration"})
+	log := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	if _, _, err := updateTree(ctx, s, log.TreeId, func(tree *trillian.Tree) {
+		tree.MaxRootDuration = ptypes.DurationProto(time.Hour)
+	}); errors.ErrorCode(err) != errors.PermissionDenied {
+		t.Errorf("updateTree(max_root_duration) returned err = %v, wantCode = %s", err, errors.PermissionDenied)
+	}
+	if err := assertStoredTree(ctx, s, log); err != nil {
+		t.Errorf("tree modified by forbidden updateTree(): %v", err)
+	}
+
+	updated, _, err := updateTree(ctx, s, log.TreeId, func(tree *trillian.Tree) {
+		tree.DisplayName = "Policy Tree"
+	})
+	if err != nil {
+		t.Fatalf("updateTree(display_name) = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := updated.DisplayName, "Policy Tree"; got != want {
+		t.Errorf("updateTree(display_name): DisplayName = %q, want = %q", got, want)
+	}
+}
+
+// TestMigrateKeyHandler tests that trees can move their private key to a
+// different key handler, as long as the logical key stays the same.
+func (tester *AdminStorageTester) TestMigrateKeyHandler(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	// Custom handler resolving to LogTree's key, as per TestUpdateTree.
+	handlerKey := &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P256}
+	newPriv := testonly.MustMarshalAny(t, handlerKey)
+	keys.RegisterHandler(handlerKey, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		return pem.UnmarshalPrivateKey(privateKeyPEM, privateKeyPass)
+	})
+	defer keys.UnregisterHandler(handlerKey)
+
+	log := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	migrated, err := storage.MigrateKeyHandler(ctx, s, log.TreeId, newPriv)
+	if err != nil {
+		t.Fatalf("MigrateKeyHandler() = (_, %v), want = (_, nil)", err)
+	}
+	if !proto.Equal(migrated.PrivateKey, newPriv) {
+		t.Errorf("post-MigrateKeyHandler() PrivateKey = %v, want = %v", migrated.PrivateKey, newPriv)
+	}
+	if !proto.Equal(migrated.PublicKey, log.PublicKey) {
+		t.Errorf("post-MigrateKeyHandler() PublicKey = %v, want = %v", migrated.PublicKey, log.PublicKey)
+	}
+	if err := assertStoredTree(ctx, s, migrated); err != nil {
+		t.Errorf("MigrateKeyHandler() not persisted: %v", err)
+	}
+
+	// Signatures from the migrated key still verify against the public key.
+	signer, err := keys.NewSigner(ctx, handlerKey)
+	if err != nil {
+		t.Fatalf("NewSigner() = (_, %v), want = (_, nil)", err)
+	}
+	publicKey, err := der.UnmarshalPublicKey(migrated.PublicKey.GetDer())
+	if err != nil {
+		t.Fatalf("UnmarshalPublicKey() = (_, %v), want = (_, nil)", err)
+	}
+	data := []byte("llamas")
+	sig, err := tcrypto.NewSHA256Signer(signer).Sign(data)
+	if err != nil {
+		t.Fatalf("Sign() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tcrypto.Verify(publicKey, data, sig); err != nil {
+		t.Errorf("Verify() = %v, want = nil", err)
+	}
+
+	// MapTree has a different key, so the handler doesn't match it.
+	mapTree := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	tests := []struct {
+		desc    string
+		treeID  int64
+		newPriv *any.Any
+	}{
+		{desc: "mismatchedKey", treeID: mapTree.TreeId, newPriv: newPriv},
+		{desc: "sameType", treeID: mapTree.TreeId, newPriv: LogTree.PrivateKey},
+	}
+	for _, test := range tests {
+		if _, err := storage.MigrateKeyHandler(ctx, s, test.treeID, test.newPriv); errors.ErrorCode(err) != errors.InvalidArgument {
+			t.Errorf("%v: MigrateKeyHandler() returned err = %v, wantCode = %s", test.desc, err, errors.InvalidArgument)
+		}
+	}
+	if err := assertStoredTree(ctx, s, mapTree); err != nil {
+		t.Errorf("tree modified by failed MigrateKeyHandler(): %v", err)
+	}
+}
+
+// TestPrivateKeyAccess tests that private keys are only returned to contexts
+// derived from storage.WithPrivateKeyAccess, as per
+// AdminStorageOptions.RequirePrivateKeyAccess.
+func (tester *AdminStorageTester) TestPrivateKeyAccess(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+	s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{RequirePrivateKeyAccess: true})
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	tests := []struct {
+		desc           string
+		ctx            context.Context
+		wantPrivateKey *any.Any
+	}{
+		{desc: "noAccess", ctx: ctx},
+		{desc: "access", ctx: storage.WithPrivateKeyAccess(ctx), wantPrivateKey: LogTree.PrivateKey},
+	}
+	for _, test := range tests {
+		storedTree, err := getTree(test.ctx, s, tree.TreeId)
+		if err != nil {
+			t.Errorf("%v: getTree() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if got, want := storedTree.PrivateKey, test.wantPrivateKey; !proto.Equal(got, want) {
+			t.Errorf("%v: GetTree().PrivateKey = %v, want = %v", test.desc, got, want)
+		}
+
+		tx, err := s.Snapshot(test.ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		trees, err := tx.ListTrees(test.ctx, false /* includeDeleted */)
+		if err != nil {
+			t.Errorf("%v: ListTrees() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Errorf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+		tx.Close()
+		for _, listed := range trees {
+			if got, want := listed.PrivateKey, test.wantPrivateKey; listed.TreeId == tree.TreeId && !proto.Equal(got, want) {
+				t.Errorf("%v: ListTrees()[%v].PrivateKey = %v, want = %v", test.desc, listed.TreeId, got, want)
+			}
+		}
+	}
+}
+
+// TestPrivateKeyAccessWrites tests that AdminWriter methods redact the trees
+// they return, and that helpers which need the key material still see it.
+func (tester *AdminStorageTester) TestPrivateKeyAccessWrites(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+	s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{RequirePrivateKeyAccess: true})
+
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	created, err := tx.CreateTree(ctx, LogTree)
+	if err != nil {
+		t.Fatalf("CreateTree() = (_, %v), want = (_, nil)", err)
+	}
+	if created.PrivateKey != nil {
+		t.Errorf("CreateTree().PrivateKey = %v, want = nil", created.PrivateKey)
+	}
+	id := created.TreeId
+
+	writes := []struct {
+		desc  string
+		write func() (*trillian.Tree, error)
+	}{
+		{
+			desc: "UpdateTree",
+			write: func() (*trillian.Tree, error) {
+				return tx.UpdateTree(ctx, id, func(tree *trillian.Tree) { tree.Description = "Updated" })
+			},
+		},
+		{
+			desc: "UpdateTrees",
+			write: func() (*trillian.Tree, error) {
+				trees, err := tx.UpdateTrees(ctx, map[int64]func(*trillian.Tree){id: func(tree *trillian.Tree) { tree.DisplayName = "Updated" }})
+				if err != nil {
+					return nil, err
+				}
+				return trees[0], nil
+			},
+		},
+		{
+			desc: "TouchTrees",
+			write: func() (*trillian.Tree, error) {
+				trees, err := tx.TouchTrees(ctx, []int64{id})
+				if err != nil {
+					return nil, err
+				}
+				return trees[0], nil
+			},
+		},
+		{desc: "PauseSequencing", write: func() (*trillian.Tree, error) { return tx.PauseSequencing(ctx, id) }},
+		{desc: "ResumeSequencing", write: func() (*trillian.Tree, error) { return tx.ResumeSequencing(ctx, id) }},
+		{desc: "SoftDeleteTree", write: func() (*trillian.Tree, error) { return tx.SoftDeleteTree(ctx, id) }},
+		{desc: "UndeleteTree", write: func() (*trillian.Tree, error) { return tx.UndeleteTree(ctx, id) }},
+	}
+	for _, w := range writes {
+		tree, err := w.write()
+		if err != nil {
+			t.Fatalf("%v() = (_, %v), want = (_, nil)", w.desc, err)
+		}
+		if tree.PrivateKey != nil {
+			t.Errorf("%v().PrivateKey = %v, want = nil", w.desc, tree.PrivateKey)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := storage.ExportTreesSince(ctx, s, &buf, nil /* since */); err != nil {
+		t.Fatalf("ExportTreesSince() = (_, %v), want = (_, nil)", err)
+	}
+	exported, err := storage.ReadExportedTrees(&buf)
+	if err != nil {
+		t.Fatalf("ReadExportedTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if len(exported) != 1 {
+		t.Fatalf("ExportTreesSince() exported %v trees, want = 1", len(exported))
+	}
+	if got, want := exported[0].PrivateKey, LogTree.PrivateKey; !proto.Equal(got, want) {
+		t.Errorf("exported PrivateKey = %v, want = %v", got, want)
+	}
+}
+
+// TestRecordSequencingProgress tests recording and reading
+// Tree.LastSequencedTime.
+func (tester *AdminStorageTester) TestRecordSequencingProgress(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	logTree := proto.Clone(LogTree).(*trillian.Tree)
+	logTree.MaxRootDuration = ptypes.DurationProto(time.Hour)
+	tree := makeTreeOrFail(ctx, s, spec{Tree: logTree}, t.Fatalf)
+	if tree.LastSequencedTime != nil {
+		t.Errorf("CreateTree() LastSequencedTime = %v, want = nil", tree.LastSequencedTime)
+	}
+
+	// Use second precision to avoid truncation by storage.
+	sequencedAt := time.Unix(time.Now().Unix(), 0)
+	when, err := ptypes.TimestampProto(sequencedAt)
+	if err != nil {
+		t.Fatalf("TimestampProto() = (_, %v), want = (_, nil)", err)
+	}
+	record := func(treeID int64) error {
+		tx, err := s.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Close()
+		if err := tx.RecordSequencingProgress(ctx, treeID, when); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	if err := record(tree.TreeId); err != nil {
+		t.Fatalf("RecordSequencingProgress() = %v, want = nil", err)
+	}
+	if err := record(tree.TreeId + 1); errors.ErrorCode(err) != errors.NotFound {
+		t.Errorf("RecordSequencingProgress(unknown tree) returned err = %v, wantCode = %s", err, errors.NotFound)
+	}
+
+	storedTree, err := getTree(ctx, s, tree.TreeId)
+	if err != nil {
+		t.Fatalf("getTree() = (_, %v), want = (_, nil)", err)
+	}
+	if !proto.Equal(storedTree.LastSequencedTime, when) {
+		t.Errorf("LastSequencedTime = %v, want = %v", storedTree.LastSequencedTime, when)
+	}
+	// Recording progress isn't a config update.
+	tree.LastSequencedTime = when
+	if !proto.Equal(storedTree, tree) {
+		t.Errorf("RecordSequencingProgress() modified other fields: got %v, want %v", storedTree, tree)
+	}
+
+	tests := []struct {
+		desc      string
+		now       time.Time
+		wantStale bool
+	}{
+		{desc: "recentProgress", now: sequencedAt.Add(30 * time.Minute)},
+		{desc: "oldProgress", now: sequencedAt.Add(2 * time.Hour), wantStale: true},
+	}
+	for _, test := range tests {
+		stale, err := storage.FindStaleLogTreesBySignal(ctx, s, nil /* logS */, test.now, storage.SequencingSignal)
+		if err != nil {
+			t.Errorf("%v: FindStaleLogTreesBySignal() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if got := len(stale) == 1 && stale[0] == tree.TreeId; got != test.wantStale {
+			t.Errorf("%v: FindStaleLogTreesBySignal() = %v, want stale = %v for tree %v", test.desc, stale, test.wantStale, tree.TreeId)
+		}
+	}
+}
+
+// TestWritesDisabled tests toggling Tree.WritesDisabled via UpdateTree.
+func (tester *AdminStorageTester) TestWritesDisabled(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	if !storage.IsTreeWritable(tree) {
+		t.Fatalf("IsTreeWritable() = false for new tree, want = true")
+	}
+
+	for _, disabled := range []bool{true, false} {
+		updated, _, err := updateTree(ctx, s, tree.TreeId, func(tree *trillian.Tree) {
+			tree.WritesDisabled = disabled
+		})
+		if err != nil {
+			t.Fatalf("WritesDisabled = %v: UpdateTree() = (_, %v), want = (_, nil)", disabled, err)
+		}
+		if got, want := storage.IsTreeWritable(updated), !disabled; got != want {
+			t.Errorf("WritesDisabled = %v: IsTreeWritable() = %v, want = %v", disabled, got, want)
+		}
+		if got, want := updated.TreeState, trillian.TreeState_ACTIVE; got != want {
+			t.Errorf("WritesDisabled = %v: TreeState = %s, want = %s", disabled, got, want)
+		}
+		if err := assertStoredTree(ctx, s, updated); err != nil {
+			t.Errorf("WritesDisabled = %v: UpdateTree() not persisted: %v", disabled, err)
+		}
+	}
+}
+
+// TestUpdateTrees tests AdminStorage batch tree updates.
+func (tester *AdminStorageTester) TestUpdateTrees(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	logTree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	mapTree := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+
+	freezeFunc := func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_FROZEN
+	}
+	renameFunc := func(tree *trillian.Tree) {
+		tree.DisplayName = "Renamed Tree"
+	}
+	invalidFunc := func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_UNKNOWN_TREE_STATE
+	}
+
+	// An invalid update aborts the whole batch.
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	_, err = tx.UpdateTrees(ctx, map[int64]func(*trillian.Tree){
+		logTree.TreeId: freezeFunc,
+		mapTree.TreeId: invalidFunc,
+	})
+	tx.Close()
+	multiErr, ok := err.(errors.MultiError)
+	if !ok {
+		t.Fatalf("UpdateTrees() = (_, %v), want = (_, errors.MultiError)", err)
+	}
+	if len(multiErr) != 1 || !strings.Contains(multiErr[0].Error(), fmt.Sprint(mapTree.TreeId)) {
+		t.Errorf("UpdateTrees() = (_, %v), want a single error naming tree %v", err, mapTree.TreeId)
+	}
+	for _, tree := range []*trillian.Tree{logTree, mapTree} {
+		if err := assertStoredTree(ctx, s, tree); err != nil {
+			t.Errorf("tree %v modified by failed UpdateTrees(): %v", tree.TreeId, err)
+		}
+	}
+
+	// Valid updates are all applied.
+	tx, err = s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	updated, err := tx.UpdateTrees(ctx, map[int64]func(*trillian.Tree){
+		logTree.TreeId: freezeFunc,
+		mapTree.TreeId: renameFunc,
+	})
+	if err != nil {
+		t.Fatalf("UpdateTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+	if got, want := len(updated), 2; got != want {
+		t.Fatalf("UpdateTrees() returned %v trees, want = %v", got, want)
+	}
+
+	wantLog := proto.Clone(logTree).(*trillian.Tree)
+	freezeFunc(wantLog)
+	wantMap := proto.Clone(mapTree).(*trillian.Tree)
+	renameFunc(wantMap)
+	for _, want := range []*trillian.Tree{wantLog, wantMap} {
+		var got *trillian.Tree
+		for _, tree := range updated {
+			if tree.TreeId == want.TreeId {
+				got = tree
+			}
+		}
+		if got == nil {
+			t.Errorf("UpdateTrees() didn't return tree %v", want.TreeId)
+			continue
+		}
+		want.UpdateTime = got.UpdateTime
+		want.Revision++
+		if !proto.Equal(got, want) {
+			t.Errorf("post-UpdateTrees() diff (-got +want):\n%v", pretty.Compare(got, want))
+		}
+		if err := assertStoredTree(ctx, s, want); err != nil {
+			t.Errorf("tree %v: %v", want.TreeId, err)
+		}
+	}
+}
+
+// TestTouchTrees tests that TouchTrees advances the UpdateTime of all trees
+// to the same time, leaving their config unchanged, and that it's
+// all-or-nothing.
+func (tester *AdminStorageTester) TestTouchTrees(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tree1 := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	tree2 := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	untouched := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	touch := func(treeIDs ...int64) ([]*trillian.Tree, error) {
+		tx, err := s.Begin(ctx)
+		if err != nil {
+			t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+		}
+		defer tx.Close()
+		trees, err := tx.TouchTrees(ctx, treeIDs)
+		if err != nil {
+			return nil, err
+		}
+		return trees, tx.Commit()
+	}
+
+	// Timestamps may be stored with millisecond precision, so make sure
+	// time passes before touching.
+	time.Sleep(2 * time.Millisecond)
+	if _, err := touch(tree1.TreeId, 12345); errors.ErrorCode(err) != errors.NotFound || !strings.Contains(err.Error(), "12345") {
+		t.Errorf("TouchTrees(%v, 12345) returned err = %v, want NotFound error naming 12345", tree1.TreeId, err)
+	}
+	if err := assertStoredTree(ctx, s, tree1); err != nil {
+		t.Errorf("after failed TouchTrees(): %v", err)
+	}
+
+	touched, err := touch(tree2.TreeId, tree1.TreeId, tree2.TreeId)
+	if err != nil {
+		t.Fatalf("TouchTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := len(touched), 2; got != want {
+		t.Fatalf("TouchTrees() returned %v trees, want = %v", got, want)
+	}
+	wantTrees := []*trillian.Tree{tree1, tree2}
+	sort.Slice(wantTrees, func(i, j int) bool { return wantTrees[i].TreeId < wantTrees[j].TreeId })
+	for i, before := range wantTrees {
+		after := touched[i]
+		if got, want := after.TreeId, before.TreeId; got != want {
+			t.Errorf("TouchTrees()[%v].TreeId = %v, want = %v", i, got, want)
+			continue
+		}
+		if !proto.Equal(after.UpdateTime, touched[0].UpdateTime) {
+			t.Errorf("TouchTrees()[%v].UpdateTime = %v, want = %v", i, after.UpdateTime, touched[0].UpdateTime)
+		}
+		beforeTime, _ := ptypes.Timestamp(before.UpdateTime)
+		afterTime, _ := ptypes.Timestamp(after.UpdateTime)
+		if !afterTime.After(beforeTime) {
+			t.Errorf("TouchTrees()[%v].UpdateTime = %v, want > %v", i, afterTime, beforeTime)
+		}
+		if diff := storage.TreeConfigDiff(before, after); len(diff) != 0 {
+			t.Errorf("TouchTrees()[%v] changed config: %+v", i, diff)
+		}
+		if err := assertStoredTree(ctx, s, after); err != nil {
+			t.Errorf("TouchTrees()[%v]: %v", i, err)
+		}
+	}
+	if err := assertStoredTree(ctx, s, untouched); err != nil {
+		t.Errorf("untouched tree: %v", err)
+	}
+}
+
+// TestConcurrentTouchTrees tests that concurrent TouchTrees calls on the same
+// tree each advance its revision, rather than overwriting one another.
+func (tester *AdminStorageTester) TestConcurrentTouchTrees(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	revision := storage.TreeRevision(tree)
+
+	var touched [2]*trillian.Tree
+	errs := raceWrites(ctx, s, tree.TreeId, func(ctx context.Context, tx storage.AdminTX, i int) error {
+		trees, err := tx.TouchTrees(ctx, []int64{tree.TreeId})
+		if err != nil {
+			return err
+		}
+		touched[i] = trees[0]
+		return nil
+	})
+	// Storages may fail either transaction on conflict, but each successful
+	// one must count.
+	succeeded := int64(0)
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	if succeeded == 0 {
+		t.Fatalf("TouchTrees() failed in both transactions: %v", errs)
+	}
+
+	stored, err := getTree(ctx, s, tree.TreeId)
+	if err != nil {
+		t.Fatalf("getTree() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := storage.TreeRevision(stored), revision+succeeded; got != want {
+		t.Errorf("stored revision = %v, want = %v", got, want)
+	}
+	if succeeded == 1 {
+		for i, err := range errs {
+			if err == nil && storage.TreeRevision(touched[i]) != storage.TreeRevision(stored) {
+				t.Errorf("TouchTrees() returned revision %v, want = %v", storage.TreeRevision(touched[i]), storage.TreeRevision(stored))
+			}
+		}
+	}
+}
+
+// TestSetTreeLabels tests AdminStorage label replacement.
+func (tester *AdminStorageTester) TestSetTreeLabels(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	labeledLog := proto.Clone(LogTree).(*trillian.Tree)
+	labeledLog.Labels = []string{"llamas", "alpacas"}
+	tree := makeTreeOrFail(ctx, s, spec{Tree: labeledLog}, t.Fatalf)
+
+	// Replace all labels
+	wantLabels := []string{"guanacos", "vicunas"}
+	tree, err := setTreeLabels(ctx, s, tree.TreeId, []string{"vicunas", "guanacos"})
+	if err != nil {
+		t.Fatalf("SetTreeLabels() = (_, %v), want = (_, nil)", err)
+	}
+	if diff := pretty.Compare(tree.Labels, wantLabels); diff != "" {
+		t.Errorf("post-SetTreeLabels() labels diff (-got +want):\n%v", diff)
+	}
+	if err := assertStoredTree(ctx, s, tree); err != nil {
+		t.Errorf("SetTreeLabels() not persisted: %v", err)
+	}
+
+	// Remove all labels
+	tree, err = setTreeLabels(ctx, s, tree.TreeId, nil)
+	if err != nil {
+		t.Fatalf("SetTreeLabels(nil) = (_, %v), want = (_, nil)", err)
+	}
+	if len(tree.Labels) != 0 {
+		t.Errorf("post-SetTreeLabels(nil) labels = %v, want none", tree.Labels)
+	}
+	if err := assertStoredTree(ctx, s, tree); err != nil {
+		t.Errorf("SetTreeLabels(nil) not persisted: %v", err)
+	}
+
+	// Invalid labels don't touch the stored tree
+	tree, err = setTreeLabels(ctx, s, tree.TreeId, wantLabels)
+	if err != nil {
+		t.Fatalf("SetTreeLabels() = (_, %v), want = (_, nil)", err)
+	}
+	var tooManyLabels []string
+	for i := 0; i < 100; i++ {
+		tooManyLabels = append(tooManyLabels, fmt.Sprintf("label%v", i))
+	}
+	if _, err := setTreeLabels(ctx, s, tree.TreeId, tooManyLabels); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("SetTreeLabels(tooManyLabels) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+	if err := assertStoredTree(ctx, s, tree); err != nil {
+		t.Errorf("tree modified by failed SetTreeLabels(): %v", err)
+	}
+}
+
+// TestReservedLabels tests that labels with storage.ReservedLabelPrefix are
+// only accepted from contexts derived from storage.WithReservedLabels.
+func (tester *AdminStorageTester) TestReservedLabels(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	reservedLabel := storage.ReservedLabelPrefix + "primary"
+
+	reservedTree := proto.Clone(LogTree).(*trillian.Tree)
+	reservedTree.Labels = []string{"llamas", reservedLabel}
+	if _, err := createTree(ctx, s, reservedTree); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("CreateTree(reservedLabel) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+
+	normalTree := proto.Clone(LogTree).(*trillian.Tree)
+	normalTree.Labels = []string{"llamas"}
+	tree, err := createTree(ctx, s, normalTree)
+	if err != nil {
+		t.Fatalf("CreateTree(normalLabel) = (_, %v), want = (_, nil)", err)
+	}
+	if _, err := setTreeLabels(ctx, s, tree.TreeId, []string{"llamas", reservedLabel}); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("SetTreeLabels(reservedLabel) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+	if err := assertStoredTree(ctx, s, tree); err != nil {
+		t.Errorf("tree modified by failed SetTreeLabels(): %v", err)
+	}
+
+	// Internal code may set reserved labels, and they survive client updates.
+	internalCtx := storage.WithReservedLabels(ctx)
+	tree, err = setTreeLabels(internalCtx, s, tree.TreeId, []string{"llamas", reservedLabel})
+	if err != nil {
+		t.Fatalf("SetTreeLabels(internalCtx, reservedLabel) = (_, %v), want = (_, nil)", err)
+	}
+	tree, _, err = updateTree(ctx, s, tree.TreeId, func(tree *trillian.Tree) {
+		tree.Description = "Changed"
+	})
+	if err != nil {
+		t.Fatalf("UpdateTree() on tree with reserved label = (_, _, %v), want = (_, _, nil)", err)
+	}
+	if want := []string{"llamas", reservedLabel}; !reflect.DeepEqual(tree.Labels, want) {
+		t.Errorf("post-UpdateTree() labels = %v, want = %v", tree.Labels, want)
+	}
+	if _, err := createTree(internalCtx, s, reservedTree); err != nil {
+		t.Errorf("CreateTree(internalCtx, reservedLabel) = (_, %v), want = (_, nil)", err)
+	}
+}
+
+// TestTransferLabels tests moving labels between trees.
+func (tester *AdminStorageTester) TestTransferLabels(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	fromTree := proto.Clone(LogTree).(*trillian.Tree)
+	fromTree.Labels = []string{"keep", "shared", "tenant-a"}
+	from := makeTreeOrFail(ctx, s, spec{Tree: fromTree}, t.Fatalf)
+	toTree := proto.Clone(LogTree).(*trillian.Tree)
+	toTree.Labels = []string{"other", "shared"}
+	to := makeTreeOrFail(ctx, s, spec{Tree: toTree}, t.Fatalf)
+
+	if err := transferLabels(ctx, s, from.TreeId, to.TreeId, []string{"tenant-a", "shared"}); err != nil {
+		t.Fatalf("TransferLabels() = %v, want = nil", err)
+	}
+	for _, test := range []struct {
+		treeID     int64
+		wantLabels []string
+	}{
+		{treeID: from.TreeId, wantLabels: []string{"keep"}},
+		{treeID: to.TreeId, wantLabels: []string{"other", "shared", "tenant-a"}},
+	} {
+		tree, err := getTree(ctx, s, test.treeID)
+		if err != nil {
+			t.Fatalf("GetTree() = (_, %v), want = (_, nil)", err)
+		}
+		if diff := pretty.Compare(tree.Labels, test.wantLabels); diff != "" {
+			t.Errorf("post-TransferLabels() tree %v labels diff (-got +want):\n%v", test.treeID, diff)
+		}
+	}
+
+	from, err := getTree(ctx, s, from.TreeId)
+	if err != nil {
+		t.Fatalf("GetTree() = (_, %v), want = (_, nil)", err)
+	}
+	to, err = getTree(ctx, s, to.TreeId)
+	if err != nil {
+		t.Fatalf("GetTree() = (_, %v), want = (_, nil)", err)
+	}
+	tests := []struct {
+		desc         string
+		fromID, toID int64
+		labels       []string
+		wantCode     errors.Code
+	}{
+		{desc: "unknownTarget", fromID: from.TreeId, toID: 12345, labels: []string{"keep"}, wantCode: errors.NotFound},
+		{desc: "unknownSource", fromID: 12345, toID: to.TreeId, labels: []string{"keep"}, wantCode: errors.NotFound},
+		{desc: "missingLabel", fromID: from.TreeId, toID: to.TreeId, labels: []string{"keep", "tenant-a"}, wantCode: errors.InvalidArgument},
+		{desc: "sameTree", fromID: from.TreeId, toID: from.TreeId, labels: []string{"keep"}, wantCode: errors.InvalidArgument},
+	}
+	for _, test := range tests {
+		if err := transferLabels(ctx, s, test.fromID, test.toID, test.labels); errors.ErrorCode(err) != test.wantCode {
+			t.Errorf("%v: TransferLabels() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+		}
+	}
+	for _, tree := range []*trillian.Tree{from, to} {
+		if err := assertStoredTree(ctx, s, tree); err != nil {
+			t.Errorf("tree modified by failed TransferLabels(): %v", err)
+		}
+	}
+}
+
+// TestCanonicalTreeOrder tests that repeated tree fields are stored in their
+// canonical order, regardless of input order.
+func (tester *AdminStorageTester) TestCanonicalTreeOrder(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	wantLabels := []string{"alpacas", "guanacos", "llamas", "vicunas"}
+	tree1 := proto.Clone(LogTree).(*trillian.Tree)
+	tree1.Labels = []string{"llamas", "alpacas", "vicunas", "guanacos"}
+	tree2 := proto.Clone(LogTree).(*trillian.Tree)
+	tree2.Labels = []string{"vicunas", "guanacos", "alpacas", "llamas"}
+
+	var hashes [][]byte
+	for _, tree := range []*trillian.Tree{tree1, tree2} {
+		inputLabels := append([]string(nil), tree.Labels...)
+		created := makeTreeOrFail(ctx, s, spec{Tree: tree}, t.Fatalf)
+		if diff := pretty.Compare(tree.Labels, inputLabels); diff != "" {
+			t.Errorf("CreateTree() modified input labels, diff (-got +want):\n%v", diff)
+		}
+		stored, err := getTree(ctx, s, created.TreeId)
+		if err != nil {
+			t.Fatalf("GetTree() = (_, %v), want = (_, nil)", err)
+		}
+		if diff := pretty.Compare(stored.Labels, wantLabels); diff != "" {
+			t.Errorf("post-CreateTree() labels diff (-got +want):\n%v", diff)
+		}
+		hash, err := storage.TreeConfigHash(stored)
+		if err != nil {
+			t.Fatalf("TreeConfigHash() = (_, %v), want = (_, nil)", err)
+		}
+		hashes = append(hashes, hash)
+
+		updated, _, err := updateTree(ctx, s, created.TreeId, func(tree *trillian.Tree) {
+			tree.Labels = []string{"vicunas", "llamas"}
+		})
+		if err != nil {
+			t.Fatalf("UpdateTree() = (_, %v), want = (_, nil)", err)
+		}
+		if diff := pretty.Compare(updated.Labels, []string{"llamas", "vicunas"}); diff != "" {
+			t.Errorf("post-UpdateTree() labels diff (-got +want):\n%v", diff)
+		}
+		if err := assertStoredTree(ctx, s, updated); err != nil {
+			t.Errorf("UpdateTree() not persisted: %v", err)
+		}
+	}
+	if !bytes.Equal(hashes[0], hashes[1]) {
+		t.Errorf("TreeConfigHash() differs for trees differing only in label order: %x != %x", hashes[0], hashes[1])
+	}
+
+	// Order aside, labels are part of the config.
+	tree3 := proto.Clone(LogTree).(*trillian.Tree)
+	tree3.Labels = []string{"alpacas", "guanacos", "llamas"}
+	created := makeTreeOrFail(ctx, s, spec{Tree: tree3}, t.Fatalf)
+	hash, err := storage.TreeConfigHash(created)
+	if err != nil {
+		t.Fatalf("TreeConfigHash() = (_, %v), want = (_, nil)", err)
+	}
+	if bytes.Equal(hash, hashes[0]) {
+		t.Errorf("TreeConfigHash() = %x for trees with different labels, want different hashes", hash)
+	}
+
+	// Labels aren't deduplicated; duplicates are rejected instead.
+	dup := proto.Clone(LogTree).(*trillian.Tree)
+	dup.Labels = []string{"llamas", "alpacas", "llamas"}
+	if _, err := createTree(ctx, s, dup); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("CreateTree(duplicate labels) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+}
+
+// TestTreeAlias tests that tree aliases resolve to the tree they were last
+// pointed to.
+func (tester *AdminStorageTester) TestTreeAlias(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tree1 := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	tree2 := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+
+	const alias = "example.com/log"
+	if _, err := resolveTreeAlias(ctx, s, alias); errors.ErrorCode(err) != errors.NotFound {
+		t.Errorf("ResolveTreeAlias() before SetTreeAlias() returned err = %v, wantCode = %s", err, errors.NotFound)
+	}
+
+	for _, tree := range []*trillian.Tree{tree1, tree2, tree1} {
+		if err := setTreeAlias(ctx, s, alias, tree.TreeId); err != nil {
+			t.Fatalf("SetTreeAlias(%q, %v) = %v, want = nil", alias, tree.TreeId, err)
+		}
+		id, err := resolveTreeAlias(ctx, s, alias)
+		if err != nil {
+			t.Fatalf("ResolveTreeAlias(%q) = (_, %v), want = (_, nil)", alias, err)
+		}
+		if id != tree.TreeId {
+			t.Errorf("ResolveTreeAlias(%q) = (%v, _), want = (%v, _)", alias, id, tree.TreeId)
+		}
+	}
+
+	tests := []struct {
+		desc     string
+		alias    string
+		treeID   int64
+		wantCode errors.Code
+	}{
+		{desc: "emptyAlias", alias: "", treeID: tree1.TreeId, wantCode: errors.InvalidArgument},
+		{desc: "unknownTree", alias: alias, treeID: 12345, wantCode: errors.NotFound},
+	}
+	for _, test := range tests {
+		if err := setTreeAlias(ctx, s, test.alias, test.treeID); errors.ErrorCode(err) != test.wantCode {
+			t.Errorf("%v: SetTreeAlias() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+		}
+	}
+	// Failed calls don't repoint the alias
+	if id, err := resolveTreeAlias(ctx, s, alias); err != nil || id != tree1.TreeId {
+		t.Errorf("ResolveTreeAlias(%q) = (%v, %v), want = (%v, nil)", alias, id, err, tree1.TreeId)
+	}
+}
+
+// TestCreateTreeWithAlias tests that CreateTreeWithAlias creates the tree
+// and its alias together, and creates nothing if the alias is taken.
+func (tester *AdminStorageTester) TestCreateTreeWithAlias(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	const alias = "example.com/log"
+	tree, err := createTreeWithAlias(ctx, s, LogTree, alias)
+	if err != nil {
+		t.Fatalf("CreateTreeWithAlias() = (_, %v), want = (_, nil)", err)
+	}
+	if err := assertStoredTree(ctx, s, tree); err != nil {
+		t.Errorf("CreateTreeWithAlias() tree not persisted: %v", err)
+	}
+	if id, err := resolveTreeAlias(ctx, s, alias); err != nil || id != tree.TreeId {
+		t.Errorf("ResolveTreeAlias(%q) = (%v, %v), want = (%v, nil)", alias, id, err, tree.TreeId)
+	}
+
+	tests := []struct {
+		desc     string
+		alias    string
+		wantCode errors.Code
+	}{
+		{desc: "takenAlias", alias: alias, wantCode: errors.AlreadyExists},
+		{desc: "emptyAlias", alias: "", wantCode: errors.InvalidArgument},
+	}
+	for _, test := range tests {
+		if _, err := createTreeWithAlias(ctx, s, MapTree, test.alias); errors.ErrorCode(err) != test.wantCode {
+			t.Errorf("%v: CreateTreeWithAlias() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+		}
+	}
+
+	// Failed calls neither create trees nor repoint the alias.
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, true /* includeDeleted */)
+	if err != nil {
+		t.Fatalf("ListTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+	if len(trees) != 1 || trees[0].TreeId != tree.TreeId {
+		var ids []int64
+		for _, tree := range trees {
+			ids = append(ids, tree.TreeId)
+		}
+		t.Errorf("ListTrees() returned tree IDs %v, want = [%v]", ids, tree.TreeId)
+	}
+	if id, err := resolveTreeAlias(ctx, s, alias); err != nil || id != tree.TreeId {
+		t.Errorf("ResolveTreeAlias(%q) = (%v, %v), want = (%v, nil)", alias, id, err, tree.TreeId)
+	}
+}
+
+// TestConcurrentCreateTreeWithAlias tests that only one of two transactions
+// creating trees with the same alias succeeds, and that the alias isn't
+// repointed by the other.
+func (tester *AdminStorageTester) TestConcurrentCreateTreeWithAlias(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	tree := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+
+	const alias = "example.com/log"
+	var created [2]*trillian.Tree
+	errs := raceWrites(ctx, s, tree.TreeId, func(ctx context.Context, tx storage.AdminTX, i int) error {
+		var err error
+		created[i], err = tx.CreateTreeWithAlias(ctx, LogTree, alias)
+		return err
+	})
+	if errs[0] == nil && errs[1] == nil {
+		t.Fatalf("CreateTreeWithAlias() succeeded in both transactions, want one to fail")
+	}
+	winner := -1
+	for i, err := range errs {
+		if err == nil {
+			winner = i
+		}
+	}
+	if winner == -1 {
+		t.Fatalf("CreateTreeWithAlias() failed in both transactions: %v", errs)
+	}
+	if id, err := resolveTreeAlias(ctx, s, alias); err != nil || id != created[winner].TreeId {
+		t.Errorf("ResolveTreeAlias(%q) = (%v, %v), want = (%v, nil)", alias, id, err, created[winner].TreeId)
+	}
+}
+
+// TestValidatePlan tests that ValidatePlan reports every problem in a plan,
+// without modifying storage.
+func (tester *AdminStorageTester) TestValidatePlan(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	logTree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	mapTree := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+
+	plan := storage.ProvisioningPlan{
+		Creates: []*trillian.Tree{LogTree},
+		Updates: map[int64]func(*trillian.Tree){
+			logTree.TreeId: func(tree *trillian.Tree) {
+				tree.TreeState = trillian.TreeState_UNKNOWN_TREE_STATE
+			},
+		},
+		Deletes: []int64{mapTree.TreeId},
+	}
+	err := storage.ValidatePlan(ctx, s, storage.AdminStorageOptions{}, plan)
+	multiErr, ok := err.(errors.MultiError)
+	if !ok {
+		t.Fatalf("ValidatePlan() = %v, want = errors.MultiError", err)
+	}
+	if len(multiErr) != 2 {
+		t.Fatalf("ValidatePlan() = %v, want 2 errors", err)
+	}
+	wantErrs := []struct {
+		treeID int64
+		code   errors.Code
+	}{
+		{treeID: logTree.TreeId, code: errors.InvalidArgument},
+		{treeID: mapTree.TreeId, code: errors.FailedPrecondition},
+	}
+	for i, want := range wantErrs {
+		if got := multiErr[i]; errors.ErrorCode(got) != want.code || !strings.Contains(got.Error(), fmt.Sprint(want.treeID)) {
+			t.Errorf("ValidatePlan() error %v = %v, want an error naming tree %v with code %s", i, got, want.treeID, want.code)
+		}
+	}
+
+	for _, tree := range []*trillian.Tree{logTree, mapTree} {
+		if err := assertStoredTree(ctx, s, tree); err != nil {
+			t.Errorf("tree %v modified by ValidatePlan(): %v", tree.TreeId, err)
+		}
+	}
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, true /* includeDeleted */)
+	if err != nil {
+		t.Fatalf("ListTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Commit() = %v, want = nil", err)
+	}
+	if got, want := len(trees), 2; got != want {
+		t.Errorf("ValidatePlan() resulted in %v trees, want = %v", got, want)
+	}
+
+	// Fixing the problems results in a valid plan.
+	plan.Updates[logTree.TreeId] = func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_FROZEN
+	}
+	plan.Deletes = nil
+	if err := storage.ValidatePlan(ctx, s, storage.AdminStorageOptions{}, plan); err != nil {
+		t.Errorf("ValidatePlan(fixed) = %v, want = nil", err)
+	}
+}
+
+// TestImportTreeTimestamps tests that imported trees must not have an
+// UpdateTime earlier than their CreateTime.
+func (tester *AdminStorageTester) TestImportTreeTimestamps(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tests := []struct {
+		desc                   string
+		createTime, updateTime time.Time
+		wantErr                bool
+	}{
+		{desc: "ordered", createTime: time.Unix(1000, 0), updateTime: time.Unix(2000, 0)},
+		{desc: "equal", createTime: time.Unix(1000, 0), updateTime: time.Unix(1000, 0)},
+		{desc: "inverted", createTime: time.Unix(2000, 0), updateTime: time.Unix(1000, 0), wantErr: true},
+	}
+	for _, test := range tests {
+		treeID, err := storage.NewTreeID()
+		if err != nil {
+			t.Fatalf("%v: NewTreeID() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.TreeId = treeID
+		tree.CreateTime, _ = ptypes.TimestampProto(test.createTime)
+		tree.UpdateTime, _ = ptypes.TimestampProto(test.updateTime)
+		tree.Revision = 1
+
+		_, err = storage.ImportTrees(ctx, s, []*trillian.Tree{tree}, storage.ImportOptions{})
+		switch hasErr := err != nil; {
+		case hasErr != test.wantErr:
+			t.Errorf("%v: ImportTrees() = (_, %v), wantErr = %v", test.desc, err, test.wantErr)
+		case hasErr && errors.ErrorCode(err) != errors.InvalidArgument:
+			t.Errorf("%v: ImportTrees() returned err = %v, wantCode = %s", test.desc, err, errors.InvalidArgument)
+		case hasErr:
+			if _, err := getTree(ctx, s, treeID); errors.ErrorCode(err) != errors.NotFound {
+				t.Errorf("%v: getTree() after failed import returned err = %v, wantCode = %s", test.desc, err, errors.NotFound)
+			}
+		default:
+			if err := assertStoredTree(ctx, s, tree); err != nil {
+				t.Errorf("%v: ImportTrees() not persisted: %v", test.desc, err)
+			}
+		}
+	}
+}
+
+// TestImportTreesCanceled tests that ImportTrees aborts when its context is
+// cancelled partway through, without importing any trees.
+func (tester *AdminStorageTester) TestImportTreesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := tester.NewAdminStorage()
+
+	// Private keys are loaded once per imported tree, which makes for a
+	// convenient hook to cancel the import partway through.
+	const numTrees, cancelAfter = 20, 5
+	handlerKey := &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P256}
+	loaded := 0
+	keys.RegisterHandler(handlerKey, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		if loaded++; loaded == cancelAfter {
+			cancel()
+		}
+		return pem.UnmarshalPrivateKey(privateKeyPEM, privateKeyPass)
+	})
+	defer keys.UnregisterHandler(handlerKey)
+
+	var trees []*trillian.Tree
+	for i := 0; i < numTrees; i++ {
+		treeID, err := storage.NewTreeID()
+		if err != nil {
+			t.Fatalf("NewTreeID() = (_, %v), want = (_, nil)", err)
+		}
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.TreeId = treeID
+		tree.CreateTime, _ = ptypes.TimestampProto(time.Unix(1000, 0))
+		tree.UpdateTime, _ = ptypes.TimestampProto(time.Unix(2000, 0))
+		tree.PrivateKey = testonly.MustMarshalAny(t, handlerKey)
+		trees = append(trees, tree)
+	}
+
+	if _, err := storage.ImportTrees(ctx, s, trees, storage.ImportOptions{}); errors.ErrorCode(err) != errors.Canceled {
+		t.Errorf("ImportTrees() returned err = %v, wantCode = %s", err, errors.Canceled)
+	}
+	if loaded >= numTrees {
+		t.Errorf("ImportTrees() loaded %v keys, want < %v (import not aborted)", loaded, numTrees)
+	}
+
+	tx, err := s.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	ids, err := tx.ListTreeIDs(context.Background(), true /* includeDeleted */)
+	if err != nil {
+		t.Fatalf("ListTreeIDs() = (_, %v), want = (_, nil)", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("ListTreeIDs() = %v, want no trees after cancelled ImportTrees()", ids)
+	}
+}
+
+// TestImportTrees tests that ImportTrees resolves ID collisions according to
+// the chosen policy.
+func (tester *AdminStorageTester) TestImportTrees(t *testing.T) {
+	ctx := context.Background()
+
+	// Imported trees keep their timestamps, so use millisecond precision to
+	// avoid truncation by storage.
+	importedTree := func(treeID int64) *trillian.Tree {
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.TreeId = treeID
+		tree.CreateTime, _ = ptypes.TimestampProto(time.Unix(1000, 0))
+		tree.UpdateTime, _ = ptypes.TimestampProto(time.Unix(2000, 0))
+		// Imported trees keep their revision too.
+		tree.Revision = 3
+		return tree
+	}
+
+	tests := []struct {
+		desc           string
+		policy         storage.CollisionPolicy
+		wantErr        bool
+		wantImported   int
+		wantSkipped    bool
+		wantRenumbered bool
+	}{
+		{desc: "fail", policy: storage.OnCollisionFail, wantErr: true},
+		{desc: "skip", policy: storage.OnCollisionSkip, wantImported: 1, wantSkipped: true},
+		{desc: "renumber", policy: storage.OnCollisionRenumber, wantImported: 2, wantRenumbered: true},
+	}
+	for _, test := range tests {
+		s := tester.NewAdminStorage()
+		existing := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+
+		freeID, err := storage.NewTreeID()
+		if err != nil {
+			t.Fatalf("%v: NewTreeID() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		colliding := importedTree(existing.TreeId)
+		free := importedTree(freeID)
+
+		result, err := storage.ImportTrees(ctx, s, []*trillian.Tree{colliding, free}, storage.ImportOptions{OnCollision: test.policy})
+		if hasErr := err != nil; hasErr != test.wantErr {
+			t.Errorf("%v: ImportTrees() = (_, %v), wantErr = %v", test.desc, err, test.wantErr)
+			continue
+		}
+
+		// The pre-existing tree is never modified.
+		if err := assertStoredTree(ctx, s, existing); err != nil {
+			t.Errorf("%v: existing tree modified by ImportTrees(): %v", test.desc, err)
+		}
+
+		if test.wantErr {
+			if errors.ErrorCode(err) != errors.AlreadyExists {
+				t.Errorf("%v: ImportTrees() returned err = %v, wantCode = %s", test.desc, err, errors.AlreadyExists)
+			}
+			// Failed imports are all-or-nothing.
+			if _, err := getTree(ctx, s, freeID); errors.ErrorCode(err) != errors.NotFound {
+				t.Errorf("%v: getTree(%v) returned err = %v, wantCode = %s", test.desc, freeID, err, errors.NotFound)
+			}
+			continue
+		}
+
+		if got := len(result.Imported); got != test.wantImported {
+			t.Errorf("%v: ImportTrees() imported %v trees, want = %v", test.desc, got, test.wantImported)
+		}
+		if err := assertStoredTree(ctx, s, free); err != nil {
+			t.Errorf("%v: %v", test.desc, err)
+		}
+
+		var wantSkipped []int64
+		if test.wantSkipped {
+			wantSkipped = []int64{existing.TreeId}
+		}
+		if diff := pretty.Compare(result.Skipped, wantSkipped); diff != "" {
+			t.Errorf("%v: ImportTrees() Skipped diff (-got +want):\n%v", test.desc, diff)
+		}
+
+		newID, ok := result.Renumbered[existing.TreeId]
+		if ok != test.wantRenumbered {
+			t.Errorf("%v: ImportTrees() Renumbered = %v, want renumbered = %v", test.desc, result.Renumbered, test.wantRenumbered)
+		}
+		if ok {
+			if newID == existing.TreeId {
+				t.Errorf("%v: ImportTrees() renumbered tree %v to the same ID", test.desc, newID)
+			}
+			want := proto.Clone(colliding).(*trillian.Tree)
+			want.TreeId = newID
+			if err := assertStoredTree(ctx, s, want); err != nil {
+				t.Errorf("%v: renumbered tree: %v", test.desc, err)
+			}
+		}
+	}
+}
+
+// TestAuditStoredTrees tests that AuditStoredTrees reports trees that no
+// longer pass validation, and only those.
+func (tester *AdminStorageTester) TestAuditStoredTrees(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+	// Create a tree with a small RSA key, then audit it against stricter
+	// rules, i.e., the default minimum key size.
+	s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{MinRsaKeySizeInBits: 1024})
+
+	activeLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	frozenLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Frozen: true}, t.Fatalf)
+	deletedMap := makeTreeOrFail(ctx, s, spec{Tree: MapTree, Deleted: true}, t.Fatalf)
+	rsaLog := makeTreeOrFail(ctx, s, spec{Tree: rsaLogTree(t, 1024)}, t.Fatalf)
+
+	failed, err := storage.AuditStoredTrees(ctx, s, storage.AdminStorageOptions{})
+	if err != nil {
+		t.Fatalf("AuditStoredTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if err, ok := failed[rsaLog.TreeId]; !ok || errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("AuditStoredTrees()[%v] = %v, wantCode = %s", rsaLog.TreeId, err, errors.InvalidArgument)
+	}
+	for _, tree := range []*trillian.Tree{activeLog, frozenLog, deletedMap} {
+		if err, ok := failed[tree.TreeId]; ok {
+			t.Errorf("AuditStoredTrees() reported conforming tree %v: %v", tree.TreeId, err)
+		}
+	}
+	if got, want := len(failed), 1; got != want {
+		t.Errorf("AuditStoredTrees() reported %v trees, want = %v", got, want)
+	}
+}
+
+// TestExportPublicKeyBundle tests that ExportPublicKeyBundle returns the
+// public keys of all requested trees.
+func (tester *AdminStorageTester) TestExportPublicKeyBundle(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	activeLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	activeMap := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	deletedLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+
+	tests := []struct {
+		desc           string
+		includeDeleted bool
+		wantTrees      []*trillian.Tree
+	}{
+		{desc: "nonDeleted", wantTrees: []*trillian.Tree{activeLog, activeMap}},
+		{desc: "includeDeleted", includeDeleted: true, wantTrees: []*trillian.Tree{activeLog, activeMap, deletedLog}},
+	}
+	for _, test := range tests {
+		bundle, err := storage.ExportPublicKeyBundle(ctx, s, test.includeDeleted)
+		if err != nil {
+			t.Errorf("%v: ExportPublicKeyBundle() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+
+		want := make([]storage.TreeKeyEntry, 0, len(test.wantTrees))
+		for _, tree := range test.wantTrees {
+			want = append(want, storage.TreeKeyEntry{
+				TreeID:      tree.TreeId,
+				DisplayName: tree.DisplayName,
+				PublicKey:   tree.PublicKey,
+			})
+		}
+		sort.Slice(want, func(i, j int) bool { return want[i].TreeID < want[j].TreeID })
+		if diff := pretty.Compare(bundle, want); diff != "" {
+			t.Errorf("%v: ExportPublicKeyBundle() diff (-got +want):\n%v", test.desc, diff)
+		}
+
+		// No private key material may leak into the bundle.
+		for _, tree := range test.wantTrees {
+			privateKey := tree.PrivateKey.GetValue()
+			for _, entry := range bundle {
+				if bytes.Contains(entry.PublicKey.GetDer(), privateKey) {
+					t.Errorf("%v: ExportPublicKeyBundle() entry for tree %v contains private key of tree %v", test.desc, entry.TreeID, tree.TreeId)
+				}
+			}
+		}
+	}
+}
+
+// TestAppDataLimits tests that trees with too big app_data are rejected on
+// creation and update.
+func (tester *AdminStorageTester) TestAppDataLimits(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	longKey := strings.Repeat("k", storage.DefaultMaxAppDataKeyLength+1)
+	bigAppData := make(map[string]string)
+	for i := 0; i <= storage.DefaultMaxAppDataSize/storage.DefaultMaxAppDataValueLength; i++ {
+		bigAppData[fmt.Sprintf("key%v", i)] = strings.Repeat("v", storage.DefaultMaxAppDataValueLength)
+	}
+	tests := []struct {
+		desc    string
+		appData map[string]string
+		// wantKey is the key expected to be named in the error, if any.
+		wantKey string
+	}{
+		{desc: "longKey", appData: map[string]string{longKey: "llamas"}, wantKey: longKey},
+		{desc: "longValue", appData: map[string]string{"owner": strings.Repeat("v", storage.DefaultMaxAppDataValueLength+1)}, wantKey: "owner"},
+		{desc: "tooBig", appData: bigAppData},
+	}
+
+	stored := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	for _, test := range tests {
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.AppData = test.appData
+		_, err := createTree(ctx, s, tree)
+		if errors.ErrorCode(err) != errors.InvalidArgument {
+			t.Errorf("%v: CreateTree() returned err = %v, wantCode = %s", test.desc, err, errors.InvalidArgument)
+		} else if !strings.Contains(err.Error(), test.wantKey) {
+			t.Errorf("%v: CreateTree() returned err = %v, want key %q named", test.desc, err, test.wantKey)
+		}
+
+		if _, _, err := updateTree(ctx, s, stored.TreeId, func(tree *trillian.Tree) {
+			tree.AppData = test.appData
+		}); errors.ErrorCode(err) != errors.InvalidArgument {
+			t.Errorf("%v: UpdateTree() returned err = %v, wantCode = %s", test.desc, err, errors.InvalidArgument)
+		}
+		if err := assertStoredTree(ctx, s, stored); err != nil {
+			t.Errorf("%v: tree modified by failed UpdateTree(): %v", test.desc, err)
+		}
+	}
+}
+
+// TestAppDataLimitOptions tests that the app_data limits are configurable via
+// AdminStorageOptions, and that keys can't exceed MaxAppDataKeyLengthLimit.
+func (tester *AdminStorageTester) TestAppDataLimitOptions(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		desc    string
+		opts    storage.AdminStorageOptions
+		appData map[string]string
+		wantErr bool
+	}{
+		{
+			desc:    "shortKeyLimit",
+			opts:    storage.AdminStorageOptions{MaxAppDataKeyLength: 4},
+			appData: map[string]string{"owner": "llamas"},
+			wantErr: true,
+		},
+		{
+			desc:    "shortValueLimit",
+			opts:    storage.AdminStorageOptions{MaxAppDataValueLength: 4},
+			appData: map[string]string{"owner": "llamas"},
+			wantErr: true,
+		},
+		{
+			desc:    "smallSizeLimit",
+			opts:    storage.AdminStorageOptions{MaxAppDataSize: 8},
+			appData: map[string]string{"owner": "llamas"},
+			wantErr: true,
+		},
+		{
+			desc:    "raisedValueLimit",
+			opts:    storage.AdminStorageOptions{MaxAppDataValueLength: 2 * storage.DefaultMaxAppDataValueLength},
+			appData: map[string]string{"owner": strings.Repeat("v", storage.DefaultMaxAppDataValueLength+1)},
+		},
+		{
+			desc:    "keyAtLimit",
+			opts:    storage.AdminStorageOptions{MaxAppDataKeyLength: 2 * storage.MaxAppDataKeyLengthLimit},
+			appData: map[string]string{strings.Repeat("k", storage.MaxAppDataKeyLengthLimit): "llamas"},
+		},
+		{
+			desc:    "keyPastLimit",
+			opts:    storage.AdminStorageOptions{MaxAppDataKeyLength: 2 * storage.MaxAppDataKeyLengthLimit},
+			appData: map[string]string{strings.Repeat("k", storage.MaxAppDataKeyLengthLimit+1): "llamas"},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		s := tester.NewAdminStorageWithOptions(test.opts)
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.AppData = test.appData
+		created, err := createTree(ctx, s, tree)
+		switch {
+		case test.wantErr && errors.ErrorCode(err) != errors.InvalidArgument:
+			t.Errorf("%v: CreateTree() returned err = %v, wantCode = %s", test.desc, err, errors.InvalidArgument)
+		case !test.wantErr && err != nil:
+			t.Errorf("%v: CreateTree() returned err = %v, want = nil", test.desc, err)
+		case !test.wantErr:
+			if err := assertStoredTree(ctx, s, created); err != nil {
+				t.Errorf("%v: CreateTree() tree not persisted: %v", test.desc, err)
+			}
+		}
+	}
+}
+
+// TestReadDecorator tests that registered ReadDecorators are applied to all
+// reads, and that their changes aren't persisted.
+func (tester *AdminStorageTester) TestReadDecorator(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	appDataLog := proto.Clone(LogTree).(*trillian.Tree)
+	appDataLog.AppData = map[string]string{"owner": "llamas"}
+	tree := makeTreeOrFail(ctx, s, spec{Tree: appDataLog}, t.Fatalf)
+
+	const decoratorName = "TestReadDecorator"
+	storage.RegisterReadDecorator(decoratorName, func(ctx context.Context, tree *trillian.Tree) {
+		if tree.AppData == nil {
+			tree.AppData = make(map[string]string)
+		}
+		tree.AppData["status"] = "derived-" + tree.TreeState.String()
+	})
+	defer storage.UnregisterReadDecorator(decoratorName)
+	wantAppData := map[string]string{"owner": "llamas", "status": "derived-ACTIVE"}
+
+	got, err := getTree(ctx, s, tree.TreeId)
+	if err != nil {
+		t.Fatalf("getTree() returned err = %v", err)
+	}
+	if diff := pretty.Compare(got.AppData, wantAppData); diff != "" {
+		t.Errorf("post-GetTree() AppData diff (-got +want):\n%v", diff)
+	}
+
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, false /* includeDeleted */)
+	if err != nil {
+		t.Fatalf("ListTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+	if len(trees) != 1 {
+		t.Fatalf("ListTrees() returned %v trees, want = 1", len(trees))
+	}
+	if diff := pretty.Compare(trees[0].AppData, wantAppData); diff != "" {
+		t.Errorf("post-ListTrees() AppData diff (-got +want):\n%v", diff)
+	}
+
+	// Updates must not persist decorated data either.
+	if _, _, err := updateTree(ctx, s, tree.TreeId, func(tree *trillian.Tree) {
+		tree.DisplayName = "Renamed Log"
+	}); err != nil {
+		t.Fatalf("updateTree() returned err = %v", err)
+	}
+
+	storage.UnregisterReadDecorator(decoratorName)
+	got, err = getTree(ctx, s, tree.TreeId)
+	if err != nil {
+		t.Fatalf("getTree() returned err = %v", err)
+	}
+	if diff := pretty.Compare(got.AppData, appDataLog.AppData); diff != "" {
+		t.Errorf("decorated AppData persisted, diff (-got +want):\n%v", diff)
+	}
+}
+
+// TestDistinctHashStrategies tests counting trees per hash strategy.
+func (tester *AdminStorageTester) TestDistinctHashStrategies(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: MapTree, Deleted: true}, t.Fatalf)
+
+	tests := []struct {
+		desc           string
+		includeDeleted bool
+		want           map[trillian.HashStrategy]int64
+	}{
+		{
+			desc: "excludeDeleted",
+			want: map[trillian.HashStrategy]int64{
+				LogTree.HashStrategy: 2,
+				MapTree.HashStrategy: 1,
+			},
+		},
+		{
+			desc:           "includeDeleted",
+			includeDeleted: true,
+			want: map[trillian.HashStrategy]int64{
+				LogTree.HashStrategy: 2,
+				MapTree.HashStrategy: 2,
+			},
+		},
+	}
+	for _, test := range tests {
+		got, err := storage.DistinctHashStrategies(ctx, s, test.includeDeleted)
+		if err != nil {
+			t.Errorf("%v: DistinctHashStrategies() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: DistinctHashStrategies() = %v, want = %v", test.desc, got, test.want)
+		}
+	}
+}
+
+// TestTreeCountSnapshots tests that recorded tree count snapshots are returned
+// by ListTreeCountSnapshots, ordered by time, and that a snapshot taken in the
+// same millisecond as a previous one replaces it.
+func (tester *AdminStorageTester) TestTreeCountSnapshots(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	fakeTime := util.NewFakeTimeSource(time.Unix(1500000000, 0))
+	opts := storage.AdminStorageOptions{TimeSource: fakeTime}
+
+	var want []storage.CountSnapshot
+	record := func() {
+		if err := storage.RecordTreeCountSnapshot(ctx, s, opts); err != nil {
+			t.Fatalf("RecordTreeCountSnapshot() = %v, want = nil", err)
+		}
+	}
+
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	record()
+	want = append(want, storage.CountSnapshot{Time: fakeTime.Now(), Active: 1})
+
+	fakeTime.Set(fakeTime.Now().Add(time.Hour))
+	makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+	record()
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	record()
+	want = append(want, storage.CountSnapshot{Time: fakeTime.Now(), Active: 3, Deleted: 1})
+
+	tests := []struct {
+		desc     string
+		from, to time.Time
+		want     []storage.CountSnapshot
+	}{
+		{desc: "all", from: want[0].Time, to: want[1].Time.Add(time.Second), want: want},
+		{desc: "first", from: want[0].Time, to: want[1].Time, want: want[:1]},
+		{desc: "second", from: want[0].Time.Add(time.Second), to: want[1].Time.Add(time.Second), want: want[1:]},
+		{desc: "none", from: want[1].Time.Add(time.Second), to: want[1].Time.Add(time.Hour)},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		got, err := tx.ListTreeCountSnapshots(ctx, test.from, test.to)
+		if err != nil {
+			t.Errorf("%v: ListTreeCountSnapshots() = (_, %v), want = (_, nil)", test.desc, err)
+			tx.Close()
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+
+		if len(got) != len(test.want) {
+			t.Errorf("%v: ListTreeCountSnapshots() returned %v snapshots, want %v", test.desc, len(got), len(test.want))
+			continue
+		}
+		for i := range got {
+			if !got[i].Time.Equal(test.want[i].Time) || got[i].Active != test.want[i].Active || got[i].Deleted != test.want[i].Deleted {
+				t.Errorf("%v: ListTreeCountSnapshots()[%v] = %+v, want %+v", test.desc, i, got[i], test.want[i])
+			}
+		}
+	}
+}
+
+// TestLabelDeriver tests that CreateTree adds and validates the labels of
+// registered LabelDerivers.
+func (tester *AdminStorageTester) TestLabelDeriver(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	const deriverName = "TestLabelDeriver"
+	storage.RegisterLabelDeriver(deriverName, func(tree *trillian.Tree) []string {
+		return strings.Split(tree.DisplayName, "-")
+	})
+	defer storage.UnregisterLabelDeriver(deriverName)
+
+	tests := []struct {
+		desc        string
+		displayName string
+		labels      []string
+		// wantLabels are sorted, as storage may reorder labels.
+		wantLabels []string
+		wantErr    bool
+	}{
+		{
+			desc:        "derived",
+			displayName: "prod-us-east-logs",
+			wantLabels:  []string{"east", "logs", "prod", "us"},
+		},
+		{
+			desc:        "merged",
+			displayName: "prod-logs",
+			labels:      []string{"logs", "team"},
+			wantLabels:  []string{"logs", "prod", "team"},
+		},
+		{
+			desc:        "invalidDerivedLabel",
+			displayName: "prod--logs",
+			wantErr:     true,
+		},
+	}
+	for _, test := range tests {
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.DisplayName = test.displayName
+		tree.Labels = test.labels
+
+		created, err := createTree(ctx, s, tree)
+		if hasErr := err != nil; hasErr != test.wantErr {
+			t.Errorf("%v: createTree() = (_, %v), wantErr = %v", test.desc, err, test.wantErr)
+			continue
+		} else if hasErr {
+			continue
+		}
+		if got := sortedLabels(created.Labels); !reflect.DeepEqual(got, test.wantLabels) {
+			t.Errorf("%v: createTree() returned Labels = %v, want = %v", test.desc, got, test.wantLabels)
+		}
+		if !reflect.DeepEqual(tree.Labels, test.labels) {
+			t.Errorf("%v: createTree() modified input Labels: got %v, want = %v", test.desc, tree.Labels, test.labels)
+		}
+		stored, err := getTree(ctx, s, created.TreeId)
+		if err != nil {
+			t.Fatalf("%v: getTree() returned err = %v", test.desc, err)
+		}
+		if got := sortedLabels(stored.Labels); !reflect.DeepEqual(got, test.wantLabels) {
+			t.Errorf("%v: stored Labels = %v, want = %v", test.desc, got, test.wantLabels)
+		}
+	}
+}
+
+// sortedLabels returns a sorted copy of labels.
+func sortedLabels(labels []string) []string {
+	sorted := append([]string(nil), labels...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// TestGetTreesByDisplayNames tests that trees are looked up by display name,
+// and that ambiguous names are rejected.
+func (tester *AdminStorageTester) TestGetTreesByDisplayNames(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	// Display names are unique to this test, as storages may share a
+	// database.
+	prefix := fmt.Sprintf("%08x-", uint32(time.Now().UnixNano()))
+	unique, deleted, duplicate := prefix+"unique", prefix+"deleted", prefix+"duplicate"
+	named := func(name string) *trillian.Tree {
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.DisplayName = name
+		return tree
+	}
+	uniqueTree := makeTreeOrFail(ctx, s, spec{Tree: named(unique)}, t.Fatalf)
+	deletedTree := makeTreeOrFail(ctx, s, spec{Tree: named(deleted), Deleted: true}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: named(duplicate)}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: named(duplicate)}, t.Fatalf)
+
+	tests := []struct {
+		desc           string
+		names          []string
+		includeDeleted bool
+		wantIDs        map[string]int64
+		wantCode       errors.Code
+	}{
+		{
+			desc:    "unique",
+			names:   []string{unique, unique},
+			wantIDs: map[string]int64{unique: uniqueTree.TreeId},
+		},
+		{
+			desc:    "absent",
+			names:   []string{unique, prefix + "absent", deleted},
+			wantIDs: map[string]int64{unique: uniqueTree.TreeId},
+		},
+		{
+			desc:           "includeDeleted",
+			names:          []string{unique, deleted},
+			includeDeleted: true,
+			wantIDs:        map[string]int64{unique: uniqueTree.TreeId, deleted: deletedTree.TreeId},
+		},
+		{
+			desc:     "duplicate",
+			names:    []string{unique, duplicate},
+			wantCode: errors.FailedPrecondition,
+		},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		trees, err := tx.GetTreesByDisplayNames(ctx, test.names, test.includeDeleted)
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+		if errors.ErrorCode(err) != test.wantCode {
+			t.Errorf("%v: GetTreesByDisplayNames() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		gotIDs := make(map[string]int64)
+		for name, tree := range trees {
+			gotIDs[name] = tree.TreeId
+		}
+		if diff := pretty.Compare(gotIDs, test.wantIDs); diff != "" {
+			t.Errorf("%v: GetTreesByDisplayNames() diff (-got +want):\n%v", test.desc, diff)
+		}
+	}
+}
+
+// TestListRecentTrees tests that ListRecentTrees returns the newest trees
+// first, up to the requested limit.
+func (tester *AdminStorageTester) TestListRecentTrees(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	// Import trees, as CreateTree doesn't allow controlling timestamps.
+	base := time.Unix(1500000000, 0)
+	var trees []*trillian.Tree
+	for i := 0; i < 4; i++ {
+		id, err := storage.NewTreeID()
+		if err != nil {
+			t.Fatalf("NewTreeID() = (_, %v), want = (_, nil)", err)
+		}
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.TreeId = id
+		tree.CreateTime, _ = ptypes.TimestampProto(base.Add(time.Duration(i) * time.Hour))
+		tree.UpdateTime = tree.CreateTime
+		trees = append(trees, tree)
+	}
+	// The newest tree is soft-deleted.
+	deleted := trees[3]
+	deleted.Deleted = true
+	deleted.DeleteTime, _ = ptypes.TimestampProto(base.Add(10 * time.Hour))
+	if _, err := storage.ImportTrees(ctx, s, trees, storage.ImportOptions{}); err != nil {
+		t.Fatalf("ImportTrees() = (_, %v), want = (_, nil)", err)
+	}
+
+	tests := []struct {
+		desc           string
+		limit          int
+		includeDeleted bool
+		want           []*trillian.Tree
+		wantCode       errors.Code
+	}{
+		{desc: "newestTwo", limit: 2, want: []*trillian.Tree{trees[2], trees[1]}},
+		{desc: "withDeleted", limit: 2, includeDeleted: true, want: []*trillian.Tree{trees[3], trees[2]}},
+		{desc: "limitAboveCount", limit: 10, want: []*trillian.Tree{trees[2], trees[1], trees[0]}},
+		{desc: "zeroLimit", limit: 0, wantCode: errors.InvalidArgument},
+		{desc: "negativeLimit", limit: -1, wantCode: errors.InvalidArgument},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		got, err := tx.ListRecentTrees(ctx, test.limit, test.includeDeleted)
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+		if errors.ErrorCode(err) != test.wantCode {
+			t.Errorf("%v: ListRecentTrees() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		var gotIDs, wantIDs []int64
+		for _, tree := range got {
+			gotIDs = append(gotIDs, tree.TreeId)
+		}
+		for _, tree := range test.want {
+			wantIDs = append(wantIDs, tree.TreeId)
+		}
+		if diff := pretty.Compare(gotIDs, wantIDs); diff != "" {
+			t.Errorf("%v: ListRecentTrees() IDs diff (-got +want):\n%v", test.desc, diff)
+		}
+	}
+}
+
+// TestGetTreeByExternalRef tests that ExternalRef is persisted and that trees
+// can be resolved by it.
+func (tester *AdminStorageTester) TestGetTreeByExternalRef(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	// Refs are unique to this test, as storages may share a database.
+	prefix := fmt.Sprintf("%x-", time.Now().UnixNano())
+	unique, updated, deleted, duplicate := prefix+"unique", prefix+"updated", prefix+"deleted", prefix+"duplicate"
+	withRef := func(ref string) *trillian.Tree {
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.ExternalRef = ref
+		return tree
+	}
+	uniqueTree := makeTreeOrFail(ctx, s, spec{Tree: withRef(unique)}, t.Fatalf)
+	if got, want := uniqueTree.ExternalRef, unique; got != want {
+		t.Errorf("CreateTree().ExternalRef = %q, want = %q", got, want)
+	}
+	deletedTree := makeTreeOrFail(ctx, s, spec{Tree: withRef(deleted), Deleted: true}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: withRef(duplicate)}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: withRef(duplicate)}, t.Fatalf)
+
+	updatedTree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	updatedTree, _, err := updateTree(ctx, s, updatedTree.TreeId, func(tree *trillian.Tree) {
+		tree.ExternalRef = updated
+	})
+	if err != nil {
+		t.Fatalf("UpdateTree() = (_, _, %v), want = (_, _, nil)", err)
+	}
+	if got, want := updatedTree.ExternalRef, updated; got != want {
+		t.Errorf("UpdateTree().ExternalRef = %q, want = %q", got, want)
+	}
+
+	tests := []struct {
+		desc           string
+		ref            string
+		includeDeleted bool
+		want           *trillian.Tree
+		wantCode       errors.Code
+	}{
+		{desc: "unique", ref: unique, want: uniqueTree},
+		{desc: "updated", ref: updated, want: updatedTree},
+		{desc: "deleted", ref: deleted, wantCode: errors.NotFound},
+		{desc: "includeDeleted", ref: deleted, includeDeleted: true, want: deletedTree},
+		{desc: "unknown", ref: prefix + "unknown", wantCode: errors.NotFound},
+		{desc: "duplicate", ref: duplicate, wantCode: errors.FailedPrecondition},
+		{desc: "empty", ref: "", wantCode: errors.InvalidArgument},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		tree, err := tx.GetTreeByExternalRef(ctx, test.ref, test.includeDeleted)
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+		if errors.ErrorCode(err) != test.wantCode {
+			t.Errorf("%v: GetTreeByExternalRef() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if !proto.Equal(tree, test.want) {
+			diff := pretty.Compare(tree, test.want)
+			t.Errorf("%v: post-GetTreeByExternalRef diff:\n%v", test.desc, diff)
+		}
+	}
+}
+
+// TestRepairDeletedConsistency tests that RepairDeletedConsistency fixes trees
+// whose Deleted flag and DeleteTime disagree, leaving other trees untouched.
+func (tester *AdminStorageTester) TestRepairDeletedConsistency(t *testing.T) {
+	if tester.SetDeletedUnchecked == nil {
+		t.Skip("SetDeletedUnchecked not set")
+	}
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	activeTree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	deletedTree := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+	noDeleteTime := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	if err := tester.SetDeletedUnchecked(ctx, noDeleteTime.TreeId, true, nil); err != nil {
+		t.Fatalf("SetDeletedUnchecked(%v, true, nil) = %v, want = nil", noDeleteTime.TreeId, err)
+	}
+	staleDeleteTime := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	now := time.Now()
+	if err := tester.SetDeletedUnchecked(ctx, staleDeleteTime.TreeId, false, &now); err != nil {
+		t.Fatalf("SetDeletedUnchecked(%v, false, now) = %v, want = nil", staleDeleteTime.TreeId, err)
+	}
+
+	repaired, err := storage.RepairDeletedConsistency(ctx, s)
+	if err != nil {
+		t.Fatalf("RepairDeletedConsistency() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := repaired, 2; got != want {
+		t.Errorf("RepairDeletedConsistency() = (%v, _), want = (%v, _)", got, want)
+	}
+
+	// Well-formed trees are untouched.
+	for _, want := range []*trillian.Tree{activeTree, deletedTree} {
+		got, err := getTree(ctx, s, want.TreeId)
+		if err != nil {
+			t.Fatalf("getTree(%v) = (_, %v), want = (_, nil)", want.TreeId, err)
+		}
+		if !proto.Equal(got, want) {
+			diff := pretty.Compare(got, want)
+			t.Errorf("getTree(%v) diff after repair:\n%v", want.TreeId, diff)
+		}
+	}
+
+	got, err := getTree(ctx, s, noDeleteTime.TreeId)
+	if err != nil {
+		t.Fatalf("getTree(%v) = (_, %v), want = (_, nil)", noDeleteTime.TreeId, err)
+	}
+	if !got.Deleted || !proto.Equal(got.DeleteTime, got.UpdateTime) {
+		t.Errorf("getTree(%v) = {Deleted: %v, DeleteTime: %v}, want = {Deleted: true, DeleteTime: %v}", got.TreeId, got.Deleted, got.DeleteTime, got.UpdateTime)
+	}
+	got, err = getTree(ctx, s, staleDeleteTime.TreeId)
+	if err != nil {
+		t.Fatalf("getTree(%v) = (_, %v), want = (_, nil)", staleDeleteTime.TreeId, err)
+	}
+	if got.Deleted || got.DeleteTime != nil {
+		t.Errorf("getTree(%v) = {Deleted: %v, DeleteTime: %v}, want = {Deleted: false, DeleteTime: nil}", got.TreeId, got.Deleted, got.DeleteTime)
+	}
+
+	// Repairing again is a no-op.
+	if repaired, err := storage.RepairDeletedConsistency(ctx, s); err != nil || repaired != 0 {
+		t.Errorf("RepairDeletedConsistency() = (%v, %v), want = (0, nil)", repaired, err)
+	}
+}
+
+// TestExistingTreeIDs tests that ExistingTreeIDs reports which of a mix of
+// existing, soft-deleted and absent IDs exist.
+func (tester *AdminStorageTester) TestExistingTreeIDs(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	active := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	deleted := makeTreeOrFail(ctx, s, spec{Tree: MapTree, Deleted: true}, t.Fatalf)
+	const absent = 12345
+	candidates := []int64{active.TreeId, deleted.TreeId, absent}
+
+	tests := []struct {
+		desc           string
+		candidateIDs   []int64
+		includeDeleted bool
+		want           map[int64]bool
+	}{
+		{
+			desc:         "excludeDeleted",
+			candidateIDs: candidates,
+			want:         map[int64]bool{active.TreeId: true, deleted.TreeId: false, absent: false},
+		},
+		{
+			desc:           "includeDeleted",
+			candidateIDs:   candidates,
+			includeDeleted: true,
+			want:           map[int64]bool{active.TreeId: true, deleted.TreeId: true, absent: false},
+		},
+		{
+			desc:         "duplicates",
+			candidateIDs: []int64{active.TreeId, active.TreeId, absent},
+			want:         map[int64]bool{active.TreeId: true, absent: false},
+		},
+		{
+			desc: "noCandidates",
+			want: map[int64]bool{},
+		},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		got, err := tx.ExistingTreeIDs(ctx, test.candidateIDs, test.includeDeleted)
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+		if err != nil {
+			t.Errorf("%v: ExistingTreeIDs() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if diff := pretty.Compare(got, test.want); diff != "" {
+			t.Errorf("%v: ExistingTreeIDs() diff (-got +want):\n%v", test.desc, diff)
+		}
+	}
+}
+
+// TestPreferredReadConsistency tests that PreferredReadConsistency is
+// persisted on creation and update, and that unknown values are rejected.
+func (tester *AdminStorageTester) TestPreferredReadConsistency(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	eventualTree := proto.Clone(LogTree).(*trillian.Tree)
+	eventualTree.PreferredReadConsistency = trillian.ReadConsistency_EVENTUAL
+	created := makeTreeOrFail(ctx, s, spec{Tree: eventualTree}, t.Fatalf)
+	stored, err := getTree(ctx, s, created.TreeId)
+	if err != nil {
+		t.Fatalf("getTree() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := stored.PreferredReadConsistency, trillian.ReadConsistency_EVENTUAL; got != want {
+		t.Errorf("getTree().PreferredReadConsistency = %v, want = %v", got, want)
+	}
+
+	for _, rc := range []trillian.ReadConsistency{trillian.ReadConsistency_STRONG, trillian.ReadConsistency_UNSPECIFIED_READ_CONSISTENCY} {
+		if _, _, err := updateTree(ctx, s, created.TreeId, func(tree *trillian.Tree) {
+			tree.PreferredReadConsistency = rc
+		}); err != nil {
+			t.Fatalf("updateTree(%v) = (_, _, %v), want = (_, _, nil)", rc, err)
+		}
+		stored, err := getTree(ctx, s, created.TreeId)
+		if err != nil {
+			t.Fatalf("getTree() = (_, %v), want = (_, nil)", err)
+		}
+		if got := stored.PreferredReadConsistency; got != rc {
+			t.Errorf("getTree().PreferredReadConsistency = %v, want = %v", got, rc)
+		}
+	}
+
+	unknown := trillian.ReadConsistency(99)
+	unknownTree := proto.Clone(LogTree).(*trillian.Tree)
+	unknownTree.PreferredReadConsistency = unknown
+	if _, err := createTree(ctx, s, unknownTree); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("createTree(%v) returned err = %v, wantCode = %s", unknown, err, errors.InvalidArgument)
+	}
+	if _, _, err := updateTree(ctx, s, created.TreeId, func(tree *trillian.Tree) {
+		tree.PreferredReadConsistency = unknown
+	}); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("updateTree(%v) returned err = %v, wantCode = %s", unknown, err, errors.InvalidArgument)
+	}
+}
+
+// TestMaxDescriptionBytes tests that ListTreesForDisplay truncates
+// descriptions as per AdminStorageOptions.MaxDescriptionBytes, without
+// splitting multibyte characters, while GetTree and ListTrees return them in
+// full.
+func (tester *AdminStorageTester) TestMaxDescriptionBytes(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+	const maxBytes = 10
+	s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{MaxDescriptionBytes: maxBytes})
+
+	described := func(description string) *trillian.Tree {
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.Description = description
+		return tree
+	}
+	// "é" is 2 bytes long, so a cut at an odd byte offset would split it.
+	tests := []struct {
+		desc, description, want string
+	}{
+		{desc: "short", description: "Llamas", want: "Llamas"},
+		{desc: "exact", description: "0123456789", want: "0123456789"},
+		{desc: "ascii", description: "Many llamas", want: "Many ll" + storage.DescriptionEllipsis},
+		{desc: "multibyte", description: strings.Repeat("é", 20), want: "ééé" + storage.DescriptionEllipsis},
+	}
+	ids := make(map[int64]int)
+	for i, test := range tests {
+		tree := makeTreeOrFail(ctx, s, spec{Tree: described(test.description)}, t.Fatalf)
+		ids[tree.TreeId] = i
+	}
+
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	trees, err := tx.ListTreesForDisplay(ctx, false /* includeDeleted */)
+	if err != nil {
+		t.Fatalf("ListTreesForDisplay() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := len(trees), len(tests); got != want {
+		t.Fatalf("ListTreesForDisplay() returned %v trees, want = %v", got, want)
+	}
+	for _, tree := range trees {
+		test := tests[ids[tree.TreeId]]
+		if got := tree.Description; got != test.want {
+			t.Errorf("%v: ListTreesForDisplay() Description = %q, want = %q", test.desc, got, test.want)
+		}
+		if got := tree.Description; len(got) > maxBytes || !utf8.ValidString(got) {
+			t.Errorf("%v: ListTreesForDisplay() Description = %q, want valid UTF-8 of at most %v bytes", test.desc, got, maxBytes)
+		}
+
+		full, err := tx.GetTree(ctx, tree.TreeId)
+		if err != nil {
+			t.Fatalf("%v: GetTree() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		if got, want := full.Description, test.description; got != want {
+			t.Errorf("%v: GetTree() Description = %q, want = %q", test.desc, got, want)
+		}
+	}
+
+	listed, err := tx.ListTrees(ctx, false /* includeDeleted */)
+	if err != nil {
+		t.Fatalf("ListTrees() = (_, %v), want = (_, nil)", err)
+	}
+	for _, tree := range listed {
+		test := tests[ids[tree.TreeId]]
+		if got, want := tree.Description, test.description; got != want {
+			t.Errorf("%v: ListTrees() Description = %q, want = %q", test.desc, got, want)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+}
+
+// TestMaxDescriptionBytesRoundTrip tests that exports and reconciliation keep
+// full descriptions when AdminStorageOptions.MaxDescriptionBytes is set.
+func (tester *AdminStorageTester) TestMaxDescriptionBytesRoundTrip(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	ctx := context.Background()
+	s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{MaxDescriptionBytes: 10})
+
+	long := proto.Clone(LogTree).(*trillian.Tree)
+	long.Description = strings.Repeat("llama ", 20)
+	tree := makeTreeOrFail(ctx, s, spec{Tree: long}, t.Fatalf)
+
+	var buf bytes.Buffer
+	if _, err := storage.ExportTreesSince(ctx, s, &buf, nil /* since */); err != nil {
+		t.Fatalf("ExportTreesSince() = (_, %v), want = (_, nil)", err)
+	}
+	exported, err := storage.ReadExportedTrees(&buf)
+	if err != nil {
+		t.Fatalf("ReadExportedTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if len(exported) != 1 {
+		t.Fatalf("ExportTreesSince() exported %v trees, want = 1", len(exported))
+	}
+	if got, want := exported[0].Description, long.Description; got != want {
+		t.Errorf("exported Description = %q, want = %q", got, want)
+	}
+
+	// Reconcile a stale copy of the tree to s, then apply the update.
+	stale := proto.Clone(tree).(*trillian.Tree)
+	stale.Description = "Stale"
+	dst := &fixedTreesStorage{AdminStorage: s, trees: []*trillian.Tree{stale}}
+	plan, err := storage.PlanReconciliation(ctx, s, dst)
+	if err != nil {
+		t.Fatalf("PlanReconciliation() = (_, %v), want = (_, nil)", err)
+	}
+	update, ok := plan.Updates[tree.TreeId]
+	if !ok {
+		t.Fatalf("PlanReconciliation() = %+v, want an update of tree %v", plan, tree.TreeId)
+	}
+	update(stale)
+	if got, want := stale.Description, long.Description; got != want {
+		t.Errorf("reconciled Description = %q, want = %q", got, want)
+	}
+}
+
+// fixedTreesStorage is an AdminStorage whose snapshots list a fixed set of
+// trees, regardless of what's actually stored.
+type fixedTreesStorage struct {
+	storage.AdminStorage
+	trees []*trillian.Tree
+}
+
+func (s *fixedTreesStorage) Snapshot(ctx context.Context) (storage.ReadOnlyAdminTX, error) {
+	tx, err := s.AdminStorage.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fixedTreesTX{ReadOnlyAdminTX: tx, trees: s.trees}, nil
+}
+
+type fixedTreesTX struct {
+	storage.ReadOnlyAdminTX
+	trees []*trillian.Tree
+}
+
+func (tx *fixedTreesTX) ListTrees(ctx context.Context, includeDeleted bool) ([]*trillian.Tree, error) {
+	return tx.trees, nil
+}
+
+// TestWithTreeMaintenance tests that WithTreeMaintenance keeps trees in the
+// MAINTENANCE state while fn runs, and restores their previous state even if
+// fn fails or panics.
+func (tester *AdminStorageTester) TestWithTreeMaintenance(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Frozen: true}, t.Fatalf)
+	assertState := func(desc string, want trillian.TreeState) {
+		stored, err := getTree(ctx, s, tree.TreeId)
+		if err != nil {
+			t.Fatalf("%v: getTree() = (_, %v), want = (_, nil)", desc, err)
+		}
+		if got := stored.TreeState; got != want {
+			t.Errorf("%v: TreeState = %s, want = %s", desc, got, want)
+		}
+	}
+
+	fnErr := errors.New(errors.Internal, "maintenance failed")
+	err := storage.WithTreeMaintenance(ctx, s, tree.TreeId, func() error {
+		assertState("during maintenance", trillian.TreeState_MAINTENANCE)
+		nestedErr := storage.WithTreeMaintenance(ctx, s, tree.TreeId, func() error {
+			t.Error("nested maintenance fn called")
+			return nil
+		})
+		if got, want := errors.ErrorCode(nestedErr), errors.FailedPrecondition; got != want {
+			t.Errorf("nested WithTreeMaintenance() returned err = %v, wantCode = %s", nestedErr, want)
+		}
+		return fnErr
+	})
+	if err != fnErr {
+		t.Errorf("WithTreeMaintenance() = %v, want = %v", err, fnErr)
+	}
+	assertState("after failed maintenance", trillian.TreeState_FROZEN)
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("WithTreeMaintenance() didn't propagate panic")
+			}
+		}()
+		storage.WithTreeMaintenance(ctx, s, tree.TreeId, func() error {
+			panic("maintenance panicked")
+		})
+	}()
+	assertState("after panicked maintenance", trillian.TreeState_FROZEN)
+
+	if err := storage.WithTreeMaintenance(ctx, s, tree.TreeId, func() error { return nil }); err != nil {
+		t.Errorf("WithTreeMaintenance() = %v, want = nil", err)
+	}
+	assertState("after maintenance", trillian.TreeState_FROZEN)
+
+	unknownErr := storage.WithTreeMaintenance(ctx, s, 12345, func() error {
+		t.Error("fn called for unknown tree")
+		return nil
+	})
+	if got, want := errors.ErrorCode(unknownErr), errors.NotFound; got != want {
+		t.Errorf("WithTreeMaintenance(12345) returned err = %v, wantCode = %s", unknownErr, want)
+	}
+}
+
+// TestWithTreeMaintenanceCanceled tests that WithTreeMaintenance restores the
+// tree's previous state even if ctx is canceled by fn.
+func (tester *AdminStorageTester) TestWithTreeMaintenanceCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := tester.NewAdminStorage()
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	if err := storage.WithTreeMaintenance(ctx, s, tree.TreeId, func() error {
+		cancel()
+		return nil
+	}); err != nil {
+		t.Errorf("WithTreeMaintenance() = %v, want = nil", err)
+	}
+
+	stored, err := getTree(context.Background(), s, tree.TreeId)
+	if err != nil {
+		t.Fatalf("getTree() = (_, %v), want = (_, nil)", err)
+	}
+	if got, want := stored.TreeState, trillian.TreeState_ACTIVE; got != want {
+		t.Errorf("TreeState = %s, want = %s", got, want)
+	}
+}
+
+// TestCountTreesByLabel tests that CountTreesByLabel counts every tree
+// carrying each label, including trees with multiple labels.
+func (tester *AdminStorageTester) TestCountTreesByLabel(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	labeled := func(labels ...string) *trillian.Tree {
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.Labels = labels
+		return tree
+	}
+	makeTreeOrFail(ctx, s, spec{Tree: labeled("prod", "eu")}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: labeled("prod", "us")}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: labeled("staging", "eu")}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: labeled("prod", "legacy"), Deleted: true}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	tests := []struct {
+		desc           string
+		includeDeleted bool
+		want           map[string]int64
+	}{
+		{
+			desc: "excludeDeleted",
+			want: map[string]int64{"prod": 2, "eu": 2, "us": 1, "staging": 1},
+		},
+		{
+			desc:           "includeDeleted",
+			includeDeleted: true,
+			want:           map[string]int64{"prod": 3, "eu": 2, "us": 1, "staging": 1, "legacy": 1},
+		},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		got, err := tx.CountTreesByLabel(ctx, test.includeDeleted)
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+		if err != nil {
+			t.Errorf("%v: CountTreesByLabel() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if diff := pretty.Compare(got, test.want); diff != "" {
+			t.Errorf("%v: CountTreesByLabel() diff (-got +want):\n%v", test.desc, diff)
+		}
+	}
+}
+
+// TestListTreeRevisions tests that ListTreeRevisions reflects the number of
+// writes every tree underwent.
+func (tester *AdminStorageTester) TestListTreeRevisions(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	untouched := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	updated := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	for i := 0; i < 3; i++ {
+		if _, _, err := updateTree(ctx, s, updated.TreeId, func(tree *trillian.Tree) {
+			tree.Description = fmt.Sprintf("Update %v", i)
+		}); err != nil {
+			t.Fatalf("updateTree() = (_, _, %v), want = (_, _, nil)", err)
+		}
+	}
+	labeled := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	if _, err := setTreeLabels(ctx, s, labeled.TreeId, []string{"llamas"}); err != nil {
+		t.Fatalf("setTreeLabels() = (_, %v), want = (_, nil)", err)
+	}
+	// A no-op update doesn't write the tree, so it keeps its revision.
+	if _, _, err := updateTree(ctx, s, labeled.TreeId, func(*trillian.Tree) {}); err != nil {
+		t.Fatalf("updateTree(no-op) = (_, _, %v), want = (_, _, nil)", err)
+	}
+	// Soft deletion is a write too.
+	deleted := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+
+	tests := []struct {
+		desc           string
+		includeDeleted bool
+		want           map[int64]int64
+	}{
+		{
+			desc: "excludeDeleted",
+			want: map[int64]int64{untouched.TreeId: 1, updated.TreeId: 4, labeled.TreeId: 2},
+		},
+		{
+			desc:           "includeDeleted",
+			includeDeleted: true,
+			want:           map[int64]int64{untouched.TreeId: 1, updated.TreeId: 4, labeled.TreeId: 2, deleted.TreeId: 2},
+		},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		got, err := tx.ListTreeRevisions(ctx, test.includeDeleted)
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+		if err != nil {
+			t.Errorf("%v: ListTreeRevisions() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if diff := pretty.Compare(got, test.want); diff != "" {
+			t.Errorf("%v: ListTreeRevisions() diff (-got +want):\n%v", test.desc, diff)
+		}
+	}
+}
+
+// TestStreamTreeConfigHashes tests that StreamTreeConfigHashes emits the
+// TreeConfigHash of every tree, and that cancellation stops the stream.
+func (tester *AdminStorageTester) TestStreamTreeConfigHashes(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	log := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	frozen := makeTreeOrFail(ctx, s, spec{Tree: MapTree, Frozen: true}, t.Fatalf)
+	deleted := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+	// labeled differs from log only in its labels.
+	labeledTree := proto.Clone(LogTree).(*trillian.Tree)
+	labeledTree.Labels = []string{"llamas"}
+	labeled := makeTreeOrFail(ctx, s, spec{Tree: labeledTree}, t.Fatalf)
+
+	tests := []struct {
+		includeDeleted bool
+		wantTrees      []*trillian.Tree
+	}{
+		{includeDeleted: false, wantTrees: []*trillian.Tree{log, frozen, labeled}},
+		{includeDeleted: true, wantTrees: []*trillian.Tree{log, frozen, deleted, labeled}},
+	}
+	for _, test := range tests {
+		sort.Slice(test.wantTrees, func(i, j int) bool { return test.wantTrees[i].TreeId < test.wantTrees[j].TreeId })
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+		}
+		hashc, errc := tx.StreamTreeConfigHashes(ctx, test.includeDeleted)
+		var got []storage.TreeHash
+		for hash := range hashc {
+			got = append(got, hash)
+		}
+		if err := <-errc; err != nil {
+			t.Errorf("StreamTreeConfigHashes(%v) err = %v, want = nil", test.includeDeleted, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Errorf("Commit() = %v, want = nil", err)
+		}
+		tx.Close()
+
+		if gotLen, wantLen := len(got), len(test.wantTrees); gotLen != wantLen {
+			t.Errorf("StreamTreeConfigHashes(%v) emitted %v hashes, want = %v", test.includeDeleted, gotLen, wantLen)
+			continue
+		}
+		for i, hash := range got {
+			want := test.wantTrees[i]
+			wantHash, err := storage.TreeConfigHash(want)
+			if err != nil {
+				t.Fatalf("TreeConfigHash() = (_, %v), want = (_, nil)", err)
+			}
+			if hash.TreeID != want.TreeId || !bytes.Equal(hash.ConfigHash, wantHash) {
+				t.Errorf("StreamTreeConfigHashes(%v)[%v] = {%v, %x}, want = {%v, %x}", test.includeDeleted, i, hash.TreeID, hash.ConfigHash, want.TreeId, wantHash)
+			}
+		}
+
+		byID := make(map[int64][]byte)
+		for _, hash := range got {
+			byID[hash.TreeID] = hash.ConfigHash
+		}
+		if bytes.Equal(byID[log.TreeId], byID[labeled.TreeId]) {
+			t.Errorf("StreamTreeConfigHashes(%v) = %x for trees differing only in labels, want different hashes", test.includeDeleted, byID[log.TreeId])
+		}
+	}
+
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	cctx, cancel := context.WithCancel(ctx)
+	hashc, errc := tx.StreamTreeConfigHashes(cctx, true /* includeDeleted */)
+	<-hashc
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-hashc:
+		case <-timeout:
+			t.Fatal("StreamTreeConfigHashes() didn't stop after cancellation")
+		}
+	}
+	if err := <-errc; errors.ErrorCode(err) != errors.Canceled {
+		t.Errorf("StreamTreeConfigHashes(cancelled) err = %v, wantCode = %s", err, errors.Canceled)
+	}
+}
+
+// TestVerifyConfigChecksumOnRead tests that GetTree detects configs corrupted
+// behind storage's back under AdminStorageOptions.VerifyConfigChecksumOnRead,
+// and that legitimate writes keep the checksum up to date.
+func (tester *AdminStorageTester) TestVerifyConfigChecksumOnRead(t *testing.T) {
+	if tester.NewAdminStorageWithOptions == nil {
+		t.Skip("NewAdminStorageWithOptions not set")
+	}
+	if tester.SetDescriptionUnchecked == nil {
+		t.Skip("SetDescriptionUnchecked not set")
+	}
+	ctx := context.Background()
+	// Both storages point to the same database, so create them before any
+	// trees.
+	unverified := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{})
+	s := tester.NewAdminStorageWithOptions(storage.AdminStorageOptions{VerifyConfigChecksumOnRead: true})
+
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	if _, err := getTree(ctx, s, tree.TreeId); err != nil {
+		t.Errorf("getTree(created) = (_, %v), want = (_, nil)", err)
+	}
+	if _, _, err := updateTree(ctx, s, tree.TreeId, func(tree *trillian.Tree) {
+		tree.Description = "Updated"
+	}); err != nil {
+		t.Fatalf("updateTree() = (_, _, %v), want = (_, _, nil)", err)
+	}
+	if _, err := getTree(ctx, s, tree.TreeId); err != nil {
+		t.Errorf("getTree(updated) = (_, %v), want = (_, nil)", err)
+	}
+	// Labels and sequencing_paused are part of the checksum too.
+	if _, err := setTreeLabels(ctx, s, tree.TreeId, []string{"llama"}); err != nil {
+		t.Fatalf("setTreeLabels() = (_, %v), want = (_, nil)", err)
+	}
+	if _, err := getTree(ctx, s, tree.TreeId); err != nil {
+		t.Errorf("getTree(labeled) = (_, %v), want = (_, nil)", err)
+	}
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	if _, err := tx.PauseSequencing(ctx, tree.TreeId); err != nil {
+		t.Fatalf("PauseSequencing() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+	tx.Close()
+	if _, err := getTree(ctx, s, tree.TreeId); err != nil {
+		t.Errorf("getTree(paused) = (_, %v), want = (_, nil)", err)
+	}
+
+	if err := tester.SetDescriptionUnchecked(ctx, tree.TreeId, "Corrupted"); err != nil {
+		t.Fatalf("SetDescriptionUnchecked() = %v, want = nil", err)
+	}
+	if _, err := getTree(ctx, s, tree.TreeId); errors.ErrorCode(err) != errors.DataLoss {
+		t.Errorf("getTree(corrupted) returned err = %v, wantCode = %s", err, errors.DataLoss)
+	}
+	if _, err := getTree(ctx, unverified, tree.TreeId); err != nil {
+		t.Errorf("getTree(corrupted) without verification = (_, %v), want = (_, nil)", err)
+	}
+	// Reads of multiple trees are verified too.
+	snapshot, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer snapshot.Close()
+	if _, err := snapshot.ListTrees(ctx, false); errors.ErrorCode(err) != errors.DataLoss {
+		t.Errorf("ListTrees(corrupted) returned err = %v, wantCode = %s", err, errors.DataLoss)
+	}
+}
+
+// TestGetTreeLineage tests that GetTreeLineage follows ClonedFrom from a tree
+// back to its original ancestor.
+func (tester *AdminStorageTester) TestGetTreeLineage(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	cloneOf := func(parent *trillian.Tree) *trillian.Tree {
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.ClonedFrom = parent.TreeId
+		return tree
+	}
+	a := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	b := makeTreeOrFail(ctx, s, spec{Tree: cloneOf(a)}, t.Fatalf)
+	c := makeTreeOrFail(ctx, s, spec{Tree: cloneOf(b)}, t.Fatalf)
+	if got, want := c.ClonedFrom, b.TreeId; got != want {
+		t.Errorf("ClonedFrom = %v, want = %v", got, want)
+	}
+
+	tests := []struct {
+		desc   string
+		treeID int64
+		want   []*trillian.Tree
+	}{
+		{desc: "clone", treeID: c.TreeId, want: []*trillian.Tree{c, b, a}},
+		{desc: "original", treeID: a.TreeId, want: []*trillian.Tree{a}},
+	}
+	for _, test := range tests {
+		lineage, err := storage.GetTreeLineage(ctx, s, test.treeID)
+		if err != nil {
+			t.Errorf("%v: GetTreeLineage() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if got, want := len(lineage), len(test.want); got != want {
+			t.Errorf("%v: GetTreeLineage() returned %v trees, want = %v", test.desc, got, want)
+			continue
+		}
+		for i, tree := range lineage {
+			if !proto.Equal(tree, test.want[i]) {
+				diff := pretty.Compare(tree, test.want[i])
+				t.Errorf("%v: GetTreeLineage()[%v] diff:\n%v", test.desc, i, diff)
+			}
+		}
+	}
+
+	if _, err := createTree(ctx, s, cloneOf(&trillian.Tree{TreeId: 12345})); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("createTree(clone of unknown tree) returned err = %v, wantCode = %s", err, errors.InvalidArgument)
+	}
+	if _, err := storage.GetTreeLineage(ctx, s, 12345); errors.ErrorCode(err) != errors.NotFound {
+		t.Errorf("GetTreeLineage(12345) returned err = %v, wantCode = %s", err, errors.NotFound)
+	}
+}
+
+// TestListUntouchedTrees tests that ListUntouchedTrees returns only trees
+// whose config wasn't updated since creation.
+func (tester *AdminStorageTester) TestListUntouchedTrees(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	untouched := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	updated := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	// Soft deletion doesn't change the config, so deleted trees stay untouched.
+	deleted := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+
+	// Make sure the update lands on a later millisecond than creation.
+	time.Sleep(2 * time.Millisecond)
+	if _, _, err := updateTree(ctx, s, updated.TreeId, func(tree *trillian.Tree) {
+		tree.Description = "Updated"
+	}); err != nil {
+		t.Fatalf("updateTree() = (_, _, %v), want = (_, _, nil)", err)
+	}
+
+	tests := []struct {
+		includeDeleted bool
+		wantIDs        []int64
+	}{
+		{includeDeleted: false, wantIDs: []int64{untouched.TreeId}},
+		{includeDeleted: true, wantIDs: []int64{untouched.TreeId, deleted.TreeId}},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+		}
+		trees, err := tx.ListUntouchedTrees(ctx, test.includeDeleted)
+		if err != nil {
+			t.Errorf("ListUntouchedTrees(%v) = (_, %v), want = (_, nil)", test.includeDeleted, err)
+			tx.Close()
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			t.Errorf("Commit() = %v, want = nil", err)
+		}
+		tx.Close()
+
+		var gotIDs []int64
+		for _, tree := range trees {
+			gotIDs = append(gotIDs, tree.TreeId)
+		}
+		sort.Slice(test.wantIDs, func(i, j int) bool { return test.wantIDs[i] < test.wantIDs[j] })
+		if diff := pretty.Compare(gotIDs, test.wantIDs); diff != "" {
+			t.Errorf("ListUntouchedTrees(%v) diff (-got +want):\n%v", test.includeDeleted, diff)
+		}
+	}
+}
+
+// TestStoreConfigDigest tests that stores with the same tree configs have the
+// same digest, and that config changes change it.
+func (tester *AdminStorageTester) TestStoreConfigDigest(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	trees := []*trillian.Tree{
+		makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf),
+		makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf),
+	}
+	digest, err := storage.StoreConfigDigest(ctx, s, false /* includeDeleted */)
+	if err != nil {
+		t.Fatalf("StoreConfigDigest() = (_, %v), want = (_, nil)", err)
+	}
+
+	// Test storages may share a database, so s isn't used past this point.
+	replica := tester.NewAdminStorage()
+	if _, err := storage.ImportTrees(ctx, replica, trees, storage.ImportOptions{}); err != nil {
+		t.Fatalf("ImportTrees() = (_, %v), want = (_, nil)", err)
+	}
+	replicaDigest, err := storage.StoreConfigDigest(ctx, replica, false /* includeDeleted */)
+	if err != nil {
+		t.Fatalf("StoreConfigDigest(replica) = (_, %v), want = (_, nil)", err)
+	}
+	if replicaDigest != digest {
+		t.Errorf("StoreConfigDigest(replica) = %x, want = %x", replicaDigest, digest)
+	}
+
+	// Each divergence is applied on top of the previous ones, so every
+	// digest must differ from the one before it.
+	for _, test := range []struct {
+		desc   string
+		treeID int64
+		update func(*trillian.Tree)
+	}{
+		{desc: "labels", treeID: trees[1].TreeId, update: func(tree *trillian.Tree) { tree.Labels = []string{"llamas"} }},
+		{desc: "displayName", treeID: trees[0].TreeId, update: func(tree *trillian.Tree) { tree.DisplayName = "Diverged Tree" }},
+	} {
+		if _, _, err := updateTree(ctx, replica, test.treeID, test.update); err != nil {
+			t.Fatalf("%v: updateTree() returned err = %v", test.desc, err)
+		}
+		divergedDigest, err := storage.StoreConfigDigest(ctx, replica, false /* includeDeleted */)
+		if err != nil {
+			t.Fatalf("%v: StoreConfigDigest(diverged) = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		if divergedDigest == digest {
+			t.Errorf("%v: StoreConfigDigest(diverged) = %x, want != %x", test.desc, divergedDigest, digest)
+		}
+		digest = divergedDigest
+	}
+}
+
+// TestExportTreesSince tests that incremental exports contain exactly the
+// trees updated or deleted since the given time.
+func (tester *AdminStorageTester) TestExportTreesSince(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	updated := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	deleted := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	unchanged := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	var full bytes.Buffer
+	if _, err := storage.ExportTreesSince(ctx, s, &full, nil /* since */); err != nil {
+		t.Fatalf("ExportTreesSince(nil) = (_, %v), want = (_, nil)", err)
+	}
+	fullTrees, err := storage.ReadExportedTrees(&full)
+	if err != nil {
+		t.Fatalf("ReadExportedTrees(full) = (_, %v), want = (_, nil)", err)
+	}
+	fullIDs := make(map[int64]bool)
+	for _, tree := range fullTrees {
+		fullIDs[tree.TreeId] = true
+	}
+	for _, tree := range []*trillian.Tree{updated, deleted, unchanged} {
+		if !fullIDs[tree.TreeId] {
+			t.Errorf("ExportTreesSince(nil) doesn't contain tree %v", tree.TreeId)
+		}
+	}
+
+	// Stored timestamps may be truncated, so leave some slack around since.
+	time.Sleep(2 * time.Millisecond)
+	since := ptypes.TimestampNow()
+	time.Sleep(2 * time.Millisecond)
+
+	if _, _, err := updateTree(ctx, s, updated.TreeId, func(tree *trillian.Tree) {
+		tree.DisplayName = "Updated Tree"
+	}); err != nil {
+		t.Fatalf("updateTree() returned err = %v", err)
+	}
+	if _, err := softDeleteTree(ctx, s, deleted.TreeId); err != nil {
+		t.Fatalf("softDeleteTree() returned err = %v", err)
+	}
+
+	var delta bytes.Buffer
+	count, err := storage.ExportTreesSince(ctx, s, &delta, since)
+	if err != nil {
+		t.Fatalf("ExportTreesSince() = (_, %v), want = (_, nil)", err)
+	}
+	deltaTrees, err := storage.ReadExportedTrees(&delta)
+	if err != nil {
+		t.Fatalf("ReadExportedTrees(delta) = (_, %v), want = (_, nil)", err)
+	}
+	if count != len(deltaTrees) {
+		t.Errorf("ExportTreesSince() = (%v, nil), but export has %v trees", count, len(deltaTrees))
+	}
+
+	got := make(map[int64]*trillian.Tree)
+	var gotIDs []int64
+	for _, tree := range deltaTrees {
+		got[tree.TreeId] = tree
+		gotIDs = append(gotIDs, tree.TreeId)
+	}
+	if len(got) != 2 || got[updated.TreeId] == nil || got[deleted.TreeId] == nil {
+		t.Fatalf("ExportTreesSince() exported trees %v, want = [%v %v]", gotIDs, updated.TreeId, deleted.TreeId)
+	}
+	if name := got[updated.TreeId].DisplayName; name != "Updated Tree" {
+		t.Errorf("exported tree %v has DisplayName = %q, want = %q", updated.TreeId, name, "Updated Tree")
+	}
+	if !got[deleted.TreeId].Deleted {
+		t.Errorf("exported tree %v has Deleted = false, want = true", deleted.TreeId)
+	}
+}
+
+// TestTreeChurn tests that TreeChurn counts trees created and deleted within
+// the requested window.
+func (tester *AdminStorageTester) TestTreeChurn(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	// Import trees, as CreateTree doesn't allow controlling timestamps.
+	base := time.Unix(1500000000, 0)
+	importedTree := func(createTime time.Time, deleteTime *time.Time) *trillian.Tree {
+		id, err := storage.NewTreeID()
+		if err != nil {
+			t.Fatalf("NewTreeID() = (_, %v), want = (_, nil)", err)
+		}
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.TreeId = id
+		tree.CreateTime, _ = ptypes.TimestampProto(createTime)
+		tree.UpdateTime = tree.CreateTime
+		if deleteTime != nil {
+			tree.Deleted = true
+			tree.DeleteTime, _ = ptypes.TimestampProto(*deleteTime)
+		}
+		return tree
+	}
+	deleteTime := base.Add(3 * time.Hour)
+	trees := []*trillian.Tree{
+		importedTree(base, nil),
+		importedTree(base.Add(time.Hour), nil),
+		importedTree(base.Add(2*time.Hour), &deleteTime),
+	}
+	if _, err := storage.ImportTrees(ctx, s, trees, storage.ImportOptions{}); err != nil {
+		t.Fatalf("ImportTrees() = (_, %v), want = (_, nil)", err)
+	}
+
+	tests := []struct {
+		desc                     string
+		window                   time.Duration
+		now                      time.Time
+		wantCreated, wantDeleted int64
+	}{
+		{desc: "all", window: 4 * time.Hour, now: base.Add(4 * time.Hour), wantCreated: 3, wantDeleted: 1},
+		{desc: "lastTwoHours", window: 2 * time.Hour, now: base.Add(3 * time.Hour), wantCreated: 2},
+		{desc: "deletionOnly", window: time.Hour, now: base.Add(4 * time.Hour), wantDeleted: 1},
+		{desc: "before", window: time.Hour, now: base},
+		{desc: "after", window: time.Hour, now: base.Add(10 * time.Hour)},
+	}
+	for _, test := range tests {
+		created, deleted, err := storage.TreeChurn(ctx, s, test.window, test.now)
+		if err != nil {
+			t.Errorf("%v: TreeChurn() = (_, _, %v), want = (_, _, nil)", test.desc, err)
+			continue
+		}
+		if created != test.wantCreated || deleted != test.wantDeleted {
+			t.Errorf("%v: TreeChurn() = (%v, %v, nil), want = (%v, %v, nil)", test.desc, created, deleted, test.wantCreated, test.wantDeleted)
+		}
+	}
+}
+
+// TestGetTreeKeyInfo tests that key info is derived from the tree's public key.
+func (tester *AdminStorageTester) TestGetTreeKeyInfo(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	fingerprint := sha256.Sum256(LogTree.PublicKey.GetDer())
+	want := &storage.KeyInfo{
+		SignatureAlgorithm: spb.DigitallySigned_ECDSA,
+		Curve:              "P-256",
+		KeySizeBits:        256,
+		Fingerprint:        fingerprint[:],
+	}
+
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	got, err := tx.GetTreeKeyInfo(ctx, tree.TreeId)
+	if err != nil {
+		t.Fatalf("GetTreeKeyInfo() = (_, %v), want = (_, nil)", err)
+	}
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("GetTreeKeyInfo() diff (-got +want):\n%v", diff)
+	}
+	if _, err := tx.GetTreeKeyInfo(ctx, tree.TreeId+1); errors.ErrorCode(err) != errors.NotFound {
+		t.Errorf("GetTreeKeyInfo(unknownID) returned err = %v, wantCode = %s", err, errors.NotFound)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+}
+
+// TestVerifyStoredTreeRoundTrip tests that the standard fixtures are stored
+// without serialization drift.
+func (tester *AdminStorageTester) TestVerifyStoredTreeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	labeledLog := proto.Clone(LogTree).(*trillian.Tree)
+	labeledLog.Labels = []string{"llamas", "alpacas"}
+	trees := []*trillian.Tree{
+		makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf),
+		makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf),
+		makeTreeOrFail(ctx, s, spec{Tree: labeledLog}, t.Fatalf),
+		makeTreeOrFail(ctx, s, spec{Tree: LogTree, Frozen: true}, t.Fatalf),
+		makeTreeOrFail(ctx, s, spec{Tree: MapTree, Deleted: true}, t.Fatalf),
+	}
+	for _, tree := range trees {
+		if err := storage.VerifyStoredTreeRoundTrip(ctx, s, tree.TreeId); err != nil {
+			t.Errorf("VerifyStoredTreeRoundTrip(%v) = %v, want = nil", tree.TreeId, err)
+		}
+	}
+
+	if err := storage.VerifyStoredTreeRoundTrip(ctx, s, 12345); errors.ErrorCode(err) != errors.NotFound {
+		t.Errorf("VerifyStoredTreeRoundTrip(unknownTree) returned err = %v, wantCode = %s", err, errors.NotFound)
+	}
+}
+
+// TestListTrees tests both ListTreeIDs and ListTrees.
+func (tester *AdminStorageTester) TestListTrees(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	run := func(desc string, includeDeleted bool, wantTrees []*trillian.Tree) {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = %v, want = nil", desc, err)
+		}
+		defer tx.Close()
+		if err := runListTreeIDsTest(ctx, tx, includeDeleted, wantTrees); err != nil {
+			t.Errorf("%v: %v", desc, err)
+		}
+		if err := runListTreesTest(ctx, tx, includeDeleted, wantTrees); err != nil {
+			t.Errorf("%v: %v", desc, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Errorf("%v: Commit() returned err = %v", desc, err)
+		}
+	}
+
+	// Do a first pass with an empty DB
+	run("empty", false /* includeDeleted */, nil /* wantTrees */)
+	run("emptyDeleted", true /* includeDeleted */, nil /* wantTrees */)
+
+	// Add some trees and do another pass
+	activeLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	frozenLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Frozen: true}, t.Fatalf)
+	deletedLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+	activeMap := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
+	run("multipleTrees", false /* includeDeleted */, []*trillian.Tree{activeLog, frozenLog, activeMap})
+	run("multipleTreesDeleted", true /* includeDeleted */, []*trillian.Tree{activeLog, frozenLog, deletedLog, activeMap})
+}
+
+// TestListTreesPaginated tests that ListTreesPaginated clamps page sizes to
+// MaxListPageSize and signals remaining trees via the continuation token.
+func (tester *AdminStorageTester) TestListTreesPaginated(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+	opts := storage.AdminStorageOptions{MaxListPageSize: 2}
+
+	var want []*trillian.Tree
+	for i := 0; i < 3; i++ {
+		want = append(want, makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf))
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i].TreeId < want[j].TreeId })
+
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+
+	// An over-cap request is clamped.
+	page, token, err := storage.ListTreesPaginated(ctx, tx, opts, false /* includeDeleted */, 100 /* pageSize */, "" /* pageToken */)
+	if err != nil {
+		t.Fatalf("ListTreesPaginated() = (_, _, %v), want = (_, _, nil)", err)
+	}
+	if got, want := len(page), opts.MaxListPageSize; got != want {
+		t.Errorf("ListTreesPaginated() returned %v trees, want = %v", got, want)
+	}
+	if token == "" {
+		t.Fatalf("ListTreesPaginated() returned an empty token, want non-empty")
+	}
+
+	// The token resumes after the truncated page.
+	next, token, err := storage.ListTreesPaginated(ctx, tx, opts, false /* includeDeleted */, 100 /* pageSize */, token)
+	if err != nil {
+		t.Fatalf("ListTreesPaginated(token) = (_, _, %v), want = (_, _, nil)", err)
+	}
+	if token != "" {
+		t.Errorf("ListTreesPaginated(token) returned token = %q, want empty", token)
+	}
+	got := append(page, next...)
+	if len(got) != len(want) {
+		t.Fatalf("ListTreesPaginated() returned %v trees over all pages, want = %v", len(got), len(want))
+	}
+	for i := range want {
+		if !proto.Equal(got[i], want[i]) {
+			t.Errorf("post-ListTreesPaginated() diff (-got +want):\n%v", pretty.Compare(got, want))
+			break
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Commit() = %v, want = nil", err)
+	}
+}
+
+// TestListSequenceableTreeIDs tests that only trees eligible for sequencing
+// are returned by ListSequenceableTreeIDs.
+func (tester *AdminStorageTester) TestListSequenceableTreeIDs(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	activeLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	preorderedLog := proto.Clone(LogTree).(*trillian.Tree)
+	preorderedLog.TreeType = trillian.TreeType_PREORDERED_LOG
+	preorderedLog = makeTreeOrFail(ctx, s, spec{Tree: preorderedLog}, t.Fatalf)
+	drainingLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	if _, _, err := updateTree(ctx, s, drainingLog.TreeId, func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_DRAINING
+	}); err != nil {
+		t.Fatalf("updateTree() returned err = %v", err)
+	}
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree, Frozen: true}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+	makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
 
-		if err := assertStoredTree(ctx, s, unrelatedTree); err != nil {
-			t.Errorf("%v: %v", test.desc, err)
-		}
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	got, err := tx.ListSequenceableTreeIDs(ctx)
+	if err != nil {
+		t.Fatalf("ListSequenceableTreeIDs() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+
+	want := []int64{activeLog.TreeId, preorderedLog.TreeId, drainingLog.TreeId}
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+	if diff := pretty.Compare(got, want); diff != "" {
+		t.Errorf("ListSequenceableTreeIDs() diff (-got +want):\n%v", diff)
 	}
 }
 
-// TestListTrees tests both ListTreeIDs and ListTrees.
-func (tester *AdminStorageTester) TestListTrees(t *testing.T) {
+// TestPauseSequencing tests that paused trees drop out of
+// ListSequenceableTreeIDs, while still accepting writes, until resumed.
+func (tester *AdminStorageTester) TestPauseSequencing(t *testing.T) {
 	ctx := context.Background()
 	s := tester.NewAdminStorage()
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
 
-	run := func(desc string, includeDeleted bool, wantTrees []*trillian.Tree) {
+	isSequenceable := func() bool {
 		tx, err := s.Snapshot(ctx)
 		if err != nil {
-			t.Fatalf("%v: Snapshot() = %v, want = nil", desc, err)
+			t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
 		}
 		defer tx.Close()
-		if err := runListTreeIDsTest(ctx, tx, includeDeleted, wantTrees); err != nil {
-			t.Errorf("%v: %v", desc, err)
-		}
-		if err := runListTreesTest(ctx, tx, includeDeleted, wantTrees); err != nil {
-			t.Errorf("%v: %v", desc, err)
+		ids, err := tx.ListSequenceableTreeIDs(ctx)
+		if err != nil {
+			t.Fatalf("ListSequenceableTreeIDs() = (_, %v), want = (_, nil)", err)
 		}
 		if err := tx.Commit(); err != nil {
-			t.Errorf("%v: Commit() returned err = %v", desc, err)
+			t.Fatalf("Commit() = %v, want = nil", err)
+		}
+		for _, id := range ids {
+			if id == tree.TreeId {
+				return true
+			}
+		}
+		return false
+	}
+	setPaused := func(treeID int64, paused bool) (*trillian.Tree, error) {
+		tx, err := s.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Close()
+		var tree *trillian.Tree
+		if paused {
+			tree, err = tx.PauseSequencing(ctx, treeID)
+		} else {
+			tree, err = tx.ResumeSequencing(ctx, treeID)
+		}
+		if err != nil {
+			return nil, err
 		}
+		return tree, tx.Commit()
 	}
 
-	// Do a first pass with an empty DB
-	run("empty", false /* includeDeleted */, nil /* wantTrees */)
-	run("emptyDeleted", true /* includeDeleted */, nil /* wantTrees */)
+	// Pausing and resuming twice checks that both are idempotent.
+	for _, paused := range []bool{true, true, false, false} {
+		updated, err := setPaused(tree.TreeId, paused)
+		if err != nil {
+			t.Fatalf("paused = %v: setPaused() returned err = %v", paused, err)
+		}
+		if updated.SequencingPaused != paused {
+			t.Errorf("paused = %v: SequencingPaused = %v, want = %v", paused, updated.SequencingPaused, paused)
+		}
+		if !proto.Equal(updated.UpdateTime, tree.UpdateTime) {
+			t.Errorf("paused = %v: UpdateTime = %v, want = %v", paused, updated.UpdateTime, tree.UpdateTime)
+		}
+		if err := assertStoredTree(ctx, s, updated); err != nil {
+			t.Errorf("paused = %v: setPaused() not persisted: %v", paused, err)
+		}
+		if got, want := isSequenceable(), !paused; got != want {
+			t.Errorf("paused = %v: tree in ListSequenceableTreeIDs = %v, want = %v", paused, got, want)
+		}
+		// Paused trees keep accepting queued leaves.
+		if !storage.IsTreeWritable(updated) {
+			t.Errorf("paused = %v: IsTreeWritable() = false, want = true", paused)
+		}
+	}
 
-	// Add some trees and do another pass
-	activeLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
-	frozenLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Frozen: true}, t.Fatalf)
-	deletedLog := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
-	activeMap := makeTreeOrFail(ctx, s, spec{Tree: MapTree}, t.Fatalf)
-	run("multipleTrees", false /* includeDeleted */, []*trillian.Tree{activeLog, frozenLog, activeMap})
-	run("multipleTreesDeleted", true /* includeDeleted */, []*trillian.Tree{activeLog, frozenLog, deletedLog, activeMap})
+	for _, paused := range []bool{true, false} {
+		if _, err := setPaused(12345, paused); errors.ErrorCode(err) != errors.NotFound {
+			t.Errorf("paused = %v: setPaused(unknown) returned err = %v, wantCode = %s", paused, err, errors.NotFound)
+		}
+	}
 }
 
 func runListTreeIDsTest(ctx context.Context, tx storage.ReadOnlyAdminTX, includeDeleted bool, wantTrees []*trillian.Tree) error {
@@ -500,6 +4291,7 @@ func (tester *AdminStorageTester) TestSoftDeleteTree(t *testing.T) {
 		wantTree := proto.Clone(test.tree).(*trillian.Tree)
 		wantTree.Deleted = true
 		wantTree.DeleteTime = deletedTree.DeleteTime
+		wantTree.Revision++
 		if got, want := deletedTree, wantTree; !proto.Equal(got, want) {
 			t.Errorf("%v: post-softDeleteTree diff (-got +want):\n%v", test.desc, pretty.Compare(got, want))
 		}
@@ -532,6 +4324,109 @@ func (tester *AdminStorageTester) TestSoftDeleteTreeErrors(t *testing.T) {
 	}
 }
 
+// TestListHardDeletableTrees tests listing soft-deleted trees past a
+// retention window.
+func (tester *AdminStorageTester) TestListHardDeletableTrees(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	// DeleteTime is storage-generated, so trees are imported to control their
+	// age. Millisecond precision avoids truncation by storage.
+	now := time.Unix(100*24*60*60, 0)
+	retention := 7 * 24 * time.Hour
+	deletedTree := func(age time.Duration) *trillian.Tree {
+		treeID, err := storage.NewTreeID()
+		if err != nil {
+			t.Fatalf("NewTreeID() = (_, %v), want = (_, nil)", err)
+		}
+		tree := proto.Clone(LogTree).(*trillian.Tree)
+		tree.TreeId = treeID
+		tree.CreateTime, _ = ptypes.TimestampProto(time.Unix(1000, 0))
+		tree.UpdateTime, _ = ptypes.TimestampProto(time.Unix(2000, 0))
+		tree.Deleted = true
+		tree.DeleteTime, _ = ptypes.TimestampProto(now.Add(-age))
+		return tree
+	}
+	oldest := deletedTree(30 * 24 * time.Hour)
+	old := deletedTree(8 * 24 * time.Hour)
+	recent := deletedTree(24 * time.Hour)
+	if _, err := storage.ImportTrees(ctx, s, []*trillian.Tree{old, recent, oldest}, storage.ImportOptions{}); err != nil {
+		t.Fatalf("ImportTrees() = (_, %v), want = (_, nil)", err)
+	}
+	makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	tests := []struct {
+		desc      string
+		retention time.Duration
+		wantIDs   []int64
+	}{
+		{desc: "retention", retention: retention, wantIDs: []int64{oldest.TreeId, old.TreeId}},
+		{desc: "longRetention", retention: 365 * 24 * time.Hour},
+		{desc: "noRetention", retention: 0, wantIDs: []int64{oldest.TreeId, old.TreeId, recent.TreeId}},
+	}
+	for _, test := range tests {
+		tx, err := s.Snapshot(ctx)
+		if err != nil {
+			t.Fatalf("%v: Snapshot() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		trees, err := tx.ListHardDeletableTrees(ctx, test.retention, now)
+		if err != nil {
+			t.Errorf("%v: ListHardDeletableTrees() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Errorf("%v: Commit() = %v, want = nil", test.desc, err)
+		}
+		tx.Close()
+
+		var gotIDs []int64
+		for _, tree := range trees {
+			gotIDs = append(gotIDs, tree.TreeId)
+		}
+		if !reflect.DeepEqual(gotIDs, test.wantIDs) {
+			t.Errorf("%v: ListHardDeletableTrees() IDs = %v, want = %v", test.desc, gotIDs, test.wantIDs)
+		}
+	}
+}
+
+// TestListHardDeletableTreesSkipsLiveTrees tests that ListHardDeletableTrees
+// doesn't return trees that aren't soft deleted, even if they have a leftover
+// DeleteTime.
+func (tester *AdminStorageTester) TestListHardDeletableTreesSkipsLiveTrees(t *testing.T) {
+	if tester.SetDeletedUnchecked == nil {
+		t.Skip("SetDeletedUnchecked not set")
+	}
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	deleted := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Deleted: true}, t.Fatalf)
+	live := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	staleDeleteTime := time.Now().Add(-time.Hour)
+	if err := tester.SetDeletedUnchecked(ctx, live.TreeId, false, &staleDeleteTime); err != nil {
+		t.Fatalf("SetDeletedUnchecked(%v, false, _) = %v, want = nil", live.TreeId, err)
+	}
+
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	trees, err := tx.ListHardDeletableTrees(ctx, 0, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListHardDeletableTrees() = (_, %v), want = (_, nil)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Commit() = %v, want = nil", err)
+	}
+
+	var gotIDs []int64
+	for _, tree := range trees {
+		gotIDs = append(gotIDs, tree.TreeId)
+	}
+	if want := []int64{deleted.TreeId}; !reflect.DeepEqual(gotIDs, want) {
+		t.Errorf("ListHardDeletableTrees() IDs = %v, want = %v", gotIDs, want)
+	}
+}
+
 // TestHardDeleteTree tests success scenarios of HardDeleteTree.
 func (tester *AdminStorageTester) TestHardDeleteTree(t *testing.T) {
 	ctx := context.Background()
@@ -563,7 +4458,16 @@ func (tester *AdminStorageTester) TestHardDeleteTreeErrors(t *testing.T) {
 	s := tester.NewAdminStorage()
 
 	activeTree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	frozenTree := makeTreeOrFail(ctx, s, spec{Tree: LogTree, Frozen: true}, t.Fatalf)
+	drainingTree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	drainingTree, _, err := updateTree(ctx, s, drainingTree.TreeId, func(tree *trillian.Tree) {
+		tree.TreeState = trillian.TreeState_DRAINING
+	})
+	if err != nil {
+		t.Fatalf("UpdateTree() = (_, %v), want = (_, nil)", err)
+	}
 
+	// Only soft-deleted trees may be hard-deleted, regardless of their state.
 	tests := []struct {
 		desc     string
 		treeID   int64
@@ -571,12 +4475,19 @@ func (tester *AdminStorageTester) TestHardDeleteTreeErrors(t *testing.T) {
 	}{
 		{desc: "unknownTree", treeID: 12345, wantCode: errors.NotFound},
 		{desc: "activeTree", treeID: activeTree.TreeId, wantCode: errors.FailedPrecondition},
+		{desc: "frozenTree", treeID: frozenTree.TreeId, wantCode: errors.FailedPrecondition},
+		{desc: "drainingTree", treeID: drainingTree.TreeId, wantCode: errors.FailedPrecondition},
 	}
 	for _, test := range tests {
 		if err := hardDeleteTree(ctx, s, test.treeID); errors.ErrorCode(err) != test.wantCode {
 			t.Errorf("%v: hardDeleteTree() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
 		}
 	}
+	for _, tree := range []*trillian.Tree{activeTree, frozenTree, drainingTree} {
+		if err := assertStoredTree(ctx, s, tree); err != nil {
+			t.Errorf("tree modified by failed hardDeleteTree(): %v", err)
+		}
+	}
 }
 
 func hardDeleteTree(ctx context.Context, s storage.AdminStorage, treeID int64) error {
@@ -616,6 +4527,7 @@ func (tester *AdminStorageTester) TestUndeleteTree(t *testing.T) {
 		want := proto.Clone(test.tree).(*trillian.Tree)
 		want.Deleted = false
 		want.DeleteTime = nil
+		want.Revision++
 		if got := tree; !proto.Equal(got, want) {
 			t.Errorf("%v: post-undeleteTree diff (-got +want):\n%v", test.desc, pretty.Compare(got, want))
 		}
@@ -648,6 +4560,77 @@ func (tester *AdminStorageTester) TestUndeleteTreeErrors(t *testing.T) {
 	}
 }
 
+// TestInvalidTreeIDs tests that operations on zero or negative tree IDs
+// return InvalidArgument, rather than NotFound.
+func (tester *AdminStorageTester) TestInvalidTreeIDs(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	ops := []struct {
+		desc string
+		fn   func(treeID int64) error
+	}{
+		{desc: "GetTree", fn: func(treeID int64) error {
+			_, err := getTree(ctx, s, treeID)
+			return err
+		}},
+		{desc: "UpdateTree", fn: func(treeID int64) error {
+			_, _, err := updateTree(ctx, s, treeID, func(*trillian.Tree) {})
+			return err
+		}},
+		{desc: "SoftDeleteTree", fn: func(treeID int64) error {
+			_, err := softDeleteTree(ctx, s, treeID)
+			return err
+		}},
+		{desc: "UndeleteTree", fn: func(treeID int64) error {
+			_, err := undeleteTree(ctx, s, treeID)
+			return err
+		}},
+		{desc: "HardDeleteTree", fn: func(treeID int64) error {
+			return hardDeleteTree(ctx, s, treeID)
+		}},
+	}
+	for _, op := range ops {
+		for _, treeID := range []int64{0, -1} {
+			if err := op.fn(treeID); errors.ErrorCode(err) != errors.InvalidArgument {
+				t.Errorf("%v(%v) returned err = %v, wantCode = %s", op.desc, treeID, err, errors.InvalidArgument)
+			}
+		}
+	}
+}
+
+// TestConcurrentDeleteUndelete tests that concurrent soft deletes and
+// undeletes of the same tree leave it in a consistent state.
+func (tester *AdminStorageTester) TestConcurrentDeleteUndelete(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+	for i := 0; i < 10; i++ {
+		// Either operation may fail, depending on which one runs first, so
+		// errors are ignored. Only the resulting state matters.
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			softDeleteTree(ctx, s, tree.TreeId)
+		}()
+		go func() {
+			defer wg.Done()
+			undeleteTree(ctx, s, tree.TreeId)
+		}()
+		wg.Wait()
+
+		got, err := getTree(ctx, s, tree.TreeId)
+		if err != nil {
+			t.Fatalf("%v: getTree() returned err = %v", i, err)
+		}
+		if got.Deleted != (got.DeleteTime != nil) {
+			t.Errorf("%v: getTree() returned inconsistent tree: Deleted = %v, DeleteTime = %v", i, got.Deleted, got.DeleteTime)
+		}
+	}
+}
+
 func undeleteTree(ctx context.Context, s storage.AdminStorage, treeID int64) (*trillian.Tree, error) {
 	tx, err := s.Begin(ctx)
 	if err != nil {
@@ -726,6 +4709,52 @@ func (tester *AdminStorageTester) TestAdminTXClose(t *testing.T) {
 	}
 }
 
+// TestOperationsAfterCommit verifies that a committed transaction may not be reused.
+func (tester *AdminStorageTester) TestOperationsAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	s := tester.NewAdminStorage()
+
+	tree := makeTreeOrFail(ctx, s, spec{Tree: LogTree}, t.Fatalf)
+
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() = (_, %v), want = (_, nil)", err)
+	}
+	defer tx.Close()
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() = %v, want = nil", err)
+	}
+
+	tests := []struct {
+		desc string
+		fn   func() error
+	}{
+		{
+			desc: "CreateTree",
+			fn: func() error {
+				_, err := tx.CreateTree(ctx, LogTree)
+				return err
+			},
+		},
+		{
+			desc: "GetTree",
+			fn: func() error {
+				_, err := tx.GetTree(ctx, tree.TreeId)
+				return err
+			},
+		},
+		{
+			desc: "Commit",
+			fn:   tx.Commit,
+		},
+	}
+	for _, test := range tests {
+		if err := test.fn(); errors.ErrorCode(err) != errors.FailedPrecondition {
+			t.Errorf("%v() after Commit() returned err = %v, wantCode = %s", test.desc, err, errors.FailedPrecondition)
+		}
+	}
+}
+
 // assertStoredTree verifies that "want" is equal to the tree stored under its ID.
 func assertStoredTree(ctx context.Context, s storage.AdminStorage, want *trillian.Tree) error {
 	got, err := getTree(ctx, s, want.TreeId)
@@ -806,6 +4835,38 @@ func createTree(ctx context.Context, s storage.AdminStorage, tree *trillian.Tree
 	return newTree, nil
 }
 
+func createTreeWithAlias(ctx context.Context, s storage.AdminStorage, tree *trillian.Tree, alias string) (*trillian.Tree, error) {
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	newTree, err := tx.CreateTreeWithAlias(ctx, tree, alias)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return newTree, nil
+}
+
+func reserveTreeID(ctx context.Context, s storage.AdminStorage) (int64, error) {
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Close()
+	id, err := tx.ReserveTreeID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
 // updateTree updates the specified tree.
 // The bool return signifies whether the error was returned by the UpdateTree() call.
 func updateTree(ctx context.Context, s storage.AdminStorage, treeID int64, updateFunc func(*trillian.Tree)) (*trillian.Tree, bool, error) {
@@ -824,6 +4885,78 @@ func updateTree(ctx context.Context, s storage.AdminStorage, treeID int64, updat
 	return newTree, false, nil
 }
 
+func replaceTree(ctx context.Context, s storage.AdminStorage, desired *trillian.Tree, expectedRevision int64) (*trillian.Tree, error) {
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	tree, err := tx.ReplaceTree(ctx, desired, expectedRevision)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func transferLabels(ctx context.Context, s storage.AdminStorage, fromTreeID, toTreeID int64, labels []string) error {
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := tx.TransferLabels(ctx, fromTreeID, toTreeID, labels); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func setTreeLabels(ctx context.Context, s storage.AdminStorage, treeID int64, labels []string) (*trillian.Tree, error) {
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	tree, err := tx.SetTreeLabels(ctx, treeID, labels)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func setTreeAlias(ctx context.Context, s storage.AdminStorage, alias string, treeID int64) error {
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if err := tx.SetTreeAlias(ctx, alias, treeID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func resolveTreeAlias(ctx context.Context, s storage.AdminStorage, alias string) (int64, error) {
+	tx, err := s.Snapshot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Close()
+	id, err := tx.ResolveTreeAlias(ctx, alias)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
 func getTree(ctx context.Context, s storage.AdminStorage, treeID int64) (*trillian.Tree, error) {
 	tx, err := s.Snapshot(ctx)
 	if err != nil {
@@ -852,3 +4985,16 @@ func softDeleteTree(ctx context.Context, s storage.AdminStorage, treeID int64) (
 	}
 	return tree, tx.Commit()
 }
+
+func softDeleteTreeIfUnchanged(ctx context.Context, s storage.AdminStorage, treeID, expectedRevision int64) (*trillian.Tree, error) {
+	tx, err := s.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	tree, err := tx.SoftDeleteTreeIfUnchanged(ctx, treeID, expectedRevision)
+	if err != nil {
+		return nil, err
+	}
+	return tree, tx.Commit()
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testonly
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+	"github.com/google/trillian/storage"
+)
+
+// LogStorageTester runs a suite of tests against LogStorage implementations.
+type LogStorageTester struct {
+	// NewStorage returns an AdminStorage and a LogStorage instance sharing a
+	// clean test database.
+	NewStorage func() (storage.AdminStorage, storage.LogStorage)
+}
+
+// RunAllTests runs all LogStorage tests.
+func (tester *LogStorageTester) RunAllTests(t *testing.T) {
+	t.Run("TestQueueLeavesPreorderedLog", tester.TestQueueLeavesPreorderedLog)
+}
+
+// TestQueueLeavesPreorderedLog tests that QueueLeaves is refused on
+// PREORDERED_LOG trees, while LOG trees keep accepting queued leaves.
+func (tester *LogStorageTester) TestQueueLeavesPreorderedLog(t *testing.T) {
+	ctx := context.Background()
+	adminStorage, logStorage := tester.NewStorage()
+
+	preorderedTree := proto.Clone(LogTree).(*trillian.Tree)
+	preorderedTree.TreeType = trillian.TreeType_PREORDERED_LOG
+
+	tests := []struct {
+		desc     string
+		tree     *trillian.Tree
+		wantCode errors.Code
+	}{
+		{desc: "log", tree: LogTree, wantCode: errors.OK},
+		{desc: "preorderedLog", tree: preorderedTree, wantCode: errors.FailedPrecondition},
+	}
+	for _, test := range tests {
+		tree, err := createTree(ctx, adminStorage, test.tree)
+		if err != nil {
+			t.Fatalf("%v: createTree() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+
+		tx, err := logStorage.BeginForTree(ctx, tree.TreeId)
+		if err != nil {
+			t.Fatalf("%v: BeginForTree() = (_, %v), want = (_, nil)", test.desc, err)
+		}
+		hash := sha256.Sum256([]byte("leaf"))
+		leaf := &trillian.LogLeaf{LeafIdentityHash: hash[:], MerkleLeafHash: hash[:], LeafValue: []byte("leaf")}
+		_, err = tx.QueueLeaves(ctx, []*trillian.LogLeaf{leaf}, time.Now())
+		tx.Close()
+		if got := errors.ErrorCode(err); got != test.wantCode {
+			t.Errorf("%v: QueueLeaves() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package testonly
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sort"
@@ -44,6 +45,23 @@ func NodeIDEq(n storage.NodeID) gomock.Matcher {
 	return nodeIDEq{n}
 }
 
+type privateKeyAccessCtx struct{}
+
+func (privateKeyAccessCtx) Matches(x interface{}) bool {
+	ctx, ok := x.(context.Context)
+	return ok && storage.HasPrivateKeyAccess(ctx)
+}
+
+func (privateKeyAccessCtx) String() string {
+	return "is a context with private key access"
+}
+
+// PrivateKeyAccessContext returns a matcher that expects a context marked with
+// storage.WithPrivateKeyAccess.
+func PrivateKeyAccessContext() gomock.Matcher {
+	return privateKeyAccessCtx{}
+}
+
 // We need a stable order to match the mock expectations so we sort them by
 // prefix len before passing them to the mock library. Might need extending
 // if we have more complex tests.
@@ -0,0 +1,60 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testonly
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+)
+
+// NewUniqueTree returns a valid tree of the specified type, as per LogTree
+// and MapTree, but with a freshly generated ECDSA key pair.
+// It's meant for tests that need many trees with distinct keys.
+// Panics if treeType isn't LOG or MAP, or if key generation fails.
+func NewUniqueTree(treeType trillian.TreeType) *trillian.Tree {
+	var tree *trillian.Tree
+	switch treeType {
+	case trillian.TreeType_LOG:
+		tree = proto.Clone(LogTree).(*trillian.Tree)
+	case trillian.TreeType_MAP:
+		tree = proto.Clone(MapTree).(*trillian.Tree)
+	default:
+		panic(fmt.Sprintf("unsupported tree type: %s", treeType))
+	}
+
+	privateKey, err := der.NewProtoFromSpec(&keyspb.Specification{
+		Params: &keyspb.Specification_EcdsaParams{
+			EcdsaParams: &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P256},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	signer, err := der.FromProto(privateKey)
+	if err != nil {
+		panic(err)
+	}
+	publicKey, err := der.ToPublicProto(signer.Public())
+	if err != nil {
+		panic(err)
+	}
+	tree.PrivateKey = mustMarshalAny(privateKey)
+	tree.PublicKey = publicKey
+	return tree
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testonly
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/storage"
+
+	_ "github.com/google/trillian/crypto/keys/der/proto"
+)
+
+func TestNewUniqueTree(t *testing.T) {
+	ctx := context.Background()
+	for _, treeType := range []trillian.TreeType{trillian.TreeType_LOG, trillian.TreeType_MAP} {
+		tree1 := NewUniqueTree(treeType)
+		tree2 := NewUniqueTree(treeType)
+		for _, tree := range []*trillian.Tree{tree1, tree2} {
+			if tree.TreeType != treeType {
+				t.Errorf("%s: NewUniqueTree().TreeType = %s", treeType, tree.TreeType)
+			}
+			if err := storage.ValidateTreeForCreation(ctx, storage.AdminStorageOptions{}, tree); err != nil {
+				t.Errorf("%s: ValidateTreeForCreation() = %v, want = nil", treeType, err)
+			}
+		}
+		if bytes.Equal(tree1.PublicKey.Der, tree2.PublicKey.Der) {
+			t.Errorf("%s: NewUniqueTree() returned trees with the same public key", treeType)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// CreateTreeWithAlias implements AdminWriter.CreateTreeWithAlias for storage
+// implementations, on top of tx.CreateTree.
+// addAlias must point alias to treeID if alias is free, and return an
+// AlreadyExists error otherwise, as a single atomic operation; unlike
+// SetTreeAlias, it must never repoint an alias that's in use.
+func CreateTreeWithAlias(ctx context.Context, tx AdminTX, tree *trillian.Tree, alias string, addAlias func(ctx context.Context, alias string, treeID int64) error) (*trillian.Tree, error) {
+	if alias == "" {
+		return nil, errors.New(errors.InvalidArgument, "empty tree alias")
+	}
+	// Fail fast on taken aliases, so no tree is created only to be rolled
+	// back. Concurrent transactions may still race for alias after this
+	// check, addAlias is what guarantees uniqueness.
+	switch id, err := tx.ResolveTreeAlias(ctx, alias); {
+	case errors.ErrorCode(err) == errors.NotFound:
+		// Alias is free.
+	case err != nil:
+		return nil, err
+	default:
+		return nil, errors.Errorf(errors.AlreadyExists, "alias %q already points to tree %v", alias, id)
+	}
+	created, err := tx.CreateTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	if err := addAlias(ctx, alias, created.TreeId); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
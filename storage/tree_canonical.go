@@ -0,0 +1,215 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+const (
+	// CanonicalTreeBytesV1 is the first version of the canonical tree
+	// encoding.
+	CanonicalTreeBytesV1 = 1
+	// CanonicalTreeBytesV2 extends V1 with the fields added to trees since:
+	// labels, app_data, writes_disabled, signature_params, sequencing_paused,
+	// external_ref, preferred_read_consistency and cloned_from.
+	CanonicalTreeBytesV2 = 2
+)
+
+// TreeConfigHashVersion is the canonical encoding version used by
+// TreeConfigHash. Changing it changes the hash of every tree.
+const TreeConfigHashVersion = CanonicalTreeBytesV2
+
+// canonicalField writes a single tree field to a canonical encoding.
+type canonicalField struct {
+	// tag identifies the field in the encoding. Tags match the trillian.Tree
+	// proto field numbers, but that's not a requirement.
+	tag   uint32
+	value func(*trillian.Tree) []byte
+}
+
+// canonicalTreeFields lists, per version, the fields encoded by
+// CanonicalTreeBytes, in encoding order.
+// Storage-managed fields (tree_id, timestamps, deletion status and revision)
+// are not part of a tree's config, thus not encoded.
+var canonicalTreeFields = map[int][]canonicalField{
+	CanonicalTreeBytesV1: canonicalTreeFieldsV1,
+	CanonicalTreeBytesV2: canonicalTreeFieldsV2,
+}
+
+// canonicalTreeFieldsV1 are the fields encoded by CanonicalTreeBytesV1.
+var canonicalTreeFieldsV1 = []canonicalField{
+	{tag: 2, value: func(t *trillian.Tree) []byte { return int32Bytes(int32(t.TreeState)) }},
+	{tag: 3, value: func(t *trillian.Tree) []byte { return int32Bytes(int32(t.TreeType)) }},
+	{tag: 4, value: func(t *trillian.Tree) []byte { return int32Bytes(int32(t.HashStrategy)) }},
+	{tag: 5, value: func(t *trillian.Tree) []byte { return int32Bytes(int32(t.HashAlgorithm)) }},
+	{tag: 6, value: func(t *trillian.Tree) []byte { return int32Bytes(int32(t.SignatureAlgorithm)) }},
+	{tag: 8, value: func(t *trillian.Tree) []byte { return []byte(t.DisplayName) }},
+	{tag: 9, value: func(t *trillian.Tree) []byte { return []byte(t.Description) }},
+	{tag: 12, value: func(t *trillian.Tree) []byte {
+		return concatBytes([]byte(t.GetPrivateKey().GetTypeUrl()), t.GetPrivateKey().GetValue())
+	}},
+	{tag: 13, value: func(t *trillian.Tree) []byte {
+		return concatBytes([]byte(t.GetStorageSettings().GetTypeUrl()), t.GetStorageSettings().GetValue())
+	}},
+	{tag: 14, value: func(t *trillian.Tree) []byte { return t.GetPublicKey().GetDer() }},
+	{tag: 15, value: func(t *trillian.Tree) []byte {
+		d := t.GetMaxRootDuration()
+		return append(int64Bytes(d.GetSeconds()), int32Bytes(d.GetNanos())...)
+	}},
+	{tag: 18, value: func(t *trillian.Tree) []byte { return int32Bytes(int32(t.SignatureCipherSuite)) }},
+}
+
+// canonicalTreeFieldsV2 are the fields encoded by CanonicalTreeBytesV2.
+// Labels and app_data are encoded in sorted order, so the encoding depends
+// neither on input order nor on map iteration order.
+var canonicalTreeFieldsV2 = append(append([]canonicalField(nil), canonicalTreeFieldsV1...), []canonicalField{
+	{tag: 21, value: func(t *trillian.Tree) []byte {
+		labels := append([]string(nil), t.Labels...)
+		sort.Strings(labels)
+		return concatStrings(labels...)
+	}},
+	{tag: 22, value: func(t *trillian.Tree) []byte {
+		keys := make([]string, 0, len(t.AppData))
+		for k := range t.AppData {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		kvs := make([]string, 0, 2*len(keys))
+		for _, k := range keys {
+			kvs = append(kvs, k, t.AppData[k])
+		}
+		return concatStrings(kvs...)
+	}},
+	{tag: 23, value: func(t *trillian.Tree) []byte { return boolBytes(t.WritesDisabled) }},
+	// Unset and default signature params are stored identically.
+	{tag: 25, value: func(t *trillian.Tree) []byte { return int32Bytes(int32(t.GetSignatureParams().GetRsaPadding())) }},
+	{tag: 26, value: func(t *trillian.Tree) []byte { return boolBytes(t.SequencingPaused) }},
+	{tag: 27, value: func(t *trillian.Tree) []byte { return []byte(t.ExternalRef) }},
+	{tag: 28, value: func(t *trillian.Tree) []byte { return int32Bytes(int32(t.PreferredReadConsistency)) }},
+	{tag: 29, value: func(t *trillian.Tree) []byte { return int64Bytes(t.ClonedFrom) }},
+}...)
+
+// CanonicalTreeBytes returns a stable byte encoding of tree's config, as
+// specified by version.
+// The encoding doesn't depend on proto marshaling, which is not guaranteed
+// to be deterministic. Instead, it's composed of a version prefix followed
+// by each field, in a fixed order, as a (tag, length, value) triplet.
+// Once a version is published its encoding never changes; adding fields to
+// the encoding requires a new version.
+func CanonicalTreeBytes(tree *trillian.Tree, version int) ([]byte, error) {
+	if tree == nil {
+		return nil, errors.New(errors.InvalidArgument, "a tree is required")
+	}
+	fields, ok := canonicalTreeFields[version]
+	if !ok {
+		return nil, errors.Errorf(errors.InvalidArgument, "unknown canonical tree encoding version: %v", version)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.Write(uint32Bytes(uint32(version)))
+	for _, f := range fields {
+		value := f.value(tree)
+		buf.Write(uint32Bytes(f.tag))
+		buf.Write(uint32Bytes(uint32(len(value))))
+		buf.Write(value)
+	}
+	return buf.Bytes(), nil
+}
+
+// TreeConfigHash returns the SHA-256 hash of tree's config, as encoded by
+// CanonicalTreeBytes using TreeConfigHashVersion.
+func TreeConfigHash(tree *trillian.Tree) ([]byte, error) {
+	return TreeConfigHashWithVersion(tree, TreeConfigHashVersion)
+}
+
+// TreeConfigHashWithVersion is like TreeConfigHash, but uses the specified
+// canonical encoding version. It's meant for verifying hashes computed before
+// TreeConfigHashVersion changed.
+func TreeConfigHashWithVersion(tree *trillian.Tree, version int) ([]byte, error) {
+	b, err := CanonicalTreeBytes(tree, version)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(b)
+	return hash[:], nil
+}
+
+// CanonicalizeTree sorts tree's repeated fields in their canonical order, so
+// that trees differing only in input order are stored identically. Labels are
+// sorted lexicographically. They're not deduplicated: tree validation rejects
+// duplicate labels, so callers find out about them rather than having them
+// silently dropped.
+// Fields are replaced rather than sorted in place, as they may be shared with
+// the caller.
+// It's meant to be called by AdminStorage implementations before persisting
+// trees.
+func CanonicalizeTree(tree *trillian.Tree) {
+	if len(tree.Labels) == 0 {
+		return
+	}
+	labels := append([]string(nil), tree.Labels...)
+	sort.Strings(labels)
+	tree.Labels = labels
+}
+
+func uint32Bytes(i uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, i)
+	return b
+}
+
+func int32Bytes(i int32) []byte {
+	return uint32Bytes(uint32(i))
+}
+
+func int64Bytes(i int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(i))
+	return b
+}
+
+func boolBytes(b bool) []byte {
+	if b {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// concatStrings is like concatBytes, but for strings.
+func concatStrings(values ...string) []byte {
+	b := make([][]byte, 0, len(values))
+	for _, v := range values {
+		b = append(b, []byte(v))
+	}
+	return concatBytes(b...)
+}
+
+// concatBytes length-prefixes and concatenates values, so that the
+// boundaries between values are unambiguous.
+func concatBytes(values ...[]byte) []byte {
+	buf := &bytes.Buffer{}
+	for _, v := range values {
+		buf.Write(uint32Bytes(uint32(len(v))))
+		buf.Write(v)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,268 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/errors"
+)
+
+// goldenTreeBytesV1 is the version 1 encoding of goldenTree().
+// It must never change.
+const goldenTreeBytesV1 = "00000001" + goldenTreeFieldsV1
+
+// goldenTreeBytesV2 is the version 2 encoding of goldenTree().
+// It must never change.
+const goldenTreeBytesV2 = "00000002" + goldenTreeFieldsV1 +
+	"00000015" + "0000000a" + "00000001" + "61" + "00000001" + "62" + // labels
+	"00000016" + "0000000a" + "00000001" + "6b" + "00000001" + "76" + // app_data
+	"00000017" + "00000001" + "01" + // writes_disabled
+	"00000019" + "00000004" + "00000001" + // signature_params
+	"0000001a" + "00000001" + "01" + // sequencing_paused
+	"0000001b" + "00000003" + "726566" + // external_ref
+	"0000001c" + "00000004" + "00000002" + // preferred_read_consistency
+	"0000001d" + "00000008" + "0000000000000007" // cloned_from
+
+// goldenTreeFieldsV1 is the encoding of the version 1 fields of goldenTree(),
+// without the version prefix.
+const goldenTreeFieldsV1 = "" +
+	"00000002" + "00000004" + "00000001" + // tree_state
+	"00000003" + "00000004" + "00000001" + // tree_type
+	"00000004" + "00000004" + "00000001" + // hash_strategy
+	"00000005" + "00000004" + "00000004" + // hash_algorithm
+	"00000006" + "00000004" + "00000003" + // signature_algorithm
+	"00000008" + "00000004" + "4c6f6773" + // display_name
+	"00000009" + "0000000c" + "4c6f6773206f66206c6f6773" + // description
+	"0000000c" + "0000000e" + "00000003" + "666f6f" + "00000003" + "626172" + // private_key
+	"0000000d" + "00000008" + "00000000" + "00000000" + // storage_settings
+	"0000000e" + "00000003" + "010203" + // public_key
+	"0000000f" + "0000000c" + "000000000000003c" + "00000000" + // max_root_duration
+	"00000012" + "00000004" + "00000000" // signature_cipher_suite
+
+// goldenTree returns a tree whose encodings are fixed by goldenTreeBytesV1
+// and goldenTreeBytesV2.
+func goldenTree() *trillian.Tree {
+	return &trillian.Tree{
+		TreeId:             12345,
+		TreeState:          trillian.TreeState_ACTIVE,
+		TreeType:           trillian.TreeType_LOG,
+		HashStrategy:       trillian.HashStrategy_RFC6962_SHA256,
+		HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+		SignatureAlgorithm: sigpb.DigitallySigned_ECDSA,
+		DisplayName:        "Logs",
+		Description:        "Logs of logs",
+		PrivateKey:         &any.Any{TypeUrl: "foo", Value: []byte("bar")},
+		PublicKey:          &keyspb.PublicKey{Der: []byte{1, 2, 3}},
+		MaxRootDuration:    &duration.Duration{Seconds: 60},
+		CreateTime:         &timestamp.Timestamp{Seconds: 1000},
+		UpdateTime:         &timestamp.Timestamp{Seconds: 2000},
+		// Unsorted, to check that the encoding sorts labels.
+		Labels:                   []string{"b", "a"},
+		AppData:                  map[string]string{"k": "v"},
+		WritesDisabled:           true,
+		SignatureParams:          &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PSS},
+		SequencingPaused:         true,
+		ExternalRef:              "ref",
+		PreferredReadConsistency: trillian.ReadConsistency_EVENTUAL,
+		ClonedFrom:               7,
+	}
+}
+
+func TestCanonicalTreeBytes_Golden(t *testing.T) {
+	for _, test := range []struct {
+		version int
+		want    string
+	}{
+		{version: CanonicalTreeBytesV1, want: goldenTreeBytesV1},
+		{version: CanonicalTreeBytesV2, want: goldenTreeBytesV2},
+	} {
+		got, err := CanonicalTreeBytes(goldenTree(), test.version)
+		if err != nil {
+			t.Errorf("CanonicalTreeBytes(v%v) = (_, %v), want = (_, nil)", test.version, err)
+			continue
+		}
+		if got, want := hex.EncodeToString(got), test.want; got != want {
+			t.Errorf("CanonicalTreeBytes(v%v) = %v, want = %v", test.version, got, want)
+		}
+	}
+}
+
+// TestCanonicalTreeBytes_V2Fields checks that every field added by V2 changes
+// the V2 encoding, but not the V1 encoding.
+func TestCanonicalTreeBytes_V2Fields(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		update func(*trillian.Tree)
+	}{
+		{desc: "labels", update: func(tree *trillian.Tree) { tree.Labels = []string{"a", "c"} }},
+		{desc: "appData", update: func(tree *trillian.Tree) { tree.AppData["k"] = "w" }},
+		{desc: "writesDisabled", update: func(tree *trillian.Tree) { tree.WritesDisabled = false }},
+		{desc: "signatureParams", update: func(tree *trillian.Tree) { tree.SignatureParams = nil }},
+		{desc: "sequencingPaused", update: func(tree *trillian.Tree) { tree.SequencingPaused = false }},
+		{desc: "externalRef", update: func(tree *trillian.Tree) { tree.ExternalRef = "other" }},
+		{desc: "preferredReadConsistency", update: func(tree *trillian.Tree) { tree.PreferredReadConsistency = trillian.ReadConsistency_STRONG }},
+		{desc: "clonedFrom", update: func(tree *trillian.Tree) { tree.ClonedFrom = 8 }},
+	} {
+		tree := goldenTree()
+		test.update(tree)
+		v1, err := CanonicalTreeBytes(tree, CanonicalTreeBytesV1)
+		if err != nil {
+			t.Errorf("%v: CanonicalTreeBytes(v1) = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if got, want := hex.EncodeToString(v1), goldenTreeBytesV1; got != want {
+			t.Errorf("%v: CanonicalTreeBytes(v1) = %v, want = %v", test.desc, got, want)
+		}
+		v2, err := CanonicalTreeBytes(tree, CanonicalTreeBytesV2)
+		if err != nil {
+			t.Errorf("%v: CanonicalTreeBytes(v2) = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if got := hex.EncodeToString(v2); got == goldenTreeBytesV2 {
+			t.Errorf("%v: CanonicalTreeBytes(v2) didn't change", test.desc)
+		}
+	}
+}
+
+func TestCanonicalTreeBytes_Stable(t *testing.T) {
+	tree := newTree()
+	want, err := CanonicalTreeBytes(tree, CanonicalTreeBytesV1)
+	if err != nil {
+		t.Fatalf("CanonicalTreeBytes() = (_, %v), want = (_, nil)", err)
+	}
+
+	// Round-trip the tree through proto marshaling, which doesn't guarantee
+	// deterministic output.
+	b, err := proto.Marshal(tree)
+	if err != nil {
+		t.Fatalf("proto.Marshal() = (_, %v), want = (_, nil)", err)
+	}
+	unmarshaled := &trillian.Tree{}
+	if err := proto.Unmarshal(b, unmarshaled); err != nil {
+		t.Fatalf("proto.Unmarshal() = %v, want = nil", err)
+	}
+
+	// Storage-managed fields are not part of the config.
+	storageManaged := proto.Clone(tree).(*trillian.Tree)
+	storageManaged.TreeId = 12345
+	storageManaged.CreateTime = ptypes.TimestampNow()
+	storageManaged.UpdateTime = ptypes.TimestampNow()
+	storageManaged.Deleted = true
+	storageManaged.DeleteTime = ptypes.TimestampNow()
+
+	for _, test := range []struct {
+		desc string
+		tree *trillian.Tree
+	}{
+		{desc: "clone", tree: proto.Clone(tree).(*trillian.Tree)},
+		{desc: "unmarshaled", tree: unmarshaled},
+		{desc: "storageManaged", tree: storageManaged},
+	} {
+		got, err := CanonicalTreeBytes(test.tree, CanonicalTreeBytesV1)
+		if err != nil {
+			t.Errorf("%v: CanonicalTreeBytes() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%v: CanonicalTreeBytes() = %x, want = %x", test.desc, got, want)
+		}
+	}
+
+	changed := proto.Clone(tree).(*trillian.Tree)
+	changed.DisplayName = "Changed"
+	got, err := CanonicalTreeBytes(changed, CanonicalTreeBytesV1)
+	if err != nil {
+		t.Fatalf("CanonicalTreeBytes() = (_, %v), want = (_, nil)", err)
+	}
+	if bytes.Equal(got, want) {
+		t.Errorf("CanonicalTreeBytes() didn't change after a config change")
+	}
+}
+
+func TestCanonicalTreeBytes_Versions(t *testing.T) {
+	// Pretend a newer version exists, with the same fields as the first.
+	const v2 = CanonicalTreeBytesV2 + 1
+	canonicalTreeFields[v2] = canonicalTreeFields[CanonicalTreeBytesV1]
+	defer delete(canonicalTreeFields, v2)
+
+	tree := goldenTree()
+	b1, err := CanonicalTreeBytes(tree, CanonicalTreeBytesV1)
+	if err != nil {
+		t.Fatalf("CanonicalTreeBytes(v1) = (_, %v), want = (_, nil)", err)
+	}
+	b2, err := CanonicalTreeBytes(tree, v2)
+	if err != nil {
+		t.Fatalf("CanonicalTreeBytes(v2) = (_, %v), want = (_, nil)", err)
+	}
+	if bytes.Equal(b1, b2) {
+		t.Errorf("CanonicalTreeBytes(v1) = CanonicalTreeBytes(v2) = %x, want different encodings", b1)
+	}
+	if got, want := hex.EncodeToString(b1), goldenTreeBytesV1; got != want {
+		t.Errorf("CanonicalTreeBytes(v1) = %v, want = %v", got, want)
+	}
+
+	if _, err := CanonicalTreeBytes(tree, 0); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("CanonicalTreeBytes(0) = (_, %v), wantCode = %s", err, errors.InvalidArgument)
+	}
+	if _, err := CanonicalTreeBytes(nil, CanonicalTreeBytesV1); errors.ErrorCode(err) != errors.InvalidArgument {
+		t.Errorf("CanonicalTreeBytes(nil) = (_, %v), wantCode = %s", err, errors.InvalidArgument)
+	}
+}
+
+func TestTreeConfigHash(t *testing.T) {
+	tree := goldenTree()
+	hash1, err := TreeConfigHash(tree)
+	if err != nil {
+		t.Fatalf("TreeConfigHash() = (_, %v), want = (_, nil)", err)
+	}
+	golden, err := hex.DecodeString(goldenTreeBytesV2)
+	if err != nil {
+		t.Fatalf("hex.DecodeString() = (_, %v), want = (_, nil)", err)
+	}
+	if want := sha256.Sum256(golden); !bytes.Equal(hash1, want[:]) {
+		t.Errorf("TreeConfigHash() = %x, want = %x (SHA-256 of goldenTreeBytesV2)", hash1, want)
+	}
+
+	other := proto.Clone(tree).(*trillian.Tree)
+	other.TreeId++
+	hash2, err := TreeConfigHash(other)
+	if err != nil {
+		t.Fatalf("TreeConfigHash() = (_, %v), want = (_, nil)", err)
+	}
+	if !bytes.Equal(hash1, hash2) {
+		t.Errorf("TreeConfigHash() differs for trees with the same config: %x != %x", hash1, hash2)
+	}
+
+	other.Description = "Changed"
+	hash3, err := TreeConfigHash(other)
+	if err != nil {
+		t.Fatalf("TreeConfigHash() = (_, %v), want = (_, nil)", err)
+	}
+	if bytes.Equal(hash1, hash3) {
+		t.Errorf("TreeConfigHash() didn't change after a config change")
+	}
+}
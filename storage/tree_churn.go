@@ -0,0 +1,68 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+)
+
+// TreeChurn returns how many trees in adminStorage were created and how many
+// were soft-deleted within [now-window, now), according to their CreateTime
+// and DeleteTime.
+// Deleted trees count towards created if their CreateTime is in the window.
+// Hard-deleted trees are no longer in storage, thus aren't counted.
+func TreeChurn(ctx context.Context, adminStorage AdminStorage, window time.Duration, now time.Time) (created int64, deleted int64, err error) {
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Close()
+	trees, err := tx.ListTrees(ctx, true /* includeDeleted */)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	start := now.Add(-window)
+	inWindow := func(t time.Time) bool {
+		return !t.Before(start) && t.Before(now)
+	}
+	for _, tree := range trees {
+		createTime, err := ptypes.Timestamp(tree.CreateTime)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error parsing create_time of tree %v: %v", tree.TreeId, err)
+		}
+		if inWindow(createTime) {
+			created++
+		}
+		if !tree.Deleted {
+			continue
+		}
+		deleteTime, err := ptypes.Timestamp(tree.DeleteTime)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error parsing delete_time of tree %v: %v", tree.TreeId, err)
+		}
+		if inWindow(deleteTime) {
+			deleted++
+		}
+	}
+	return created, deleted, nil
+}
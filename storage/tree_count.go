@@ -0,0 +1,61 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// CountSnapshot is the number of trees in storage at a point in time.
+type CountSnapshot struct {
+	// Time is when the snapshot was taken.
+	Time time.Time
+
+	// Active is the number of trees that weren't soft-deleted.
+	Active int64
+
+	// Deleted is the number of soft-deleted trees.
+	Deleted int64
+}
+
+// RecordTreeCountSnapshot counts the active and deleted trees in
+// adminStorage and records the result, timestamped by opts.TimeSource, as per
+// AdminWriter.WriteTreeCountSnapshot.
+func RecordTreeCountSnapshot(ctx context.Context, adminStorage AdminStorage, opts AdminStorageOptions) error {
+	tx, err := adminStorage.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+
+	all, err := tx.ListTreeIDs(ctx, true /* includeDeleted */)
+	if err != nil {
+		return err
+	}
+	active, err := tx.ListTreeIDs(ctx, false /* includeDeleted */)
+	if err != nil {
+		return err
+	}
+	snapshot := &CountSnapshot{
+		Time:    TimeSourceFor(opts).Now(),
+		Active:  int64(len(active)),
+		Deleted: int64(len(all) - len(active)),
+	}
+	if err := tx.WriteTreeCountSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+)
+
+// FieldChange describes a tree config field that differs between two trees.
+type FieldChange struct {
+	// Field is the trillian.Tree proto field name, e.g. "display_name".
+	Field string
+	// Old and New are human-readable representations of the field's values.
+	Old, New string
+}
+
+// diffField renders a single tree field for TreeConfigDiff.
+type diffField struct {
+	name  string
+	value func(*trillian.Tree) string
+}
+
+// treeDiffFields lists the fields compared by TreeConfigDiff, in proto field
+// number order.
+// Storage-managed fields (tree_id, timestamps and deletion status) are not
+// part of a tree's config, thus not compared.
+var treeDiffFields = []diffField{
+	{name: "tree_state", value: func(t *trillian.Tree) string { return t.GetTreeState().String() }},
+	{name: "tree_type", value: func(t *trillian.Tree) string { return t.GetTreeType().String() }},
+	{name: "hash_strategy", value: func(t *trillian.Tree) string { return t.GetHashStrategy().String() }},
+	{name: "hash_algorithm", value: func(t *trillian.Tree) string { return t.GetHashAlgorithm().String() }},
+	{name: "signature_algorithm", value: func(t *trillian.Tree) string { return t.GetSignatureAlgorithm().String() }},
+	{name: "display_name", value: func(t *trillian.Tree) string { return t.GetDisplayName() }},
+	{name: "description", value: func(t *trillian.Tree) string { return t.GetDescription() }},
+	{name: "private_key", value: func(t *trillian.Tree) string {
+		// Never render key material, only a fingerprint of it.
+		if t.GetPrivateKey() == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v (sha256:%x)", t.GetPrivateKey().GetTypeUrl(), sha256.Sum256(t.GetPrivateKey().GetValue()))
+	}},
+	{name: "storage_settings", value: func(t *trillian.Tree) string {
+		if m := t.GetStorageSettings(); m != nil {
+			return proto.CompactTextString(m)
+		}
+		return ""
+	}},
+	{name: "public_key", value: func(t *trillian.Tree) string {
+		if m := t.GetPublicKey(); m != nil {
+			return proto.CompactTextString(m)
+		}
+		return ""
+	}},
+	{name: "max_root_duration", value: func(t *trillian.Tree) string {
+		if m := t.GetMaxRootDuration(); m != nil {
+			return proto.CompactTextString(m)
+		}
+		return ""
+	}},
+	{name: "signature_cipher_suite", value: func(t *trillian.Tree) string { return t.GetSignatureCipherSuite().String() }},
+	{name: "labels", value: func(t *trillian.Tree) string { return strings.Join(t.GetLabels(), ",") }},
+	{name: "app_data", value: func(t *trillian.Tree) string {
+		// fmt prints maps sorted by key.
+		if len(t.GetAppData()) == 0 {
+			return ""
+		}
+		return fmt.Sprint(t.GetAppData())
+	}},
+	{name: "writes_disabled", value: func(t *trillian.Tree) string { return fmt.Sprint(t.GetWritesDisabled()) }},
+	{name: "signature_params", value: func(t *trillian.Tree) string {
+		if m := t.GetSignatureParams(); m != nil {
+			return proto.CompactTextString(m)
+		}
+		return ""
+	}},
+	{name: "sequencing_paused", value: func(t *trillian.Tree) string { return fmt.Sprint(t.GetSequencingPaused()) }},
+	{name: "external_ref", value: func(t *trillian.Tree) string { return t.GetExternalRef() }},
+	{name: "preferred_read_consistency", value: func(t *trillian.Tree) string { return t.GetPreferredReadConsistency().String() }},
+	{name: "cloned_from", value: func(t *trillian.Tree) string { return fmt.Sprint(t.GetClonedFrom()) }},
+}
+
+// TreeConfigDiff returns the config fields that differ between trees a and
+// b, in proto field number order. Identical configs return an empty diff.
+// Storage-managed fields (tree_id, timestamps and deletion status) are
+// ignored. Private keys are represented by a fingerprint, so key material
+// isn't leaked into diffs.
+func TreeConfigDiff(a, b *trillian.Tree) []FieldChange {
+	var changes []FieldChange
+	for _, f := range treeDiffFields {
+		if before, after := f.value(a), f.value(b); before != after {
+			changes = append(changes, FieldChange{Field: f.name, Old: before, New: after})
+		}
+	}
+	return changes
+}
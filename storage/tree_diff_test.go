@@ -0,0 +1,81 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+)
+
+func TestTreeConfigDiff(t *testing.T) {
+	displayNameChanged := newTree()
+	displayNameChanged.DisplayName = "Alpacas Log"
+
+	multipleChanged := newTree()
+	multipleChanged.TreeState = trillian.TreeState_FROZEN
+	multipleChanged.Description = "Registry of privately-owned llamas"
+	multipleChanged.Labels = []string{"guanacos", "vicunas"}
+
+	// Storage-managed fields aren't part of the config.
+	volatileChanged := newTree()
+	volatileChanged.TreeId = 12345
+	volatileChanged.CreateTime = ptypes.TimestampNow()
+	volatileChanged.UpdateTime = ptypes.TimestampNow()
+	volatileChanged.Deleted = true
+	volatileChanged.DeleteTime = ptypes.TimestampNow()
+
+	tests := []struct {
+		desc string
+		a, b *trillian.Tree
+		want []FieldChange
+	}{
+		{
+			desc: "identical",
+			a:    newTree(),
+			b:    newTree(),
+		},
+		{
+			desc: "volatileFields",
+			a:    newTree(),
+			b:    volatileChanged,
+		},
+		{
+			desc: "displayName",
+			a:    newTree(),
+			b:    displayNameChanged,
+			want: []FieldChange{
+				{Field: "display_name", Old: "Llamas Log", New: "Alpacas Log"},
+			},
+		},
+		{
+			desc: "multipleFields",
+			a:    newTree(),
+			b:    multipleChanged,
+			want: []FieldChange{
+				{Field: "tree_state", Old: "ACTIVE", New: "FROZEN"},
+				{Field: "description", Old: "Registry of publicly-owned llamas", New: "Registry of privately-owned llamas"},
+				{Field: "labels", Old: "", New: "guanacos,vicunas"},
+			},
+		},
+	}
+	for _, test := range tests {
+		if got := TreeConfigDiff(test.a, test.b); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: TreeConfigDiff() = %+v, want = %+v", test.desc, got, test.want)
+		}
+	}
+}
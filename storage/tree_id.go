@@ -18,8 +18,25 @@ import (
 	"crypto/rand"
 	"math"
 	"math/big"
+	"time"
 )
 
+// DefaultTreeIDReservationTTL is how long IDs reserved via
+// AdminTX.ReserveTreeID remain reserved, unless overridden by
+// AdminStorageOptions.TreeIDReservationTTL.
+const DefaultTreeIDReservationTTL = 24 * time.Hour
+
+// TreeIDReservationExpiry returns when an ID reserved at now expires, as per
+// opts.TreeIDReservationTTL. It's meant to be used by AdminStorage
+// implementations of ReserveTreeID.
+func TreeIDReservationExpiry(opts AdminStorageOptions, now time.Time) time.Time {
+	ttl := opts.TreeIDReservationTTL
+	if ttl == 0 {
+		ttl = DefaultTreeIDReservationTTL
+	}
+	return now.Add(ttl)
+}
+
 // NewTreeID generates a random, positive, non-zero tree ID.
 func NewTreeID() (int64, error) {
 	id, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
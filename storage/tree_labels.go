@@ -0,0 +1,61 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// TransferLabels implements AdminWriter.TransferLabels for storage
+// implementations, on top of tx.UpdateTrees.
+func TransferLabels(ctx context.Context, tx AdminTX, fromTreeID, toTreeID int64, labels []string) error {
+	if fromTreeID == toTreeID {
+		return errors.Errorf(errors.InvalidArgument, "can't transfer labels from tree %v to itself", fromTreeID)
+	}
+	from, err := tx.GetTree(ctx, fromTreeID)
+	if err != nil {
+		return err
+	}
+	for _, label := range labels {
+		if !containsString(from.Labels, label) {
+			return errors.Errorf(errors.InvalidArgument, "tree %v doesn't have all labels: %v", fromTreeID, labels)
+		}
+	}
+
+	_, err = tx.UpdateTrees(ctx, map[int64]func(*trillian.Tree){
+		fromTreeID: func(tree *trillian.Tree) {
+			var kept []string
+			for _, label := range tree.Labels {
+				if !containsString(labels, label) {
+					kept = append(kept, label)
+				}
+			}
+			tree.Labels = kept
+		},
+		toTreeID: func(tree *trillian.Tree) {
+			added := append([]string(nil), tree.Labels...)
+			for _, label := range labels {
+				if !containsString(added, label) {
+					added = append(added, label)
+				}
+			}
+			tree.Labels = added
+		},
+	})
+	return err
+}
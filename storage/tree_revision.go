@@ -0,0 +1,40 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// TreeRevision returns the revision of tree, as expected by
+// AdminWriter.ReplaceTree and AdminWriter.SoftDeleteTreeIfUnchanged.
+// Revisions are assigned by storage, starting at 1 on creation and
+// incremented on every write to the tree, so they're only meaningful for trees
+// read from storage. Recording sequencing progress doesn't change revisions.
+func TreeRevision(tree *trillian.Tree) int64 {
+	return tree.GetRevision()
+}
+
+// CheckTreeRevision returns a FailedPrecondition error if storedTree's
+// revision isn't expectedRevision.
+// It's meant to be used by AdminStorage implementations of ReplaceTree and
+// SoftDeleteTreeIfUnchanged.
+func CheckTreeRevision(storedTree *trillian.Tree, expectedRevision int64) error {
+	if rev := TreeRevision(storedTree); rev != expectedRevision {
+		return errors.Errorf(errors.FailedPrecondition, "tree %v: stale revision: got %v, current is %v", storedTree.TreeId, expectedRevision, rev)
+	}
+	return nil
+}
@@ -17,12 +17,20 @@ package storage
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"sort"
+	"strings"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys"
 	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/errors"
 )
@@ -30,13 +38,62 @@ import (
 const (
 	maxDisplayNameLength = 20
 	maxDescriptionLength = 200
+	maxLabels            = 10
+	maxLabelLength       = 50
+	maxExternalRefLength = 256
 )
 
+// hashAlgorithms maps tree hash algorithms to their crypto.Hash.
+var hashAlgorithms = map[sigpb.DigitallySigned_HashAlgorithm]crypto.Hash{
+	sigpb.DigitallySigned_SHA256: crypto.SHA256,
+}
+
+// DefaultMaxAppDataKeyLength, DefaultMaxAppDataValueLength and
+// DefaultMaxAppDataSize are the app_data limits applied by tree validation,
+// unless overridden by the corresponding AdminStorageOptions fields.
+const (
+	DefaultMaxAppDataKeyLength   = 64
+	DefaultMaxAppDataValueLength = 1024
+	DefaultMaxAppDataSize        = 8 * 1024
+
+	// MaxAppDataKeyLengthLimit is the longest app_data key supported by all
+	// storages, as bound by the MySQL TreeAppData.AppDataKey column.
+	MaxAppDataKeyLengthLimit = 255
+)
+
+// ReservedLabelPrefix is the prefix of labels reserved for internal use (e.g.
+// primary markers). Tree validation rejects new labels with the prefix,
+// unless the context was derived from WithReservedLabels.
+const ReservedLabelPrefix = "trillian."
+
+// reservedLabelsKey is the context key for the reserved labels marker.
+type reservedLabelsKey struct{}
+
+// WithReservedLabels returns a context that allows trees to be given labels
+// with ReservedLabelPrefix. It's meant for internal code only; client-supplied
+// trees must never be validated under it.
+func WithReservedLabels(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reservedLabelsKey{}, true)
+}
+
+// ValidateTreeID returns an InvalidArgument error if treeID can't identify a
+// stored tree. Valid tree IDs are always positive, so zero (e.g., an unset
+// TreeId) and negative IDs are rejected.
+// It's meant to be called by AdminStorage implementations before accessing
+// storage.
+func ValidateTreeID(treeID int64) error {
+	if treeID <= 0 {
+		return errors.Errorf(errors.InvalidArgument, "invalid tree ID: %v", treeID)
+	}
+	return nil
+}
+
 // ValidateTreeForCreation returns nil if tree is valid for insertion, error
-// otherwise.
+// otherwise. Limits configurable via opts, such as the minimum RSA key size,
+// are taken from opts.
 // See the documentation on trillian.Tree for reference on which values are
 // valid.
-func ValidateTreeForCreation(ctx context.Context, tree *trillian.Tree) error {
+func ValidateTreeForCreation(ctx context.Context, opts AdminStorageOptions, tree *trillian.Tree) error {
 	switch {
 	case tree == nil:
 		return errors.New(errors.InvalidArgument, "a tree is required")
@@ -58,9 +115,163 @@ func ValidateTreeForCreation(ctx context.Context, tree *trillian.Tree) error {
 		return errors.Errorf(errors.InvalidArgument, "invalid deleted: %v", tree.Deleted)
 	case tree.DeleteTime != nil:
 		return errors.Errorf(errors.InvalidArgument, "invalid delete_time: %+v (must be nil)", tree.DeleteTime)
+	case tree.ClonedFrom < 0:
+		return errors.Errorf(errors.InvalidArgument, "invalid cloned_from: %v", tree.ClonedFrom)
+	}
+
+	if err := validateMutableTreeFields(ctx, opts, tree); err != nil {
+		return err
+	}
+	if err := validateReservedLabels(ctx, tree.Labels, nil /* existing */); err != nil {
+		return err
+	}
+	if err := validateSignatureParams(tree); err != nil {
+		return err
+	}
+	return validateKeyPolicy(opts, tree)
+}
+
+// ValidateTreeForImport returns nil if tree is valid for import, error
+// otherwise.
+// Contrary to creation, imported trees keep their storage-generated fields,
+// so tree_id and timestamps must be set, and the tree may be in any valid
+// state, including soft deleted. Limits are taken from opts.
+func ValidateTreeForImport(ctx context.Context, opts AdminStorageOptions, tree *trillian.Tree) error {
+	switch {
+	case tree == nil:
+		return errors.New(errors.InvalidArgument, "a tree is required")
+	case tree.TreeId <= 0:
+		return errors.Errorf(errors.InvalidArgument, "invalid tree_id: %v", tree.TreeId)
+	case tree.TreeType == trillian.TreeType_UNKNOWN_TREE_TYPE:
+		return errors.Errorf(errors.InvalidArgument, "invalid tree_type: %s", tree.TreeType)
+	case tree.HashStrategy == trillian.HashStrategy_UNKNOWN_HASH_STRATEGY:
+		return errors.Errorf(errors.InvalidArgument, "invalid hash_strategy: %s", tree.HashStrategy)
+	case tree.HashAlgorithm == sigpb.DigitallySigned_NONE:
+		return errors.Errorf(errors.InvalidArgument, "invalid hash_algorithm: %s", tree.HashAlgorithm)
+	case tree.SignatureAlgorithm == sigpb.DigitallySigned_ANONYMOUS:
+		return errors.Errorf(errors.InvalidArgument, "invalid signature_algorithm: %s", tree.SignatureAlgorithm)
+	case tree.PrivateKey == nil:
+		return errors.New(errors.InvalidArgument, "a private_key is required")
+	case tree.PublicKey == nil:
+		return errors.New(errors.InvalidArgument, "a public_key is required")
+	case tree.Deleted != (tree.DeleteTime != nil):
+		return errors.Errorf(errors.InvalidArgument, "inconsistent deleted (%v) and delete_time (%+v)", tree.Deleted, tree.DeleteTime)
+	}
+	createTime, err := ptypes.Timestamp(tree.CreateTime)
+	if err != nil {
+		return errors.Errorf(errors.InvalidArgument, "invalid create_time: %v", err)
+	}
+	updateTime, err := ptypes.Timestamp(tree.UpdateTime)
+	if err != nil {
+		return errors.Errorf(errors.InvalidArgument, "invalid update_time: %v", err)
+	}
+	if updateTime.Before(createTime) {
+		return errors.Errorf(errors.InvalidArgument, "update_time (%v) before create_time (%v)", updateTime, createTime)
+	}
+	if tree.DeleteTime != nil {
+		if _, err := ptypes.Timestamp(tree.DeleteTime); err != nil {
+			return errors.Errorf(errors.InvalidArgument, "invalid delete_time: %v", err)
+		}
+	}
+
+	if err := validateSignatureParams(tree); err != nil {
+		return err
+	}
+	return validateMutableTreeFields(ctx, opts, tree)
+}
+
+// validateSignatureParams returns an error if tree.SignatureParams are invalid
+// or don't apply to the tree's signature algorithm.
+func validateSignatureParams(tree *trillian.Tree) error {
+	padding := tree.GetSignatureParams().GetRsaPadding()
+	switch _, ok := trillian.SignatureParams_RsaPadding_name[int32(padding)]; {
+	case !ok:
+		return errors.Errorf(errors.InvalidArgument, "invalid signature_params.rsa_padding: %v", padding)
+	case padding != trillian.SignatureParams_PKCS1V15 && tree.SignatureAlgorithm != sigpb.DigitallySigned_RSA:
+		return errors.Errorf(errors.InvalidArgument, "signature_params.rsa_padding %s not supported by signature_algorithm %s", padding, tree.SignatureAlgorithm)
+	}
+	return nil
+}
+
+// validateKeyPolicy returns an error if tree has an RSA key smaller than
+// opts.MinRsaKeySizeInBits, or an ECDSA key on a curve too small for the tree's
+// hash algorithm (see validateECDSAHash).
+// Other key types are not checked.
+// It's assumed that the private and public keys have already been checked to
+// be a matching pair.
+func validateKeyPolicy(opts AdminStorageOptions, tree *trillian.Tree) error {
+	publicKey, err := der.UnmarshalPublicKey(tree.PublicKey.GetDer())
+	if err != nil {
+		return errors.Errorf(errors.InvalidArgument, "invalid public_key: %v", err)
 	}
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		minBits := opts.MinRsaKeySizeInBits
+		if minBits == 0 {
+			minBits = keys.MinRsaKeySizeInBits
+		}
+		if bits := key.N.BitLen(); bits < minBits {
+			return errors.Errorf(errors.InvalidArgument, "minimum RSA key size is %v bits, got %v bits", minBits, bits)
+		}
+	case *ecdsa.PublicKey:
+		return validateECDSAHash(key.Curve, tree.HashAlgorithm)
+	}
+	return nil
+}
+
+// validateECDSAHash returns an error if the digest size of hashAlgorithm is
+// larger than the order of curve, e.g. SHA-512 with P-256. ECDSA truncates
+// such digests to the order size, so part of the digest would go unsigned.
+// Digests smaller than the order (e.g. SHA-256 with P-384) are accepted, as
+// they're the only option while hash algorithms are limited to SHA-256.
+// Hash algorithms unknown to validation aren't checked.
+func validateECDSAHash(curve elliptic.Curve, hashAlgorithm sigpb.DigitallySigned_HashAlgorithm) error {
+	hash, ok := hashAlgorithms[hashAlgorithm]
+	if !ok {
+		return nil
+	}
+	orderSize := (curve.Params().N.BitLen() + 7) / 8
+	if hash.Size() > orderSize {
+		return errors.Errorf(errors.InvalidArgument, "hash_algorithm %s (%v-byte digest) is larger than ECDSA curve %v order (%v bytes)", hashAlgorithm, hash.Size(), curve.Params().Name, orderSize)
+	}
+	return nil
+}
+
+// ValidateTreeKeyCurve returns an InvalidArgument error if tree has an ECDSA
+// key on a curve not in opts.AllowedECCurves, nil otherwise. It's meant to be
+// called by AdminStorage implementations on tree creation, after
+// ValidateTreeForCreation.
+func ValidateTreeKeyCurve(opts AdminStorageOptions, tree *trillian.Tree) error {
+	if len(opts.AllowedECCurves) == 0 {
+		return nil
+	}
+	publicKey, err := der.UnmarshalPublicKey(tree.PublicKey.GetDer())
+	if err != nil {
+		return errors.Errorf(errors.InvalidArgument, "invalid public_key: %v", err)
+	}
+	key, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil
+	}
+	for _, allowed := range opts.AllowedECCurves {
+		if allowed.Params().Name == key.Curve.Params().Name {
+			return nil
+		}
+	}
+	return errors.Errorf(errors.InvalidArgument, "ECDSA curve not allowed: %v", key.Curve.Params().Name)
+}
 
-	return validateMutableTreeFields(ctx, tree)
+// ValidateTreeStateTransition returns a FailedPrecondition error if opts
+// forbid moving from the state of storedTree to the state of newTree, nil
+// otherwise. Under AdminStorageOptions.FreezeIsTerminal FROZEN trees can't
+// change state.
+// It's meant to be called by AdminStorage implementations alongside
+// ValidateTreeForUpdate.
+func ValidateTreeStateTransition(opts AdminStorageOptions, storedTree, newTree *trillian.Tree) error {
+	if opts.FreezeIsTerminal && storedTree.TreeState == trillian.TreeState_FROZEN && newTree.TreeState != trillian.TreeState_FROZEN {
+		return errors.Errorf(errors.FailedPrecondition, "tree %v is frozen, can't transition to %s", storedTree.TreeId, newTree.TreeState)
+	}
+	return nil
 }
 
 // ValidateTreeForUpdate returns nil if newTree is valid for update, error
@@ -68,9 +279,10 @@ func ValidateTreeForCreation(ctx context.Context, tree *trillian.Tree) error {
 // The newTree is compared to the storedTree to determine if readonly fields
 // have been changed. It's assumed that storage-generated fields, such as
 // update_time, have not yet changed when this method is called.
+// Limits are taken from opts.
 // See the documentation on trillian.Tree for reference on which fields may be
 // changed and what is considered valid for each of them.
-func ValidateTreeForUpdate(ctx context.Context, storedTree, newTree *trillian.Tree) error {
+func ValidateTreeForUpdate(ctx context.Context, opts AdminStorageOptions, storedTree, newTree *trillian.Tree) error {
 	// Check that readonly fields didn't change
 	switch {
 	case storedTree.TreeId != newTree.TreeId:
@@ -93,11 +305,24 @@ func ValidateTreeForUpdate(ctx context.Context, storedTree, newTree *trillian.Tr
 		return errors.New(errors.InvalidArgument, "readonly field changed: deleted")
 	case !proto.Equal(storedTree.DeleteTime, newTree.DeleteTime):
 		return errors.New(errors.InvalidArgument, "readonly field changed: delete_time")
+	case !proto.Equal(storedTree.LastSequencedTime, newTree.LastSequencedTime):
+		return errors.New(errors.InvalidArgument, "readonly field changed: last_sequenced_time")
+	case storedTree.SequencingPaused != newTree.SequencingPaused:
+		return errors.New(errors.InvalidArgument, "readonly field changed: sequencing_paused")
+	case storedTree.ClonedFrom != newTree.ClonedFrom:
+		return errors.New(errors.InvalidArgument, "readonly field changed: cloned_from")
+	case storedTree.Revision != newTree.Revision:
+		return errors.New(errors.InvalidArgument, "readonly field changed: revision")
+	case storedTree.GetSignatureParams().GetRsaPadding() != newTree.GetSignatureParams().GetRsaPadding():
+		return errors.New(errors.InvalidArgument, "readonly field changed: signature_params")
+	}
+	if err := validateMutableTreeFields(ctx, opts, newTree); err != nil {
+		return err
 	}
-	return validateMutableTreeFields(ctx, newTree)
+	return validateReservedLabels(ctx, newTree.Labels, storedTree.Labels)
 }
 
-func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
+func validateMutableTreeFields(ctx context.Context, opts AdminStorageOptions, tree *trillian.Tree) error {
 	switch {
 	case tree.TreeState == trillian.TreeState_UNKNOWN_TREE_STATE:
 		return errors.Errorf(errors.InvalidArgument, "invalid tree_state: %v", tree.TreeState)
@@ -105,6 +330,16 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 		return errors.Errorf(errors.InvalidArgument, "display_name too big, max length is %v: %v", maxDisplayNameLength, tree.DisplayName)
 	case len(tree.Description) > maxDescriptionLength:
 		return errors.Errorf(errors.InvalidArgument, "description too big, max length is %v: %v", maxDescriptionLength, tree.Description)
+	case len(tree.ExternalRef) > maxExternalRefLength:
+		return errors.Errorf(errors.InvalidArgument, "external_ref too big, max length is %v: %v", maxExternalRefLength, tree.ExternalRef)
+	case trillian.ReadConsistency_name[int32(tree.PreferredReadConsistency)] == "":
+		return errors.Errorf(errors.InvalidArgument, "invalid preferred_read_consistency: %v", tree.PreferredReadConsistency)
+	}
+	if err := validateLabels(tree.Labels); err != nil {
+		return err
+	}
+	if err := validateAppData(opts, tree.AppData); err != nil {
+		return err
 	}
 	if duration, err := ptypes.Duration(tree.MaxRootDuration); err != nil {
 		return errors.Errorf(errors.InvalidArgument, "max_root_duration malformed: %v", tree.MaxRootDuration)
@@ -121,23 +356,122 @@ func validateMutableTreeFields(ctx context.Context, tree *trillian.Tree) error {
 		}
 	}
 
+	return validatePrivateKey(ctx, tree.PrivateKey, tree.PublicKey)
+}
+
+// validatePrivateKey returns an error if privateKey can't be used to obtain a
+// signer, or if the signer doesn't match publicKey.
+func validatePrivateKey(ctx context.Context, privateKey *any.Any, publicKey *keyspb.PublicKey) error {
+	// An empty value unmarshals into an empty key proto, which can't produce a
+	// usable signer.
+	if len(privateKey.GetValue()) == 0 {
+		return errors.Errorf(errors.InvalidArgument, "invalid private_key: empty value for type %q", privateKey.GetTypeUrl())
+	}
 	var privateKeyProto ptypes.DynamicAny
-	if err := ptypes.UnmarshalAny(tree.PrivateKey, &privateKeyProto); err != nil {
+	if err := ptypes.UnmarshalAny(privateKey, &privateKeyProto); err != nil {
 		return errors.Errorf(errors.InvalidArgument, "invalid private_key: %v", err)
 	}
 
 	// Check that the private key can be obtained and matches the public key.
-	privateKey, err := keys.NewSigner(ctx, privateKeyProto.Message)
+	signer, err := keys.NewSigner(ctx, privateKeyProto.Message)
 	if err != nil {
 		return errors.Errorf(errors.InvalidArgument, "invalid private_key: %v", err)
 	}
-	publicKeyDER, err := der.MarshalPublicKey(privateKey.Public())
+	publicKeyDER, err := der.MarshalPublicKey(signer.Public())
 	if err != nil {
 		return errors.Errorf(errors.InvalidArgument, "invalid private_key: %v", err)
 	}
-	if !bytes.Equal(publicKeyDER, tree.PublicKey.GetDer()) {
+	if !bytes.Equal(publicKeyDER, publicKey.GetDer()) {
 		return errors.Errorf(errors.InvalidArgument, "private_key and public_key are not a matching pair")
 	}
 
 	return nil
 }
+
+func validateLabels(labels []string) error {
+	if len(labels) > maxLabels {
+		return errors.Errorf(errors.InvalidArgument, "too many labels, max is %v: %v", maxLabels, labels)
+	}
+	seen := make(map[string]bool)
+	for _, label := range labels {
+		switch {
+		case label == "":
+			return errors.New(errors.InvalidArgument, "labels must not be empty")
+		case len(label) > maxLabelLength:
+			return errors.Errorf(errors.InvalidArgument, "label too big, max length is %v: %v", maxLabelLength, label)
+		case seen[label]:
+			return errors.Errorf(errors.InvalidArgument, "duplicate label: %v", label)
+		}
+		seen[label] = true
+	}
+	return nil
+}
+
+// validateReservedLabels returns an error if labels contains a label with
+// ReservedLabelPrefix that isn't in existing, unless ctx was derived from
+// WithReservedLabels.
+// Imported trees aren't checked, as their labels were validated by the
+// storage they're imported from.
+func validateReservedLabels(ctx context.Context, labels, existing []string) error {
+	if allowed, _ := ctx.Value(reservedLabelsKey{}).(bool); allowed {
+		return nil
+	}
+	for _, label := range labels {
+		if strings.HasPrefix(label, ReservedLabelPrefix) && !containsString(existing, label) {
+			return errors.Errorf(errors.InvalidArgument, "label uses reserved prefix %q: %v", ReservedLabelPrefix, label)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAppData returns an error if appData exceeds the limits of opts, see
+// AdminStorageOptions.MaxAppDataKeyLength and related fields.
+func validateAppData(opts AdminStorageOptions, appData map[string]string) error {
+	maxKeyLength := opts.MaxAppDataKeyLength
+	switch {
+	case maxKeyLength == 0:
+		maxKeyLength = DefaultMaxAppDataKeyLength
+	case maxKeyLength > MaxAppDataKeyLengthLimit:
+		maxKeyLength = MaxAppDataKeyLengthLimit
+	}
+	maxValueLength := opts.MaxAppDataValueLength
+	if maxValueLength == 0 {
+		maxValueLength = DefaultMaxAppDataValueLength
+	}
+	maxSize := opts.MaxAppDataSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxAppDataSize
+	}
+
+	// Check keys in order, so errors are deterministic.
+	keys := make([]string, 0, len(appData))
+	for key := range appData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	size := 0
+	for _, key := range keys {
+		value := appData[key]
+		switch {
+		case len(key) > maxKeyLength:
+			return errors.Errorf(errors.InvalidArgument, "app_data key too big, max length is %v: %v", maxKeyLength, key)
+		case len(value) > maxValueLength:
+			return errors.Errorf(errors.InvalidArgument, "app_data value of key %q too big, max length is %v", key, maxValueLength)
+		}
+		size += len(key) + len(value)
+	}
+	if size > maxSize {
+		return errors.Errorf(errors.InvalidArgument, "app_data too big, max total size is %v: %v", maxSize, size)
+	}
+	return nil
+}
@@ -16,6 +16,12 @@ package storage
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,6 +29,7 @@ import (
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/errors"
@@ -87,6 +94,15 @@ func TestValidateTreeForCreation(t *testing.T) {
 		A Very Long Description That Clearly Won't Fit, Also Mentions Llamas, For Some Reason Has Only Capitalized Words And Keeps Repeating Itself.
 		`
 
+	invalidExternalRef := newTree()
+	invalidExternalRef.ExternalRef = strings.Repeat("a", 257)
+
+	invalidReadConsistency := newTree()
+	invalidReadConsistency.PreferredReadConsistency = trillian.ReadConsistency(99)
+
+	invalidClonedFrom := newTree()
+	invalidClonedFrom.ClonedFrom = -1
+
 	unsupportedPrivateKey := newTree()
 	unsupportedPrivateKey.PrivateKey.TypeUrl = "urn://unknown-type"
 
@@ -96,6 +112,9 @@ func TestValidateTreeForCreation(t *testing.T) {
 	nilPrivateKey := newTree()
 	nilPrivateKey.PrivateKey = nil
 
+	emptyPrivateKey := newTree()
+	emptyPrivateKey.PrivateKey.Value = nil
+
 	invalidPublicKey := newTree()
 	invalidPublicKey.PublicKey.Der = []byte("foobar")
 
@@ -125,6 +144,61 @@ func TestValidateTreeForCreation(t *testing.T) {
 	deleteTimeTree := newTree()
 	deleteTimeTree.DeleteTime = ptypes.TimestampNow()
 
+	validLabels := newTree()
+	validLabels.Labels = []string{"llamas", "alpacas"}
+
+	tooManyLabels := newTree()
+	for i := 0; i <= maxLabels; i++ {
+		tooManyLabels.Labels = append(tooManyLabels.Labels, fmt.Sprintf("label%v", i))
+	}
+
+	emptyLabel := newTree()
+	emptyLabel.Labels = []string{"llamas", ""}
+
+	longLabel := newTree()
+	longLabel.Labels = []string{strings.Repeat("l", maxLabelLength+1)}
+
+	duplicateLabels := newTree()
+	duplicateLabels.Labels = []string{"llamas", "alpacas", "llamas"}
+
+	reservedLabel := newTree()
+	reservedLabel.Labels = []string{"llamas", ReservedLabelPrefix + "primary"}
+
+	validAppData := newTree()
+	validAppData.AppData = map[string]string{"owner": "llamas"}
+
+	longAppDataKey := newTree()
+	longAppDataKey.AppData = map[string]string{strings.Repeat("k", DefaultMaxAppDataKeyLength+1): "llamas"}
+
+	longAppDataValue := newTree()
+	longAppDataValue.AppData = map[string]string{"owner": strings.Repeat("v", DefaultMaxAppDataValueLength+1)}
+
+	// Every entry is within limits, but their total size isn't.
+	bigAppData := newTree()
+	bigAppData.AppData = make(map[string]string)
+	for i := 0; i <= DefaultMaxAppDataSize/DefaultMaxAppDataValueLength; i++ {
+		bigAppData.AppData[fmt.Sprintf("key%v", i)] = strings.Repeat("v", DefaultMaxAppDataValueLength)
+	}
+
+	rsa1024Tree := newRSATree(t, 1024)
+	rsa2048Tree := newRSATree(t, 2048)
+
+	// SHA-256 digests are larger than the P-224 order, but not the P-384 one.
+	p224Tree := newECDSATree(t, elliptic.P224())
+	p384Tree := newECDSATree(t, elliptic.P384())
+
+	rsaPSSTree := newRSATree(t, 2048)
+	rsaPSSTree.SignatureParams = &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PSS}
+
+	ecdsaPSSTree := newTree()
+	ecdsaPSSTree.SignatureParams = &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PSS}
+
+	ecdsaPKCS1Tree := newTree()
+	ecdsaPKCS1Tree.SignatureParams = &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PKCS1V15}
+
+	invalidPaddingTree := newRSATree(t, 2048)
+	invalidPaddingTree.SignatureParams = &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_RsaPadding(-1)}
+
 	tests := []struct {
 		desc    string
 		tree    *trillian.Tree
@@ -183,6 +257,21 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    invalidDescription,
 			wantErr: true,
 		},
+		{
+			desc:    "invalidExternalRef",
+			tree:    invalidExternalRef,
+			wantErr: true,
+		},
+		{
+			desc:    "invalidReadConsistency",
+			tree:    invalidReadConsistency,
+			wantErr: true,
+		},
+		{
+			desc:    "invalidClonedFrom",
+			tree:    invalidClonedFrom,
+			wantErr: true,
+		},
 		{
 			desc:    "unsupportedPrivateKey",
 			tree:    unsupportedPrivateKey,
@@ -198,6 +287,11 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    nilPrivateKey,
 			wantErr: true,
 		},
+		{
+			desc:    "emptyPrivateKey",
+			tree:    emptyPrivateKey,
+			wantErr: true,
+		},
 		{
 			desc:    "invalidPublicKey",
 			tree:    invalidPublicKey,
@@ -237,9 +331,93 @@ func TestValidateTreeForCreation(t *testing.T) {
 			tree:    deleteTimeTree,
 			wantErr: true,
 		},
+		{
+			desc: "validLabels",
+			tree: validLabels,
+		},
+		{
+			desc:    "tooManyLabels",
+			tree:    tooManyLabels,
+			wantErr: true,
+		},
+		{
+			desc:    "emptyLabel",
+			tree:    emptyLabel,
+			wantErr: true,
+		},
+		{
+			desc:    "longLabel",
+			tree:    longLabel,
+			wantErr: true,
+		},
+		{
+			desc:    "duplicateLabels",
+			tree:    duplicateLabels,
+			wantErr: true,
+		},
+		{
+			desc:    "reservedLabel",
+			tree:    reservedLabel,
+			wantErr: true,
+		},
+		{
+			desc: "validAppData",
+			tree: validAppData,
+		},
+		{
+			desc:    "longAppDataKey",
+			tree:    longAppDataKey,
+			wantErr: true,
+		},
+		{
+			desc:    "longAppDataValue",
+			tree:    longAppDataValue,
+			wantErr: true,
+		},
+		{
+			desc:    "bigAppData",
+			tree:    bigAppData,
+			wantErr: true,
+		},
+		{
+			desc:    "rsa1024Tree",
+			tree:    rsa1024Tree,
+			wantErr: true,
+		},
+		{
+			desc: "rsa2048Tree",
+			tree: rsa2048Tree,
+		},
+		{
+			desc:    "p224Tree",
+			tree:    p224Tree,
+			wantErr: true,
+		},
+		{
+			desc: "p384Tree",
+			tree: p384Tree,
+		},
+		{
+			desc: "rsaPSSTree",
+			tree: rsaPSSTree,
+		},
+		{
+			desc:    "ecdsaPSSTree",
+			tree:    ecdsaPSSTree,
+			wantErr: true,
+		},
+		{
+			desc: "ecdsaPKCS1Tree",
+			tree: ecdsaPKCS1Tree,
+		},
+		{
+			desc:    "invalidPaddingTree",
+			tree:    invalidPaddingTree,
+			wantErr: true,
+		},
 	}
 	for _, test := range tests {
-		err := ValidateTreeForCreation(ctx, test.tree)
+		err := ValidateTreeForCreation(ctx, AdminStorageOptions{}, test.tree)
 		switch hasErr := err != nil; {
 		case hasErr != test.wantErr:
 			t.Errorf("%v: ValidateTreeForCreation() = %v, wantErr = %v", test.desc, err, test.wantErr)
@@ -343,6 +521,13 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "emptyPrivateKeyProto",
+			updatefn: func(tree *trillian.Tree) {
+				tree.PrivateKey = &any.Any{TypeUrl: tree.PrivateKey.TypeUrl}
+			},
+			wantErr: true,
+		},
 		// Changes on readonly fields
 		{
 			desc: "TreeId",
@@ -403,13 +588,41 @@ func TestValidateTreeForUpdate(t *testing.T) {
 			updatefn: func(tree *trillian.Tree) { tree.DeleteTime = ptypes.TimestampNow() },
 			wantErr:  true,
 		},
+		{
+			desc:     "LastSequencedTime",
+			updatefn: func(tree *trillian.Tree) { tree.LastSequencedTime = ptypes.TimestampNow() },
+			wantErr:  true,
+		},
+		{
+			desc:     "SequencingPaused",
+			updatefn: func(tree *trillian.Tree) { tree.SequencingPaused = true },
+			wantErr:  true,
+		},
+		{
+			desc:     "ClonedFrom",
+			updatefn: func(tree *trillian.Tree) { tree.ClonedFrom = 12345 },
+			wantErr:  true,
+		},
+		{
+			desc: "SignatureParams",
+			updatefn: func(tree *trillian.Tree) {
+				tree.SignatureParams = &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PSS}
+			},
+			wantErr: true,
+		},
+		{
+			desc: "DefaultSignatureParams",
+			updatefn: func(tree *trillian.Tree) {
+				tree.SignatureParams = &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PKCS1V15}
+			},
+		},
 	}
 	for _, test := range tests {
 		tree := newTree()
 		baseTree := *tree
 		test.updatefn(tree)
 
-		err := ValidateTreeForUpdate(ctx, &baseTree, tree)
+		err := ValidateTreeForUpdate(ctx, AdminStorageOptions{}, &baseTree, tree)
 		switch hasErr := err != nil; {
 		case hasErr != test.wantErr:
 			t.Errorf("%v: ValidateTreeForUpdate() = %v, wantErr = %v", test.desc, err, test.wantErr)
@@ -419,6 +632,102 @@ func TestValidateTreeForUpdate(t *testing.T) {
 	}
 }
 
+func TestValidateTreeForImport(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		desc     string
+		updatefn func(*trillian.Tree)
+		wantErr  bool
+	}{
+		{
+			desc:     "valid",
+			updatefn: func(tree *trillian.Tree) {},
+		},
+		{
+			desc:     "frozen",
+			updatefn: func(tree *trillian.Tree) { tree.TreeState = trillian.TreeState_FROZEN },
+		},
+		{
+			desc: "deleted",
+			updatefn: func(tree *trillian.Tree) {
+				tree.Deleted = true
+				tree.DeleteTime = ptypes.TimestampNow()
+			},
+		},
+		{
+			desc:     "noTreeId",
+			updatefn: func(tree *trillian.Tree) { tree.TreeId = 0 },
+			wantErr:  true,
+		},
+		{
+			desc:     "invalidTreeType",
+			updatefn: func(tree *trillian.Tree) { tree.TreeType = trillian.TreeType_UNKNOWN_TREE_TYPE },
+			wantErr:  true,
+		},
+		{
+			desc:     "nilPublicKey",
+			updatefn: func(tree *trillian.Tree) { tree.PublicKey = nil },
+			wantErr:  true,
+		},
+		{
+			desc:     "noCreateTime",
+			updatefn: func(tree *trillian.Tree) { tree.CreateTime = nil },
+			wantErr:  true,
+		},
+		{
+			desc:     "noUpdateTime",
+			updatefn: func(tree *trillian.Tree) { tree.UpdateTime = nil },
+			wantErr:  true,
+		},
+		{
+			desc: "updateTimeAfterCreateTime",
+			updatefn: func(tree *trillian.Tree) {
+				tree.CreateTime, _ = ptypes.TimestampProto(time.Unix(1000, 0))
+				tree.UpdateTime, _ = ptypes.TimestampProto(time.Unix(2000, 0))
+			},
+		},
+		{
+			desc: "updateTimeBeforeCreateTime",
+			updatefn: func(tree *trillian.Tree) {
+				tree.CreateTime, _ = ptypes.TimestampProto(time.Unix(2000, 0))
+				tree.UpdateTime, _ = ptypes.TimestampProto(time.Unix(1000, 0))
+			},
+			wantErr: true,
+		},
+		{
+			desc:     "deletedWithoutDeleteTime",
+			updatefn: func(tree *trillian.Tree) { tree.Deleted = true },
+			wantErr:  true,
+		},
+		{
+			desc:     "deleteTimeWithoutDeleted",
+			updatefn: func(tree *trillian.Tree) { tree.DeleteTime = ptypes.TimestampNow() },
+			wantErr:  true,
+		},
+		{
+			desc:     "invalidDisplayName",
+			updatefn: func(tree *trillian.Tree) { tree.DisplayName = strings.Repeat("llama", 20) },
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		tree := newTree()
+		tree.TreeId = 12345
+		tree.CreateTime = ptypes.TimestampNow()
+		tree.UpdateTime = tree.CreateTime
+		test.updatefn(tree)
+
+		err := ValidateTreeForImport(ctx, AdminStorageOptions{}, tree)
+		switch hasErr := err != nil; {
+		case hasErr != test.wantErr:
+			t.Errorf("%v: ValidateTreeForImport() = %v, wantErr = %v", test.desc, err, test.wantErr)
+		case hasErr && errors.ErrorCode(err) != errors.InvalidArgument:
+			t.Errorf("%v: ValidateTreeForImport() = %v, wantCode = %v", test.desc, err, errors.InvalidArgument)
+		}
+	}
+}
+
 // newTree returns a valid tree for tests.
 func newTree() *trillian.Tree {
 	privateKey, err := ptypes.MarshalAny(&keyspb.PEMKeyFile{
@@ -444,3 +753,56 @@ func newTree() *trillian.Tree {
 		MaxRootDuration: ptypes.DurationProto(1000 * time.Millisecond),
 	}
 }
+
+// newRSATree returns a valid tree with a freshly-generated RSA key of the
+// specified size.
+func newRSATree(t *testing.T, bits int) *trillian.Tree {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() = (_, %v), want = (_, nil)", err)
+	}
+	keyDER, err := der.MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatalf("der.MarshalPrivateKey() = (_, %v), want = (_, nil)", err)
+	}
+	privateKey, err := ptypes.MarshalAny(&keyspb.PrivateKey{Der: keyDER})
+	if err != nil {
+		t.Fatalf("ptypes.MarshalAny() = (_, %v), want = (_, nil)", err)
+	}
+	publicKey, err := der.ToPublicProto(key.Public())
+	if err != nil {
+		t.Fatalf("der.ToPublicProto() = (_, %v), want = (_, nil)", err)
+	}
+
+	tree := newTree()
+	tree.SignatureAlgorithm = sigpb.DigitallySigned_RSA
+	tree.PrivateKey = privateKey
+	tree.PublicKey = publicKey
+	return tree
+}
+
+// newECDSATree returns a valid tree with a freshly-generated ECDSA key on the
+// specified curve.
+func newECDSATree(t *testing.T, curve elliptic.Curve) *trillian.Tree {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() = (_, %v), want = (_, nil)", err)
+	}
+	keyDER, err := der.MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatalf("der.MarshalPrivateKey() = (_, %v), want = (_, nil)", err)
+	}
+	privateKey, err := ptypes.MarshalAny(&keyspb.PrivateKey{Der: keyDER})
+	if err != nil {
+		t.Fatalf("ptypes.MarshalAny() = (_, %v), want = (_, nil)", err)
+	}
+	publicKey, err := der.ToPublicProto(key.Public())
+	if err != nil {
+		t.Fatalf("der.ToPublicProto() = (_, %v), want = (_, nil)", err)
+	}
+
+	tree := newTree()
+	tree.PrivateKey = privateKey
+	tree.PublicKey = publicKey
+	return tree
+}
@@ -0,0 +1,34 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import "github.com/google/trillian"
+
+// IsTreeWritable returns whether tree accepts writes, such as queueing and
+// integrating leaves.
+// Soft-deleted, FROZEN, MAINTENANCE and writes-disabled trees aren't writable. Note that
+// WritesDisabled is independent of TreeState, so an ACTIVE tree may still
+// reject writes.
+func IsTreeWritable(tree *trillian.Tree) bool {
+	switch {
+	case tree.Deleted:
+		return false
+	case tree.TreeState == trillian.TreeState_FROZEN, tree.TreeState == trillian.TreeState_MAINTENANCE:
+		return false
+	case tree.WritesDisabled:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian/errors"
+)
+
+// DefaultWaitForTreePollInterval is the interval between reads performed by
+// WaitForTree if none is specified.
+const DefaultWaitForTreePollInterval = 100 * time.Millisecond
+
+// WaitForTree blocks until treeID is readable from a new snapshot of
+// adminStorage, polling every pollInterval. It's meant to be used as a
+// read-your-writes barrier after committing a tree on eventually consistent
+// backends.
+// Soft-deleted trees are considered readable.
+// A zero pollInterval means DefaultWaitForTreePollInterval.
+// Returns a DeadlineExceeded error if the tree isn't readable after timeout.
+func WaitForTree(ctx context.Context, adminStorage AdminStorage, treeID int64, timeout, pollInterval time.Duration) error {
+	if pollInterval == 0 {
+		pollInterval = DefaultWaitForTreePollInterval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		switch err := readTree(ctx, adminStorage, treeID); {
+		case err == nil:
+			return nil
+		case errors.ErrorCode(err) != errors.NotFound && ctx.Err() == nil:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return errors.Errorf(errors.DeadlineExceeded, "tree %v not readable after %v", treeID, timeout)
+			}
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func readTree(ctx context.Context, adminStorage AdminStorage, treeID int64) error {
+	tx, err := adminStorage.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+	if _, err := tx.GetTree(ctx, treeID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,90 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/errors"
+)
+
+// delayedAdminStorage is a fake AdminStorage whose tree only becomes visible
+// after a number of reads. Only Snapshot is implemented.
+type delayedAdminStorage struct {
+	AdminStorage
+	tree *trillian.Tree
+	// invisibleReads is the number of reads that fail with NotFound before
+	// the tree becomes visible. Negative values mean it never becomes visible.
+	invisibleReads int
+	getErr         error
+	reads          int
+}
+
+func (s *delayedAdminStorage) Snapshot(ctx context.Context) (ReadOnlyAdminTX, error) {
+	return &delayedAdminTX{s: s}, nil
+}
+
+type delayedAdminTX struct {
+	ReadOnlyAdminTX
+	s *delayedAdminStorage
+}
+
+func (t *delayedAdminTX) GetTree(ctx context.Context, treeID int64) (*trillian.Tree, error) {
+	s := t.s
+	s.reads++
+	switch {
+	case s.getErr != nil:
+		return nil, s.getErr
+	case treeID != s.tree.TreeId, s.invisibleReads < 0, s.reads <= s.invisibleReads:
+		return nil, errors.Errorf(errors.NotFound, "tree %v not found", treeID)
+	}
+	return s.tree, nil
+}
+
+func (t *delayedAdminTX) Commit() error { return nil }
+
+func (t *delayedAdminTX) Close() error { return nil }
+
+func TestWaitForTree(t *testing.T) {
+	ctx := context.Background()
+	tree := newTree()
+	tree.TreeId = 12345
+
+	tests := []struct {
+		desc           string
+		invisibleReads int
+		getErr         error
+		wantCode       errors.Code
+		wantReads      int
+	}{
+		{desc: "visible", wantReads: 1},
+		{desc: "delayed", invisibleReads: 3, wantReads: 4},
+		{desc: "neverVisible", invisibleReads: -1, wantCode: errors.DeadlineExceeded},
+		{desc: "getErr", getErr: errors.New(errors.Internal, "get err"), wantCode: errors.Internal, wantReads: 1},
+	}
+	for _, test := range tests {
+		s := &delayedAdminStorage{tree: tree, invisibleReads: test.invisibleReads, getErr: test.getErr}
+		err := WaitForTree(ctx, s, tree.TreeId, 50*time.Millisecond, 1*time.Millisecond)
+		if got := errors.ErrorCode(err); got != test.wantCode {
+			t.Errorf("%v: WaitForTree() returned err = %v, wantCode = %s", test.desc, err, test.wantCode)
+		}
+		if test.wantReads > 0 && s.reads != test.wantReads {
+			t.Errorf("%v: WaitForTree() read tree %v times, want = %v", test.desc, s.reads, test.wantReads)
+		}
+	}
+}
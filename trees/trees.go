@@ -57,6 +57,8 @@ type GetOpts struct {
 // GetTree returns the specified tree, either from the ctx (if present) or read from storage.
 // The tree will be validated according to GetOpts before returned. Tree state is also considered
 // (for example, deleted tree will return NotFound errors).
+// Trees are read from storage with private key access (see
+// storage.WithPrivateKeyAccess), as callers use them to sign.
 func GetTree(ctx context.Context, s storage.AdminStorage, treeID int64, opts GetOpts) (*trillian.Tree, error) {
 	// TODO(codingllama): Record stats of ctx hits/misses, so we can assess whether RPCs work
 	// as intended.
@@ -72,8 +74,12 @@ func GetTree(ctx context.Context, s storage.AdminStorage, treeID int64, opts Get
 	switch {
 	case opts.TreeType != trillian.TreeType_UNKNOWN_TREE_TYPE && tree.TreeType != opts.TreeType:
 		return nil, errors.Errorf(errors.InvalidArgument, "operation not allowed for %s-type trees (wanted %s-type)", tree.TreeType, opts.TreeType)
+	case tree.TreeState == trillian.TreeState_MAINTENANCE:
+		return nil, errors.Errorf(errors.FailedPrecondition, "tree %v is under maintenance", tree.TreeId)
 	case tree.TreeState == trillian.TreeState_FROZEN && !opts.Readonly:
 		return nil, errors.Errorf(errors.FailedPrecondition, "operation not allowed on %s trees", tree.TreeState)
+	case tree.WritesDisabled && !opts.Readonly:
+		return nil, errors.Errorf(errors.FailedPrecondition, "writes disabled for tree %v", tree.TreeId)
 	case tree.Deleted:
 		return nil, errors.Errorf(errors.NotFound, "tree %v not found", tree.TreeId)
 	}
@@ -82,6 +88,7 @@ func GetTree(ctx context.Context, s storage.AdminStorage, treeID int64, opts Get
 }
 
 func getTreeFromStorage(ctx context.Context, s storage.AdminStorage, treeID int64) (*trillian.Tree, error) {
+	ctx = storage.WithPrivateKeyAccess(ctx)
 	tx, err := s.Snapshot(ctx)
 	if err != nil {
 		return nil, err
@@ -99,12 +106,7 @@ func getTreeFromStorage(ctx context.Context, s storage.AdminStorage, treeID int6
 
 // Hash returns the crypto.Hash configured by the tree.
 func Hash(tree *trillian.Tree) (crypto.Hash, error) {
-	switch tree.HashAlgorithm {
-	case sigpb.DigitallySigned_SHA256:
-		return crypto.SHA256, nil
-	}
-	// There's no nil-like value for crypto.Hash, something has to be returned.
-	return crypto.SHA256, fmt.Errorf("unexpected hash algorithm: %s", tree.HashAlgorithm)
+	return tcrypto.TreeHash(tree)
 }
 
 // Signer returns a Trillian crypto.Signer configured by the tree.
@@ -113,11 +115,6 @@ func Signer(ctx context.Context, tree *trillian.Tree) (*tcrypto.Signer, error) {
 		return nil, fmt.Errorf("signature algorithm not supported: %s", tree.SignatureAlgorithm)
 	}
 
-	hash, err := Hash(tree)
-	if err != nil {
-		return nil, err
-	}
-
 	var keyProto ptypes.DynamicAny
 	if err := ptypes.UnmarshalAny(tree.PrivateKey, &keyProto); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal tree.PrivateKey: %v", err)
@@ -132,5 +129,13 @@ func Signer(ctx context.Context, tree *trillian.Tree) (*tcrypto.Signer, error) {
 		return nil, fmt.Errorf("%s signature not supported by signer of type %T", tree.SignatureAlgorithm, signer)
 	}
 
-	return &tcrypto.Signer{Hash: hash, Signer: signer}, nil
+	return tcrypto.NewSignerForTree(tree, signer)
+}
+
+// SignerOpts returns the crypto.SignerOpts configured by the tree's
+// SignatureParams, as used by Signer. Signatures of the tree must be verified
+// with the same opts, see tcrypto.VerifyWithOpts.
+// Returns nil opts if the tree uses default params.
+func SignerOpts(tree *trillian.Tree) (crypto.SignerOpts, error) {
+	return tcrypto.SignerOpts(tree)
 }
@@ -72,6 +72,14 @@ func TestGetTree(t *testing.T) {
 	frozenTree.TreeId = 3
 	frozenTree.TreeState = trillian.TreeState_FROZEN
 
+	writesDisabledTree := *testonly.LogTree
+	writesDisabledTree.TreeId = 4
+	writesDisabledTree.WritesDisabled = true
+
+	maintenanceTree := *testonly.LogTree
+	maintenanceTree.TreeId = 5
+	maintenanceTree.TreeState = trillian.TreeState_MAINTENANCE
+
 	softDeletedTree := *testonly.LogTree
 	softDeletedTree.Deleted = true
 	softDeletedTree.DeleteTime = ptypes.TimestampNow()
@@ -126,6 +134,34 @@ func TestGetTree(t *testing.T) {
 			storageTree: &frozenTree,
 			wantErr:     true,
 		},
+		{
+			desc:        "writesDisabledTree",
+			treeID:      writesDisabledTree.TreeId,
+			opts:        GetOpts{TreeType: trillian.TreeType_LOG, Readonly: true},
+			storageTree: &writesDisabledTree,
+			wantTree:    &writesDisabledTree,
+		},
+		{
+			desc:        "writesDisabledTreeNotReadonly",
+			treeID:      writesDisabledTree.TreeId,
+			opts:        GetOpts{TreeType: trillian.TreeType_LOG},
+			storageTree: &writesDisabledTree,
+			wantErr:     true,
+		},
+		{
+			desc:        "maintenanceTree",
+			treeID:      maintenanceTree.TreeId,
+			opts:        GetOpts{TreeType: trillian.TreeType_LOG},
+			storageTree: &maintenanceTree,
+			wantErr:     true,
+		},
+		{
+			desc:        "maintenanceTreeReadonly",
+			treeID:      maintenanceTree.TreeId,
+			opts:        GetOpts{TreeType: trillian.TreeType_LOG, Readonly: true},
+			storageTree: &maintenanceTree,
+			wantErr:     true,
+		},
 		{
 			desc:        "softDeleted",
 			treeID:      softDeletedTree.TreeId,
@@ -179,8 +215,8 @@ func TestGetTree(t *testing.T) {
 
 		admin := storage.NewMockAdminStorage(ctrl)
 		tx := storage.NewMockReadOnlyAdminTX(ctrl)
-		admin.EXPECT().Snapshot(ctx).MaxTimes(1).Return(tx, test.beginErr)
-		tx.EXPECT().GetTree(ctx, test.treeID).MaxTimes(1).Return(test.storageTree, test.getErr)
+		admin.EXPECT().Snapshot(testonly.PrivateKeyAccessContext()).MaxTimes(1).Return(tx, test.beginErr)
+		tx.EXPECT().GetTree(testonly.PrivateKeyAccessContext(), test.treeID).MaxTimes(1).Return(test.storageTree, test.getErr)
 		tx.EXPECT().Close().MaxTimes(1).Return(nil)
 		tx.EXPECT().Commit().MaxTimes(1).Return(test.commitErr)
 
@@ -241,11 +277,15 @@ func TestSigner(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+
 	tests := []struct {
 		desc         string
 		sigAlgo      sigpb.DigitallySigned_SignatureAlgorithm
+		sigParams    *trillian.SignatureParams
 		signer       crypto.Signer
 		newSignerErr error
+		wantOpts     crypto.SignerOpts
 		wantErr      bool
 	}{
 		{
@@ -263,6 +303,26 @@ func TestSigner(t *testing.T) {
 			sigAlgo: sigpb.DigitallySigned_RSA,
 			signer:  rsaKey,
 		},
+		{
+			desc:      "rsaPKCS1",
+			sigAlgo:   sigpb.DigitallySigned_RSA,
+			sigParams: &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PKCS1V15},
+			signer:    rsaKey,
+		},
+		{
+			desc:      "rsaPSS",
+			sigAlgo:   sigpb.DigitallySigned_RSA,
+			sigParams: &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PSS},
+			signer:    rsaKey,
+			wantOpts:  pssOpts,
+		},
+		{
+			desc:      "ecdsaPSS",
+			sigAlgo:   sigpb.DigitallySigned_ECDSA,
+			sigParams: &trillian.SignatureParams{RsaPadding: trillian.SignatureParams_PSS},
+			signer:    ecdsaKey,
+			wantErr:   true,
+		},
 		{
 			desc:    "keyMismatch1",
 			sigAlgo: sigpb.DigitallySigned_ECDSA,
@@ -289,6 +349,7 @@ func TestSigner(t *testing.T) {
 		tree.HashAlgorithm = sigpb.DigitallySigned_SHA256
 		tree.HashStrategy = trillian.HashStrategy_RFC6962_SHA256
 		tree.SignatureAlgorithm = test.sigAlgo
+		tree.SignatureParams = test.sigParams
 
 		var wantKeyProto ptypes.DynamicAny
 		if err := ptypes.UnmarshalAny(tree.PrivateKey, &wantKeyProto); err != nil {
@@ -311,9 +372,25 @@ func TestSigner(t *testing.T) {
 			continue
 		}
 
-		want := &tcrypto.Signer{Hash: crypto.SHA256, Signer: test.signer}
+		want := &tcrypto.Signer{Hash: crypto.SHA256, Signer: test.signer, Opts: test.wantOpts}
 		if diff := pretty.Compare(signer, want); diff != "" {
 			t.Errorf("%v: post-Signer(_, %s) diff:\n%v", test.desc, test.sigAlgo, diff)
 		}
+
+		// Signatures must verify with the tree's opts, and only with them.
+		data := []byte("data")
+		sig, err := signer.Sign(data)
+		if err != nil {
+			t.Errorf("%v: Sign() = (_, %v), want = (_, nil)", test.desc, err)
+			continue
+		}
+		if err := tcrypto.VerifyWithOpts(signer.Public(), data, sig, test.wantOpts); err != nil {
+			t.Errorf("%v: VerifyWithOpts(%v) = %v, want = nil", test.desc, test.wantOpts, err)
+		}
+		if test.wantOpts != nil {
+			if err := tcrypto.Verify(signer.Public(), data, sig); err == nil {
+				t.Errorf("%v: Verify() = nil, want = error for %v signature", test.desc, test.wantOpts)
+			}
+		}
 	}
 }
@@ -75,6 +75,12 @@ const (
 	TreeState_DEPRECATED_SOFT_DELETED TreeState = 3
 	// Deprecated in favor of Tree.deleted.
 	TreeState_DEPRECATED_HARD_DELETED TreeState = 4
+	// Draining trees continue to integrate queued entries, but new entries
+	// should not be accepted.
+	TreeState_DRAINING TreeState = 5
+	// Trees under maintenance respond to neither read nor write requests. See
+	// storage WithTreeMaintenance.
+	TreeState_MAINTENANCE TreeState = 6
 )
 
 var TreeState_name = map[int32]string{
@@ -83,6 +89,8 @@ var TreeState_name = map[int32]string{
 	2: "FROZEN",
 	3: "DEPRECATED_SOFT_DELETED",
 	4: "DEPRECATED_HARD_DELETED",
+	5: "DRAINING",
+	6: "MAINTENANCE",
 }
 var TreeState_value = map[string]int32{
 	"UNKNOWN_TREE_STATE":      0,
@@ -90,6 +98,8 @@ var TreeState_value = map[string]int32{
 	"FROZEN":                  2,
 	"DEPRECATED_SOFT_DELETED": 3,
 	"DEPRECATED_HARD_DELETED": 4,
+	"DRAINING":                5,
+	"MAINTENANCE":             6,
 }
 
 func (x TreeState) String() string {
@@ -108,17 +118,22 @@ const (
 	TreeType_LOG TreeType = 1
 	// Tree represents a verifiable map.
 	TreeType_MAP TreeType = 2
+	// Tree represents a verifiable pre-ordered log, i.e., a log whose entries
+	// are placed according to sequence numbers assigned outside of Trillian.
+	TreeType_PREORDERED_LOG TreeType = 3
 )
 
 var TreeType_name = map[int32]string{
 	0: "UNKNOWN_TREE_TYPE",
 	1: "LOG",
 	2: "MAP",
+	3: "PREORDERED_LOG",
 }
 var TreeType_value = map[string]int32{
 	"UNKNOWN_TREE_TYPE": 0,
 	"LOG":               1,
 	"MAP":               2,
+	"PREORDERED_LOG":    3,
 }
 
 func (x TreeType) String() string {
@@ -126,6 +141,62 @@ func (x TreeType) String() string {
 }
 func (TreeType) EnumDescriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
 
+// Consistency of reads from a tree.
+// It's a hint only: no storage implementation currently serves stale reads,
+// so all reads are strongly consistent regardless of it.
+type ReadConsistency int32
+
+const (
+	// No preference. Reads are strongly consistent.
+	ReadConsistency_UNSPECIFIED_READ_CONSISTENCY ReadConsistency = 0
+	// Reads reflect all committed writes.
+	ReadConsistency_STRONG ReadConsistency = 1
+	// Reads may be stale, in exchange for lower latency.
+	ReadConsistency_EVENTUAL ReadConsistency = 2
+)
+
+var ReadConsistency_name = map[int32]string{
+	0: "UNSPECIFIED_READ_CONSISTENCY",
+	1: "STRONG",
+	2: "EVENTUAL",
+}
+var ReadConsistency_value = map[string]int32{
+	"UNSPECIFIED_READ_CONSISTENCY": 0,
+	"STRONG":                       1,
+	"EVENTUAL":                     2,
+}
+
+func (x ReadConsistency) String() string {
+	return proto.EnumName(ReadConsistency_name, int32(x))
+}
+func (ReadConsistency) EnumDescriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
+
+// Padding scheme of RSA signatures.
+type SignatureParams_RsaPadding int32
+
+const (
+	// PKCS #1 v1.5 padding, the default.
+	SignatureParams_PKCS1V15 SignatureParams_RsaPadding = 0
+	// Probabilistic Signature Scheme (PSS) padding, as per RFC 8017.
+	SignatureParams_PSS SignatureParams_RsaPadding = 1
+)
+
+var SignatureParams_RsaPadding_name = map[int32]string{
+	0: "PKCS1V15",
+	1: "PSS",
+}
+var SignatureParams_RsaPadding_value = map[string]int32{
+	"PKCS1V15": 0,
+	"PSS":      1,
+}
+
+func (x SignatureParams_RsaPadding) String() string {
+	return proto.EnumName(SignatureParams_RsaPadding_name, int32(x))
+}
+func (SignatureParams_RsaPadding) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor3, []int{1, 0}
+}
+
 // Represents a tree, which may be either a verifiable log or map.
 // Readonly attributes are assigned at tree creation, after which they may not
 // be modified.
@@ -191,6 +262,54 @@ type Tree struct {
 	// Time of tree deletion, if any.
 	// Readonly.
 	DeleteTime *google_protobuf2.Timestamp `protobuf:"bytes,20,opt,name=delete_time,json=deleteTime" json:"delete_time,omitempty"`
+	// Labels attached to the tree, used to group and find related trees.
+	// Labels must be unique within a tree. Storage returns labels sorted
+	// lexicographically, regardless of the order they were set in.
+	// Optional.
+	Labels []string `protobuf:"bytes,21,rep,name=labels" json:"labels,omitempty"`
+	// Application-defined data attached to the tree, as key/value pairs.
+	// Entries may also be computed on read by storage.ReadDecorators, in which
+	// case they aren't persisted.
+	// Optional.
+	AppData map[string]string `protobuf:"bytes,22,rep,name=app_data,json=appData" json:"app_data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// If true, writes to the tree (e.g. queueing and integrating leaves) are
+	// rejected, regardless of tree_state. Meant to temporarily halt writes,
+	// e.g. during storage repairs, without changing the state seen by clients.
+	// Optional.
+	WritesDisabled bool `protobuf:"varint,23,opt,name=writes_disabled,json=writesDisabled" json:"writes_disabled,omitempty"`
+	// Time the sequencer last integrated leaves into the tree, as recorded via
+	// storage. Distinct from the time of the latest signed root, as roots may
+	// be signed without any integration progress. Unset if the tree was never
+	// sequenced.
+	// Readonly (automatically assigned by the sequencer).
+	LastSequencedTime *google_protobuf2.Timestamp `protobuf:"bytes,24,opt,name=last_sequenced_time,json=lastSequencedTime" json:"last_sequenced_time,omitempty"`
+	// Parameters of the signature algorithm, e.g. the RSA padding scheme.
+	// Defaults apply if unset.
+	// Readonly.
+	SignatureParams *SignatureParams `protobuf:"bytes,25,opt,name=signature_params,json=signatureParams" json:"signature_params,omitempty"`
+	// If true, the sequencer doesn't integrate queued leaves into the tree.
+	// Leaves may still be queued, and are integrated once sequencing resumes.
+	// Readonly (set via storage PauseSequencing and ResumeSequencing).
+	SequencingPaused bool `protobuf:"varint,26,opt,name=sequencing_paused,json=sequencingPaused" json:"sequencing_paused,omitempty"`
+	// Opaque reference to the tree's entry in an external config system, e.g.
+	// its ID. Trees may be looked up by it, see storage GetTreeByExternalRef.
+	// Optional.
+	ExternalRef string `protobuf:"bytes,27,opt,name=external_ref,json=externalRef" json:"external_ref,omitempty"`
+	// Read consistency hint for the tree when callers don't specify one, see
+	// storage ReadConsistencyFor. Not acted upon by any read path yet.
+	// Optional.
+	PreferredReadConsistency ReadConsistency `protobuf:"varint,28,opt,name=preferred_read_consistency,json=preferredReadConsistency,enum=trillian.ReadConsistency" json:"preferred_read_consistency,omitempty"`
+	// ID of the tree this tree was cloned from, zero if it's not a clone. The
+	// referenced tree must exist when the tree is created. See storage
+	// GetTreeLineage.
+	// Readonly (set at creation).
+	ClonedFrom int64 `protobuf:"varint,29,opt,name=cloned_from,json=clonedFrom" json:"cloned_from,omitempty"`
+	// Revision of the tree, starting at 1 on creation and incremented by
+	// storage on every write to the tree, excluding sequencing progress (see
+	// last_sequenced_time). Used for compare-and-swap writes, see storage
+	// ReplaceTree and SoftDeleteTreeIfUnchanged.
+	// Readonly (automatically assigned on writes).
+	Revision int64 `protobuf:"varint,30,opt,name=revision" json:"revision,omitempty"`
 }
 
 func (m *Tree) Reset()                    { *m = Tree{} }
@@ -317,6 +436,96 @@ func (m *Tree) GetDeleteTime() *google_protobuf2.Timestamp {
 	return nil
 }
 
+func (m *Tree) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Tree) GetAppData() map[string]string {
+	if m != nil {
+		return m.AppData
+	}
+	return nil
+}
+
+func (m *Tree) GetWritesDisabled() bool {
+	if m != nil {
+		return m.WritesDisabled
+	}
+	return false
+}
+
+func (m *Tree) GetLastSequencedTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.LastSequencedTime
+	}
+	return nil
+}
+
+func (m *Tree) GetSignatureParams() *SignatureParams {
+	if m != nil {
+		return m.SignatureParams
+	}
+	return nil
+}
+
+func (m *Tree) GetSequencingPaused() bool {
+	if m != nil {
+		return m.SequencingPaused
+	}
+	return false
+}
+
+func (m *Tree) GetExternalRef() string {
+	if m != nil {
+		return m.ExternalRef
+	}
+	return ""
+}
+
+func (m *Tree) GetPreferredReadConsistency() ReadConsistency {
+	if m != nil {
+		return m.PreferredReadConsistency
+	}
+	return ReadConsistency_UNSPECIFIED_READ_CONSISTENCY
+}
+
+func (m *Tree) GetClonedFrom() int64 {
+	if m != nil {
+		return m.ClonedFrom
+	}
+	return 0
+}
+
+func (m *Tree) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+// SignatureParams refine how signatures are produced for a given signature
+// algorithm.
+type SignatureParams struct {
+	// Padding scheme of RSA signatures. Only meaningful if signature_algorithm
+	// is RSA.
+	RsaPadding SignatureParams_RsaPadding `protobuf:"varint,1,opt,name=rsa_padding,json=rsaPadding,enum=trillian.SignatureParams_RsaPadding" json:"rsa_padding,omitempty"`
+}
+
+func (m *SignatureParams) Reset()                    { *m = SignatureParams{} }
+func (m *SignatureParams) String() string            { return proto.CompactTextString(m) }
+func (*SignatureParams) ProtoMessage()               {}
+func (*SignatureParams) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
+
+func (m *SignatureParams) GetRsaPadding() SignatureParams_RsaPadding {
+	if m != nil {
+		return m.RsaPadding
+	}
+	return SignatureParams_PKCS1V15
+}
+
 type SignedEntryTimestamp struct {
 	TimestampNanos int64                  `protobuf:"varint,1,opt,name=timestamp_nanos,json=timestampNanos" json:"timestamp_nanos,omitempty"`
 	LogId          int64                  `protobuf:"varint,2,opt,name=log_id,json=logId" json:"log_id,omitempty"`
@@ -326,7 +535,7 @@ type SignedEntryTimestamp struct {
 func (m *SignedEntryTimestamp) Reset()                    { *m = SignedEntryTimestamp{} }
 func (m *SignedEntryTimestamp) String() string            { return proto.CompactTextString(m) }
 func (*SignedEntryTimestamp) ProtoMessage()               {}
-func (*SignedEntryTimestamp) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
+func (*SignedEntryTimestamp) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
 
 func (m *SignedEntryTimestamp) GetTimestampNanos() int64 {
 	if m != nil {
@@ -364,7 +573,7 @@ type SignedLogRoot struct {
 func (m *SignedLogRoot) Reset()                    { *m = SignedLogRoot{} }
 func (m *SignedLogRoot) String() string            { return proto.CompactTextString(m) }
 func (*SignedLogRoot) ProtoMessage()               {}
-func (*SignedLogRoot) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
+func (*SignedLogRoot) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
 
 func (m *SignedLogRoot) GetTimestampNanos() int64 {
 	if m != nil {
@@ -425,7 +634,7 @@ type SignedMapRoot struct {
 func (m *SignedMapRoot) Reset()                    { *m = SignedMapRoot{} }
 func (m *SignedMapRoot) String() string            { return proto.CompactTextString(m) }
 func (*SignedMapRoot) ProtoMessage()               {}
-func (*SignedMapRoot) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
+func (*SignedMapRoot) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{4} }
 
 func (m *SignedMapRoot) GetTimestampNanos() int64 {
 	if m != nil {
@@ -471,80 +680,108 @@ func (m *SignedMapRoot) GetMetadata() *google_protobuf.Any {
 
 func init() {
 	proto.RegisterType((*Tree)(nil), "trillian.Tree")
+	proto.RegisterType((*SignatureParams)(nil), "trillian.SignatureParams")
 	proto.RegisterType((*SignedEntryTimestamp)(nil), "trillian.SignedEntryTimestamp")
 	proto.RegisterType((*SignedLogRoot)(nil), "trillian.SignedLogRoot")
 	proto.RegisterType((*SignedMapRoot)(nil), "trillian.SignedMapRoot")
 	proto.RegisterEnum("trillian.HashStrategy", HashStrategy_name, HashStrategy_value)
 	proto.RegisterEnum("trillian.TreeState", TreeState_name, TreeState_value)
 	proto.RegisterEnum("trillian.TreeType", TreeType_name, TreeType_value)
+	proto.RegisterEnum("trillian.ReadConsistency", ReadConsistency_name, ReadConsistency_value)
+	proto.RegisterEnum("trillian.SignatureParams_RsaPadding", SignatureParams_RsaPadding_name, SignatureParams_RsaPadding_value)
 }
 
 func init() { proto.RegisterFile("trillian.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 1009 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0x5d, 0x6f, 0xe2, 0x46,
-	0x14, 0x5d, 0x03, 0x01, 0x73, 0x21, 0xc4, 0x99, 0x7c, 0xac, 0x43, 0xa5, 0x2e, 0x4d, 0x2b, 0x35,
-	0x4d, 0x25, 0xb2, 0x65, 0x9b, 0x48, 0xd5, 0x3e, 0x54, 0x04, 0x9c, 0x25, 0x5f, 0x80, 0xc6, 0x6e,
-	0xab, 0xcd, 0x8b, 0x35, 0xe0, 0xa9, 0x19, 0x2d, 0xc6, 0x96, 0x3d, 0xac, 0xd6, 0x2b, 0xf5, 0xad,
-	0x8f, 0xfd, 0x99, 0x55, 0xff, 0x45, 0xa5, 0x6a, 0xc6, 0x1f, 0x21, 0xc9, 0x76, 0xb3, 0xaa, 0xfa,
-	0x92, 0xcc, 0x3d, 0xf7, 0x9c, 0x33, 0x73, 0xed, 0x3b, 0xd7, 0x40, 0x83, 0x87, 0x6c, 0x3e, 0x67,
-	0x64, 0xd1, 0x0e, 0x42, 0x9f, 0xfb, 0x48, 0xcd, 0xe2, 0x66, 0x73, 0x1a, 0xc6, 0x01, 0xf7, 0x8f,
-	0xde, 0xd0, 0x38, 0x0a, 0x26, 0xe9, 0xbf, 0x84, 0xd5, 0xd4, 0xd3, 0x5c, 0xc4, 0xdc, 0x60, 0x92,
-	0xfc, 0x4d, 0x33, 0x7b, 0xae, 0xef, 0xbb, 0x73, 0x7a, 0x24, 0xa3, 0xc9, 0xf2, 0xd7, 0x23, 0xb2,
-	0x88, 0xd3, 0xd4, 0xe7, 0xf7, 0x53, 0xce, 0x32, 0x24, 0x9c, 0xf9, 0xe9, 0xd6, 0xcd, 0x67, 0xf7,
-	0xf3, 0x9c, 0x79, 0x34, 0xe2, 0xc4, 0x0b, 0x12, 0xc2, 0xfe, 0x5f, 0x15, 0x28, 0x59, 0x21, 0xa5,
-	0xe8, 0x29, 0x54, 0x78, 0x48, 0xa9, 0xcd, 0x1c, 0x5d, 0x69, 0x29, 0x07, 0x45, 0x5c, 0x16, 0xe1,
-	0xb9, 0x83, 0x3a, 0x00, 0x32, 0x11, 0x71, 0xc2, 0xa9, 0x5e, 0x68, 0x29, 0x07, 0x8d, 0xce, 0x56,
-	0x3b, 0x2f, 0x51, 0x88, 0x4d, 0x91, 0xc2, 0x55, 0x9e, 0x2d, 0xd1, 0x11, 0xc8, 0xc0, 0xe6, 0x71,
-	0x40, 0xf5, 0xa2, 0x94, 0xa0, 0xbb, 0x12, 0x2b, 0x0e, 0x28, 0x56, 0x79, 0xba, 0x42, 0x2f, 0x61,
-	0x7d, 0x46, 0xa2, 0x99, 0x1d, 0xf1, 0x90, 0x70, 0xea, 0xc6, 0x7a, 0x49, 0x8a, 0x76, 0x6f, 0x45,
-	0x03, 0x12, 0xcd, 0xcc, 0x34, 0x8b, 0xeb, 0xb3, 0x95, 0x08, 0x5d, 0x42, 0x43, 0x8a, 0xc9, 0xdc,
-	0xf5, 0x43, 0xc6, 0x67, 0x9e, 0xbe, 0x26, 0xd5, 0x5f, 0xb5, 0x93, 0xa7, 0xd8, 0x67, 0x2e, 0xe3,
-	0x64, 0x3e, 0x8f, 0x4d, 0xe6, 0x2e, 0xa8, 0x23, 0xad, 0xba, 0x19, 0x17, 0xcb, 0x8d, 0xf3, 0x10,
-	0xdd, 0xc0, 0x56, 0xc4, 0xdc, 0x05, 0xe1, 0xcb, 0x90, 0xae, 0x38, 0x96, 0xa5, 0xe3, 0x37, 0xff,
-	0xe2, 0x68, 0x66, 0x8a, 0x5b, 0x5b, 0x14, 0x3d, 0xc0, 0x10, 0x81, 0xdd, 0x5b, 0xef, 0x29, 0x0b,
-	0x66, 0x34, 0xb4, 0xa3, 0x25, 0xe3, 0x54, 0x47, 0xd2, 0xfe, 0xdb, 0xc7, 0xec, 0x7b, 0x52, 0x63,
-	0x0a, 0x09, 0xde, 0x8e, 0x3e, 0x80, 0xa2, 0x2f, 0xa0, 0xee, 0xb0, 0x28, 0x98, 0x93, 0xd8, 0x5e,
-	0x10, 0x8f, 0xea, 0x6a, 0x4b, 0x39, 0xa8, 0xe2, 0x5a, 0x8a, 0x0d, 0x89, 0x47, 0x51, 0x0b, 0x6a,
-	0x0e, 0x8d, 0xa6, 0x21, 0x0b, 0x44, 0xa3, 0xe8, 0xd5, 0x94, 0x71, 0x0b, 0xa1, 0x63, 0xa8, 0x05,
-	0x21, 0x7b, 0x4b, 0x38, 0xb5, 0xdf, 0xd0, 0x58, 0xaf, 0xb7, 0x94, 0x83, 0x5a, 0x67, 0xbb, 0x9d,
-	0xf4, 0x52, 0x3b, 0xeb, 0xa5, 0x76, 0x77, 0x11, 0x63, 0x48, 0x89, 0x97, 0x34, 0x46, 0x3f, 0x82,
-	0x16, 0x71, 0x3f, 0x24, 0x2e, 0xb5, 0x23, 0xca, 0x39, 0x5b, 0xb8, 0x91, 0xbe, 0xfe, 0x11, 0xed,
-	0x46, 0xca, 0x36, 0x53, 0x32, 0x7a, 0x0e, 0x10, 0x2c, 0x27, 0x73, 0x36, 0x95, 0xdb, 0x36, 0xa4,
-	0x74, 0xb3, 0x9d, 0xde, 0x92, 0xb1, 0xcc, 0x5c, 0xd2, 0x18, 0x57, 0x83, 0x6c, 0x89, 0x0c, 0xd8,
-	0xf4, 0xc8, 0x3b, 0x3b, 0xf4, 0x7d, 0x6e, 0x67, 0xad, 0xaf, 0x6f, 0x48, 0xe1, 0xde, 0x83, 0x3d,
-	0xfb, 0x29, 0x01, 0x6f, 0x78, 0xe4, 0x1d, 0xf6, 0x7d, 0x9e, 0x01, 0xe8, 0x25, 0xd4, 0xa6, 0x21,
-	0x15, 0xf5, 0x8a, 0xfb, 0xa1, 0x6b, 0xd2, 0xa0, 0xf9, 0xc0, 0xc0, 0xca, 0x2e, 0x0f, 0x86, 0x84,
-	0x2e, 0x00, 0x21, 0x5e, 0x06, 0x4e, 0x2e, 0xde, 0x7c, 0x5c, 0x9c, 0xd0, 0xa5, 0x58, 0x87, 0x8a,
-	0x43, 0xe7, 0x94, 0x53, 0x47, 0xdf, 0x6a, 0x29, 0x07, 0x2a, 0xce, 0x42, 0x61, 0x9b, 0x2c, 0x13,
-	0xdb, 0xed, 0xc7, 0x6d, 0x13, 0xba, 0x00, 0x2e, 0x4a, 0x6a, 0x45, 0x53, 0x2f, 0x4a, 0x2a, 0x68,
-	0xb5, 0x8b, 0x92, 0x5a, 0xd3, 0xea, 0xfb, 0x7f, 0x28, 0xb0, 0x9d, 0xb4, 0x93, 0xb1, 0xe0, 0x61,
-	0x9c, 0xcb, 0xd0, 0xd7, 0xb0, 0x91, 0x0f, 0x05, 0x7b, 0x41, 0x16, 0x7e, 0x94, 0x0e, 0x80, 0x46,
-	0x0e, 0x0f, 0x05, 0x8a, 0x76, 0xa0, 0x3c, 0xf7, 0x5d, 0x31, 0x20, 0x0a, 0x32, 0xbf, 0x36, 0xf7,
-	0xdd, 0x73, 0x07, 0x7d, 0x0f, 0xd5, 0xbc, 0x13, 0xe5, 0x5d, 0xaf, 0x75, 0x76, 0x3f, 0xdc, 0xc7,
-	0xf8, 0x96, 0xb8, 0xff, 0xa7, 0x02, 0xeb, 0x09, 0x7a, 0xe5, 0xbb, 0xe2, 0x5d, 0x7c, 0xfa, 0x39,
-	0x3e, 0x83, 0xaa, 0x7c, 0xdf, 0xe2, 0xde, 0xca, 0xa3, 0xd4, 0xb1, 0x2a, 0x00, 0x71, 0xad, 0x45,
-	0x32, 0x99, 0x56, 0xec, 0x7d, 0x72, 0x9a, 0x62, 0x32, 0x65, 0x4c, 0xf6, 0x9e, 0xde, 0x3d, 0x6a,
-	0xe9, 0x13, 0x8f, 0xba, 0x52, 0xf7, 0xda, 0x6a, 0xdd, 0x5f, 0xc2, 0xba, 0xdc, 0x29, 0xa4, 0x6f,
-	0x59, 0x24, 0xda, 0xae, 0x2c, 0xb3, 0x75, 0x01, 0xe2, 0x14, 0xdb, 0xff, 0x3b, 0x2f, 0xf3, 0x9a,
-	0x04, 0xff, 0x63, 0x99, 0xff, 0xb9, 0x12, 0x8f, 0x04, 0x2b, 0x95, 0x78, 0x24, 0x38, 0x77, 0xc4,
-	0xcc, 0x10, 0xf0, 0xbd, 0x42, 0x6a, 0x1e, 0x09, 0xb2, 0x3a, 0xd0, 0x73, 0x50, 0x3d, 0xca, 0x89,
-	0x43, 0x38, 0xd1, 0x2b, 0x1f, 0xb9, 0xd2, 0x39, 0xeb, 0xa2, 0xa4, 0x16, 0xb5, 0xd2, 0xe1, 0xef,
-	0x0a, 0xd4, 0x57, 0x27, 0x37, 0xda, 0x83, 0x9d, 0x9f, 0x86, 0x97, 0xc3, 0xd1, 0x2f, 0x43, 0x7b,
-	0xd0, 0x35, 0x07, 0xb6, 0x69, 0xe1, 0xae, 0x65, 0xbc, 0x7a, 0xad, 0x3d, 0x41, 0x08, 0x1a, 0xf8,
-	0xac, 0x77, 0xf2, 0xc3, 0x49, 0xc7, 0x36, 0x07, 0xdd, 0xce, 0xf1, 0x89, 0xa6, 0xa0, 0x2d, 0xd8,
-	0xb0, 0x0c, 0xd3, 0xb2, 0xaf, 0xbb, 0x63, 0xc9, 0x37, 0xb0, 0x56, 0x10, 0x1e, 0xa3, 0xd3, 0x0b,
-	0xa3, 0x67, 0xd9, 0xf7, 0xf8, 0x45, 0xb4, 0x03, 0x9b, 0xbd, 0xd1, 0xf0, 0xfc, 0xd2, 0x14, 0xd0,
-	0xf1, 0x77, 0x1d, 0x5b, 0xc0, 0xa5, 0xc3, 0xdf, 0xa0, 0x9a, 0x7f, 0xa7, 0xd0, 0x2e, 0xa0, 0xec,
-	0x08, 0x16, 0x36, 0x0c, 0xdb, 0xb4, 0xba, 0x96, 0xa1, 0x3d, 0x41, 0x00, 0xe5, 0x6e, 0xcf, 0x3a,
-	0xff, 0xd9, 0xd0, 0x14, 0xb1, 0x3e, 0xc3, 0xa3, 0x1b, 0x63, 0xa8, 0x15, 0xd0, 0x33, 0x78, 0xda,
-	0x37, 0xc6, 0xd8, 0xe8, 0x75, 0x2d, 0xa3, 0x6f, 0x9b, 0xa3, 0x33, 0xcb, 0xee, 0x1b, 0x57, 0x86,
-	0x65, 0xf4, 0xb5, 0x62, 0xb3, 0xa0, 0x2a, 0xf7, 0x08, 0x83, 0x2e, 0xee, 0xe7, 0x84, 0x92, 0x20,
-	0x1c, 0xbe, 0x00, 0x35, 0xfb, 0xe6, 0x89, 0x13, 0xde, 0xd9, 0xdd, 0x7a, 0x3d, 0x16, 0x9b, 0x57,
-	0xa0, 0x78, 0x35, 0x7a, 0xa5, 0x29, 0x62, 0x71, 0xdd, 0x1d, 0x6b, 0x85, 0xd3, 0x01, 0xec, 0x4d,
-	0x7d, 0x2f, 0x7b, 0xca, 0x77, 0x7f, 0x52, 0x9c, 0xae, 0x5b, 0x69, 0x3c, 0x16, 0xe1, 0x58, 0xb9,
-	0x69, 0xba, 0x8c, 0xcf, 0x96, 0x93, 0xf6, 0xd4, 0xf7, 0x8e, 0xd2, 0x6f, 0x7e, 0x26, 0x99, 0x94,
-	0xa5, 0xe6, 0xc5, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x6d, 0x07, 0x82, 0x2d, 0x98, 0x08, 0x00,
-	0x00,
+	// 1418 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xdd, 0x6f, 0xdb, 0xc8,
+	0x11, 0x0f, 0x25, 0x59, 0xa6, 0x46, 0xb2, 0x45, 0xaf, 0x1d, 0x87, 0x56, 0xae, 0x17, 0xd5, 0x57,
+	0xa0, 0x6e, 0x0e, 0x90, 0x2f, 0x6a, 0x6d, 0xb4, 0x77, 0x0f, 0x05, 0x23, 0xd1, 0xb6, 0xfc, 0x41,
+	0x09, 0x4b, 0x26, 0x87, 0xe4, 0x85, 0x58, 0x89, 0x6b, 0x8a, 0x38, 0x7e, 0x95, 0x5c, 0xa5, 0xd1,
+	0x01, 0x7d, 0xeb, 0x63, 0xdf, 0xfa, 0xda, 0x3f, 0xaf, 0xff, 0x46, 0x81, 0x62, 0x97, 0x1f, 0x92,
+	0x95, 0xbb, 0x73, 0x50, 0xf4, 0x45, 0xda, 0xf9, 0xcd, 0xef, 0x37, 0x9c, 0x21, 0x77, 0x76, 0x16,
+	0x76, 0x59, 0xe2, 0xf9, 0xbe, 0x47, 0xc2, 0x5e, 0x9c, 0x44, 0x2c, 0x42, 0x72, 0x61, 0x77, 0x3a,
+	0xb3, 0x64, 0x19, 0xb3, 0xe8, 0xf4, 0x07, 0xba, 0x4c, 0xe3, 0x69, 0xfe, 0x97, 0xb1, 0x3a, 0x6a,
+	0xee, 0x4b, 0x3d, 0x37, 0x9e, 0x66, 0xbf, 0xb9, 0xe7, 0xc8, 0x8d, 0x22, 0xd7, 0xa7, 0xa7, 0xc2,
+	0x9a, 0x2e, 0xee, 0x4f, 0x49, 0xb8, 0xcc, 0x5d, 0x5f, 0x6e, 0xba, 0x9c, 0x45, 0x42, 0x98, 0x17,
+	0xe5, 0x8f, 0xee, 0xbc, 0xd8, 0xf4, 0x33, 0x2f, 0xa0, 0x29, 0x23, 0x41, 0x9c, 0x11, 0x8e, 0xff,
+	0xd9, 0x82, 0x9a, 0x95, 0x50, 0x8a, 0x9e, 0xc1, 0x36, 0x4b, 0x28, 0xb5, 0x3d, 0x47, 0x95, 0xba,
+	0xd2, 0x49, 0x15, 0xd7, 0xb9, 0x39, 0x72, 0x50, 0x1f, 0x40, 0x38, 0x52, 0x46, 0x18, 0x55, 0x2b,
+	0x5d, 0xe9, 0x64, 0xb7, 0xbf, 0xdf, 0x2b, 0x4b, 0xe4, 0x62, 0x93, 0xbb, 0x70, 0x83, 0x15, 0x4b,
+	0x74, 0x0a, 0xc2, 0xb0, 0xd9, 0x32, 0xa6, 0x6a, 0x55, 0x48, 0xd0, 0x43, 0x89, 0xb5, 0x8c, 0x29,
+	0x96, 0x59, 0xbe, 0x42, 0xdf, 0xc1, 0xce, 0x9c, 0xa4, 0x73, 0x3b, 0x65, 0x09, 0x61, 0xd4, 0x5d,
+	0xaa, 0x35, 0x21, 0x3a, 0x5c, 0x89, 0xae, 0x48, 0x3a, 0x37, 0x73, 0x2f, 0x6e, 0xcd, 0xd7, 0x2c,
+	0x74, 0x03, 0xbb, 0x42, 0x4c, 0x7c, 0x37, 0x4a, 0x3c, 0x36, 0x0f, 0xd4, 0x2d, 0xa1, 0xfe, 0x4d,
+	0x2f, 0x7b, 0x8b, 0x43, 0xcf, 0xf5, 0x18, 0xf1, 0xfd, 0xa5, 0xe9, 0xb9, 0x21, 0x75, 0x44, 0x28,
+	0xad, 0xe0, 0xe2, 0x9d, 0xf9, 0xba, 0x89, 0xde, 0xc3, 0x7e, 0xea, 0xb9, 0x21, 0x61, 0x8b, 0x84,
+	0xae, 0x45, 0xac, 0x8b, 0x88, 0xbf, 0xfb, 0x99, 0x88, 0x66, 0xa1, 0x58, 0x85, 0x45, 0xe9, 0x27,
+	0x18, 0x22, 0x70, 0xb8, 0x8a, 0x3d, 0xf3, 0xe2, 0x39, 0x4d, 0xec, 0x74, 0xe1, 0x31, 0xaa, 0x22,
+	0x11, 0xfe, 0xeb, 0xc7, 0xc2, 0x0f, 0x84, 0xc6, 0xe4, 0x12, 0x7c, 0x90, 0xfe, 0x04, 0x8a, 0x7e,
+	0x0d, 0x2d, 0xc7, 0x4b, 0x63, 0x9f, 0x2c, 0xed, 0x90, 0x04, 0x54, 0x95, 0xbb, 0xd2, 0x49, 0x03,
+	0x37, 0x73, 0xcc, 0x20, 0x01, 0x45, 0x5d, 0x68, 0x3a, 0x34, 0x9d, 0x25, 0x5e, 0xcc, 0x37, 0x8a,
+	0xda, 0xc8, 0x19, 0x2b, 0x08, 0x9d, 0x41, 0x33, 0x4e, 0xbc, 0x0f, 0x84, 0x51, 0xfb, 0x07, 0xba,
+	0x54, 0x5b, 0x5d, 0xe9, 0xa4, 0xd9, 0x3f, 0xe8, 0x65, 0x7b, 0xa9, 0x57, 0xec, 0xa5, 0x9e, 0x16,
+	0x2e, 0x31, 0xe4, 0xc4, 0x1b, 0xba, 0x44, 0x7f, 0x06, 0x25, 0x65, 0x51, 0x42, 0x5c, 0x6a, 0xa7,
+	0x94, 0x31, 0x2f, 0x74, 0x53, 0x75, 0xe7, 0x17, 0xb4, 0xed, 0x9c, 0x6d, 0xe6, 0x64, 0xf4, 0x0d,
+	0x40, 0xbc, 0x98, 0xfa, 0xde, 0x4c, 0x3c, 0x76, 0x57, 0x48, 0xf7, 0x7a, 0x79, 0x97, 0x4c, 0x84,
+	0xe7, 0x86, 0x2e, 0x71, 0x23, 0x2e, 0x96, 0x48, 0x87, 0xbd, 0x80, 0x7c, 0xb4, 0x93, 0x28, 0x62,
+	0x76, 0xb1, 0xf5, 0xd5, 0xb6, 0x10, 0x1e, 0x7d, 0xf2, 0xcc, 0x61, 0x4e, 0xc0, 0xed, 0x80, 0x7c,
+	0xc4, 0x51, 0xc4, 0x0a, 0x00, 0x7d, 0x07, 0xcd, 0x59, 0x42, 0x79, 0xbd, 0xbc, 0x3f, 0x54, 0x45,
+	0x04, 0xe8, 0x7c, 0x12, 0xc0, 0x2a, 0x9a, 0x07, 0x43, 0x46, 0xe7, 0x00, 0x17, 0x2f, 0x62, 0xa7,
+	0x14, 0xef, 0x3d, 0x2e, 0xce, 0xe8, 0x42, 0xac, 0xc2, 0xb6, 0x43, 0x7d, 0xca, 0xa8, 0xa3, 0xee,
+	0x77, 0xa5, 0x13, 0x19, 0x17, 0x26, 0x0f, 0x9b, 0x2d, 0xb3, 0xb0, 0x07, 0x8f, 0x87, 0xcd, 0xe8,
+	0x22, 0xec, 0x21, 0xd4, 0x7d, 0x32, 0xa5, 0x7e, 0xaa, 0x3e, 0xed, 0x56, 0x4f, 0x1a, 0x38, 0xb7,
+	0xd0, 0x39, 0xc8, 0x24, 0x8e, 0x6d, 0x87, 0x30, 0xa2, 0x1e, 0x76, 0xab, 0x27, 0xcd, 0xfe, 0xf3,
+	0x87, 0x7d, 0xd9, 0xd3, 0xe2, 0x78, 0x48, 0x18, 0xd1, 0x43, 0x96, 0x2c, 0xf1, 0x36, 0xc9, 0x2c,
+	0xf4, 0x5b, 0x68, 0xff, 0x35, 0xf1, 0x18, 0x4d, 0x6d, 0xc7, 0x4b, 0xc9, 0xd4, 0xa7, 0x8e, 0xfa,
+	0x4c, 0xa4, 0xbb, 0x9b, 0xc1, 0xc3, 0x1c, 0x45, 0xd7, 0xb0, 0xef, 0x93, 0x94, 0xd9, 0x29, 0xfd,
+	0xcb, 0x82, 0x86, 0x33, 0xea, 0x64, 0xd9, 0xab, 0x8f, 0x66, 0xbf, 0xc7, 0x65, 0x66, 0xa1, 0x12,
+	0x45, 0x0c, 0x41, 0x59, 0xb5, 0x4b, 0x4c, 0x12, 0x12, 0xa4, 0xea, 0x51, 0xfe, 0x6d, 0xcb, 0xa4,
+	0xcb, 0xde, 0x98, 0x08, 0x02, 0x6e, 0xa7, 0x0f, 0x01, 0xf4, 0x35, 0xec, 0xe5, 0xc9, 0x78, 0xa1,
+	0x6b, 0xc7, 0x64, 0x91, 0x52, 0x47, 0xed, 0x88, 0xe4, 0x95, 0x95, 0x63, 0x22, 0x70, 0xde, 0x3e,
+	0xf4, 0x23, 0xa3, 0x49, 0x48, 0x7c, 0x3b, 0xa1, 0xf7, 0xea, 0xf3, 0xac, 0x39, 0x0a, 0x0c, 0xd3,
+	0x7b, 0xf4, 0x3d, 0x74, 0xe2, 0x84, 0xde, 0xd3, 0x24, 0xa1, 0x8e, 0x9d, 0x50, 0xe2, 0xd8, 0xb3,
+	0x28, 0x4c, 0xbd, 0x94, 0xd1, 0x70, 0xb6, 0x54, 0xbf, 0x10, 0x8d, 0xbc, 0x96, 0x1f, 0xa6, 0xc4,
+	0x19, 0xac, 0x08, 0x58, 0x2d, 0xc5, 0x1b, 0x1e, 0xf4, 0x02, 0x9a, 0x33, 0x3f, 0x0a, 0xa9, 0x63,
+	0xdf, 0x27, 0x51, 0xa0, 0xfe, 0x4a, 0x9c, 0xc2, 0x90, 0x41, 0x17, 0x49, 0x14, 0xa0, 0x0e, 0xc8,
+	0x09, 0xfd, 0xe0, 0xa5, 0x7c, 0x8f, 0x7f, 0x29, 0xbc, 0xa5, 0xdd, 0xf9, 0x16, 0x5a, 0xeb, 0x5f,
+	0x0e, 0x29, 0x50, 0xe5, 0x3d, 0x24, 0x89, 0xfc, 0xf9, 0x12, 0x1d, 0xc0, 0xd6, 0x07, 0xe2, 0x2f,
+	0xb2, 0x23, 0xbc, 0x81, 0x33, 0xe3, 0xdb, 0xca, 0x1f, 0xa5, 0xeb, 0x9a, 0xbc, 0xad, 0xc8, 0xd7,
+	0x35, 0x19, 0x94, 0xe6, 0x75, 0x4d, 0x6e, 0x2a, 0xad, 0xe3, 0xbf, 0x41, 0x7b, 0xe3, 0xbd, 0x22,
+	0x1d, 0x9a, 0x49, 0x4a, 0xec, 0x98, 0x38, 0x8e, 0x17, 0xba, 0xaa, 0x94, 0x9f, 0xb0, 0x3f, 0xf7,
+	0x1d, 0x7a, 0x38, 0x25, 0x93, 0x8c, 0x8b, 0x21, 0x29, 0xd7, 0xc7, 0x5f, 0x01, 0xac, 0x3c, 0xa8,
+	0x05, 0xf2, 0xe4, 0x66, 0x60, 0xbe, 0x7a, 0xfb, 0xea, 0x4c, 0x79, 0x82, 0xb6, 0xa1, 0x3a, 0x31,
+	0x4d, 0x45, 0x3a, 0xfe, 0x87, 0x04, 0x07, 0xd9, 0xd1, 0x27, 0x8a, 0x29, 0x37, 0x09, 0xdf, 0x86,
+	0xe5, 0x00, 0xb3, 0x43, 0x12, 0x46, 0x69, 0x3e, 0xac, 0x76, 0x4b, 0xd8, 0xe0, 0x28, 0x7a, 0x0a,
+	0x75, 0x3f, 0x72, 0xf9, 0x30, 0xab, 0x08, 0xff, 0x96, 0x1f, 0xb9, 0x23, 0x07, 0xfd, 0x01, 0x1a,
+	0xe5, 0xf6, 0x10, 0x73, 0xa9, 0xd9, 0x3f, 0xfc, 0xe9, 0x33, 0x17, 0xaf, 0x88, 0xc7, 0xff, 0x96,
+	0x60, 0x27, 0x43, 0x6f, 0x23, 0x97, 0x9f, 0x1b, 0x9f, 0x9f, 0xc7, 0x73, 0x68, 0x88, 0xb3, 0x89,
+	0xcf, 0x18, 0x91, 0x4a, 0x0b, 0xcb, 0x1c, 0xe0, 0x23, 0x88, 0x3b, 0xb3, 0xc9, 0xea, 0xfd, 0x98,
+	0x65, 0x53, 0xcd, 0x26, 0xa2, 0xe9, 0xfd, 0x48, 0x1f, 0xa6, 0x5a, 0xfb, 0xcc, 0x54, 0xd7, 0xea,
+	0xde, 0x5a, 0xaf, 0xfb, 0x2b, 0xd8, 0x11, 0x4f, 0x2a, 0xb7, 0x4f, 0x5d, 0x78, 0x5b, 0x1c, 0xc4,
+	0x39, 0x76, 0xfc, 0x9f, 0xb2, 0xcc, 0x3b, 0x12, 0xff, 0x1f, 0xcb, 0xfc, 0x9f, 0x2b, 0x09, 0x48,
+	0xbc, 0x56, 0x49, 0x40, 0xe2, 0x91, 0x68, 0x50, 0x0e, 0x6f, 0x14, 0xd2, 0x0c, 0x48, 0x5c, 0xd4,
+	0x81, 0xbe, 0x01, 0x39, 0xa0, 0x8c, 0x88, 0x33, 0x6e, 0xfb, 0x17, 0xc6, 0x4f, 0xc9, 0xba, 0xae,
+	0xc9, 0x55, 0xa5, 0xf6, 0xf2, 0xef, 0x12, 0xb4, 0xd6, 0x6f, 0x19, 0xe8, 0x08, 0x9e, 0xbe, 0x31,
+	0x6e, 0x8c, 0xf1, 0xf7, 0x86, 0x7d, 0xa5, 0x99, 0x57, 0xb6, 0x69, 0x61, 0xcd, 0xd2, 0x2f, 0xdf,
+	0x29, 0x4f, 0x10, 0x82, 0x5d, 0x7c, 0x31, 0x38, 0xff, 0xd3, 0x79, 0xdf, 0x36, 0xaf, 0xb4, 0xfe,
+	0xd9, 0xb9, 0x22, 0xa1, 0x7d, 0x68, 0x5b, 0xba, 0x69, 0xd9, 0x77, 0xda, 0x44, 0xf0, 0x75, 0xac,
+	0x54, 0x78, 0x8c, 0xf1, 0xeb, 0x6b, 0x7d, 0x60, 0xd9, 0x1b, 0xfc, 0x2a, 0x7a, 0x0a, 0x7b, 0x83,
+	0xb1, 0x31, 0xba, 0x31, 0x39, 0x74, 0xf6, 0xaa, 0x6f, 0x73, 0xb8, 0xf6, 0xf2, 0x5f, 0x12, 0x34,
+	0xca, 0x4b, 0x15, 0x3a, 0x04, 0x54, 0xe4, 0x60, 0x61, 0x5d, 0xb7, 0x4d, 0x4b, 0xb3, 0x74, 0xe5,
+	0x09, 0x02, 0xa8, 0x6b, 0x03, 0x6b, 0xf4, 0x56, 0x57, 0x24, 0xbe, 0xbe, 0xc0, 0xe3, 0xf7, 0xba,
+	0xa1, 0x54, 0xd0, 0x0b, 0x78, 0x36, 0xd4, 0x27, 0x58, 0x1f, 0x68, 0x96, 0x3e, 0xb4, 0xcd, 0xf1,
+	0x85, 0x65, 0x0f, 0xf5, 0x5b, 0xdd, 0xd2, 0x87, 0x4a, 0xb5, 0x53, 0x91, 0xa5, 0x0d, 0xc2, 0x95,
+	0x86, 0x87, 0x25, 0xa1, 0x26, 0x08, 0x2d, 0x90, 0x87, 0x58, 0x1b, 0x19, 0x23, 0xe3, 0x52, 0xd9,
+	0x42, 0x6d, 0x68, 0xde, 0x69, 0x23, 0xc3, 0xd2, 0x0d, 0xcd, 0x18, 0xe8, 0x4a, 0xfd, 0xe5, 0x25,
+	0xc8, 0xc5, 0xfd, 0x8d, 0x57, 0xf0, 0x20, 0x39, 0xeb, 0xdd, 0x44, 0xcf, 0xfa, 0xf8, 0x76, 0x7c,
+	0xa9, 0x48, 0x7c, 0x71, 0xa7, 0x4d, 0x94, 0x0a, 0x7f, 0x5d, 0x13, 0xac, 0x8f, 0xf1, 0x50, 0xc7,
+	0xfa, 0xd0, 0xe6, 0xce, 0xea, 0xcb, 0x3b, 0x68, 0x6f, 0x9e, 0x80, 0x5d, 0xf8, 0xe2, 0x8d, 0x61,
+	0x4e, 0xf4, 0xc1, 0xe8, 0x62, 0xa4, 0x0f, 0x6d, 0xac, 0x6b, 0x43, 0x7b, 0x30, 0x36, 0xcc, 0x91,
+	0x69, 0xe9, 0xc6, 0xe0, 0x5d, 0x56, 0xb6, 0x69, 0xe1, 0xb1, 0xc1, 0xa3, 0xb7, 0x40, 0xd6, 0xdf,
+	0xea, 0x86, 0xf5, 0x46, 0xbb, 0x55, 0x2a, 0xaf, 0xaf, 0xe0, 0x68, 0x16, 0x05, 0xc5, 0x87, 0x7e,
+	0x78, 0x03, 0x7f, 0xbd, 0x63, 0xe5, 0xf6, 0x84, 0x9b, 0x13, 0xe9, 0x7d, 0xc7, 0xf5, 0xd8, 0x7c,
+	0x31, 0xed, 0xcd, 0xa2, 0xe0, 0x34, 0xbf, 0x22, 0x17, 0x92, 0x69, 0x5d, 0x68, 0x7e, 0xff, 0xdf,
+	0x01, 0x00, 0x30, 0xfc, 0xd0, 0x1e, 0xc7, 0x0b, 0x00, 0x00,
 }